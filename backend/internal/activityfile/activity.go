@@ -0,0 +1,193 @@
+// Package activityfile разбирает файлы активностей спортивных часов (GPX, TCX, FIT).
+package activityfile
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"training-tracker/backend/internal/models"
+)
+
+// Форматы файлов
+const (
+	FormatGPX = "gpx"
+	FormatTCX = "tcx"
+	FormatFIT = "fit"
+)
+
+// heartRateSampleInterval - минимальный шаг между сохраняемыми замерами пульса, секунды
+const heartRateSampleInterval = 5
+
+var (
+	ErrUnknownFormat = errors.New("unsupported activity file format, expected GPX, TCX or FIT")
+	ErrNoData        = errors.New("activity file contains no track data")
+)
+
+// Activity - данные активности, извлеченные из файла
+type Activity struct {
+	Format        string
+	Sport         string // run, bike, swim, row, other
+	StartTime     time.Time
+	Duration      int     // секунды
+	Distance      float64 // метры
+	ElevationGain float64 // метры
+	Calories      int
+	AvgHeartRate  int
+	MaxHeartRate  int
+	HeartRate     []models.HeartRateSample
+}
+
+// Parse определяет формат по расширению или содержимому и разбирает файл
+func Parse(filename string, data []byte) (*Activity, error) {
+	var (
+		activity *Activity
+		err      error
+	)
+	switch DetectFormat(filename, data) {
+	case FormatGPX:
+		activity, err = parseGPX(data)
+	case FormatTCX:
+		activity, err = parseTCX(data)
+	case FormatFIT:
+		activity, err = parseFIT(data)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if activity.StartTime.IsZero() {
+		return nil, ErrNoData
+	}
+	return activity, nil
+}
+
+// DetectFormat возвращает формат файла или пустую строку
+func DetectFormat(filename string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gpx":
+		return FormatGPX
+	case ".tcx":
+		return FormatTCX
+	case ".fit":
+		return FormatFIT
+	}
+
+	if len(data) >= 12 && string(data[8:12]) == ".FIT" {
+		return FormatFIT
+	}
+	head := data
+	if len(head) > 512 {
+		head = head[:512]
+	}
+	switch {
+	case bytes.Contains(head, []byte("<gpx")):
+		return FormatGPX
+	case bytes.Contains(head, []byte("<TrainingCenterDatabase")):
+		return FormatTCX
+	}
+	return ""
+}
+
+// normalizeSport приводит название вида спорта из файла к типам CardioActivity
+func normalizeSport(sport string) string {
+	s := strings.ToLower(sport)
+	switch {
+	case strings.Contains(s, "run"), strings.Contains(s, "walk"), strings.Contains(s, "hik"):
+		return "run"
+	case strings.Contains(s, "bik"), strings.Contains(s, "cycl"):
+		return "bike"
+	case strings.Contains(s, "swim"):
+		return "swim"
+	case strings.Contains(s, "row"):
+		return "row"
+	default:
+		return "other"
+	}
+}
+
+// trackPoint - общая точка трека для всех форматов
+type trackPoint struct {
+	Time      time.Time
+	Lat, Lon  float64
+	HasPos    bool
+	Elevation float64
+	HasEle    bool
+	Distance  float64 // накопленная дистанция из файла, если есть
+	HeartRate int
+}
+
+// summarize заполняет показатели активности по точкам трека.
+// Значения, уже взятые из сводки файла, не перезаписываются.
+func summarize(activity *Activity, points []trackPoint) {
+	if len(points) == 0 {
+		return
+	}
+	start := points[0].Time
+	if activity.StartTime.IsZero() {
+		activity.StartTime = start
+	}
+	if activity.Duration == 0 {
+		activity.Duration = int(points[len(points)-1].Time.Sub(start).Seconds())
+	}
+
+	var distance, gain float64
+	var hrSum, hrCount, hrMax int
+	lastSample := -heartRateSampleInterval
+	var samples []models.HeartRateSample
+	for i, p := range points {
+		if i > 0 {
+			prev := points[i-1]
+			if p.HasPos && prev.HasPos {
+				distance += haversine(prev.Lat, prev.Lon, p.Lat, p.Lon)
+			}
+			if p.HasEle && prev.HasEle && p.Elevation > prev.Elevation {
+				gain += p.Elevation - prev.Elevation
+			}
+		}
+		if p.Distance > 0 && p.Distance > distance {
+			distance = p.Distance
+		}
+		if p.HeartRate > 0 {
+			hrSum += p.HeartRate
+			hrCount++
+			if p.HeartRate > hrMax {
+				hrMax = p.HeartRate
+			}
+			offset := int(p.Time.Sub(start).Seconds())
+			if offset-lastSample >= heartRateSampleInterval {
+				samples = append(samples, models.HeartRateSample{Offset: offset, BPM: p.HeartRate})
+				lastSample = offset
+			}
+		}
+	}
+
+	if activity.Distance == 0 {
+		activity.Distance = math.Round(distance*10) / 10
+	}
+	if activity.ElevationGain == 0 {
+		activity.ElevationGain = math.Round(gain*10) / 10
+	}
+	if activity.AvgHeartRate == 0 && hrCount > 0 {
+		activity.AvgHeartRate = int(math.Round(float64(hrSum) / float64(hrCount)))
+	}
+	if activity.MaxHeartRate == 0 {
+		activity.MaxHeartRate = hrMax
+	}
+	activity.HeartRate = samples
+}
+
+// haversine - расстояние между двумя координатами в метрах
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371000.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
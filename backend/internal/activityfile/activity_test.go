@@ -0,0 +1,115 @@
+package activityfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"training-tracker/backend/internal/models"
+)
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		file string
+		want Activity
+	}{
+		// Точка без времени пропускается, дистанция - по координатам
+		{"run.gpx", Activity{
+			Format: FormatGPX, Sport: "run", StartTime: time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC),
+			Duration: 60, Distance: 222.4, ElevationGain: 5, AvgHeartRate: 140, MaxHeartRate: 160,
+			HeartRate: []models.HeartRateSample{{Offset: 0, BPM: 120}, {Offset: 30, BPM: 140}, {Offset: 60, BPM: 160}},
+		}},
+		// Сводка кругов важнее точек трека, средний пульс взвешен по времени круга
+		{"ride.tcx", Activity{
+			Format: FormatTCX, Sport: "bike", StartTime: time.Date(2026, 3, 2, 7, 30, 0, 0, time.UTC),
+			Duration: 900, Distance: 6000, ElevationGain: 20, Calories: 150, AvgHeartRate: 140, MaxHeartRate: 172,
+			HeartRate: []models.HeartRateSample{{Offset: 0, BPM: 110}, {Offset: 600, BPM: 150}, {Offset: 900, BPM: 172}},
+		}},
+		// Последняя запись - со сжатым заголовком времени
+		{"run.fit", Activity{
+			Format: FormatFIT, Sport: "run", StartTime: time.Date(2026, 3, 3, 6, 0, 0, 0, time.UTC),
+			Duration: 25, Distance: 80, Calories: 42, AvgHeartRate: 128, MaxHeartRate: 150,
+			HeartRate: []models.HeartRateSample{{Offset: 0, BPM: 100}, {Offset: 10, BPM: 120}, {Offset: 20, BPM: 140}, {Offset: 25, BPM: 150}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			got, err := Parse(tt.file, fixture(t, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Parse = %+v\nwant %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	fit := fixture(t, "run.fit")
+	badSignature := append([]byte{}, fit...)
+	copy(badSignature[8:12], "FIT?")
+	// данные для локального сообщения 2, которое не было определено
+	undefined := append(append([]byte{}, fit[:14]...), 0x02, 0x00)
+
+	tests := []struct {
+		name string
+		file string
+		data []byte
+		err  error
+	}{
+		{"truncated gpx", "run.gpx", fixture(t, "run.gpx")[:500], nil},
+		{"truncated tcx", "ride.tcx", fixture(t, "ride.tcx")[:700], nil},
+		{"truncated fit", "run.fit", fit[:len(fit)/2], errFITCorrupt},
+		{"fit cut inside the last message", "run.fit", fit[:len(fit)-3], errFITCorrupt},
+		{"fit shorter than its header", "run.fit", fit[:10], errFITCorrupt},
+		{"fit with a bad signature", "run.fit", badSignature, errFITCorrupt},
+		{"fit with an undefined message", "run.fit", undefined, errFITCorrupt},
+		{"not xml", "run.gpx", []byte("\x00\x01garbage"), nil},
+		{"gpx without points", "run.gpx", []byte(`<gpx><trk><trkseg></trkseg></trk></gpx>`), ErrNoData},
+		{"tcx without activities", "ride.tcx", []byte(`<TrainingCenterDatabase><Activities/></TrainingCenterDatabase>`), ErrNoData},
+		{"unknown format", "notes.txt", []byte("hello"), ErrUnknownFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.file, tt.data)
+			if err == nil {
+				t.Fatalf("Parse = %+v, want an error", got)
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Errorf("Parse error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		file string
+		data []byte
+		want string
+	}{
+		{"RUN.GPX", nil, FormatGPX},
+		{"upload", fixture(t, "run.gpx"), FormatGPX},
+		{"upload", fixture(t, "ride.tcx"), FormatTCX},
+		{"upload", fixture(t, "run.fit"), FormatFIT},
+		{"upload", []byte("<html></html>"), ""},
+	}
+	for _, tt := range tests {
+		if got := DetectFormat(tt.file, tt.data); got != tt.want {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tt.file, got, tt.want)
+		}
+	}
+}
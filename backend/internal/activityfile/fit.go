@@ -0,0 +1,277 @@
+package activityfile
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"time"
+)
+
+// Глобальные номера сообщений и поля FIT, которые нам нужны
+const (
+	fitMesgSession = 18
+	fitMesgRecord  = 20
+
+	fitFieldTimestamp = 253
+
+	fitSessionStartTime    = 2
+	fitSessionSport        = 5
+	fitSessionElapsedTime  = 7
+	fitSessionTotalDist    = 9
+	fitSessionCalories     = 11
+	fitSessionAvgHeartRate = 16
+	fitSessionMaxHeartRate = 17
+	fitSessionTotalAscent  = 22
+
+	fitRecordLat         = 0
+	fitRecordLon         = 1
+	fitRecordAltitude    = 2
+	fitRecordHeartRate   = 3
+	fitRecordDistance    = 5
+	fitRecordEnhancedAlt = 78
+)
+
+// fitEpoch - начало отсчета времени FIT (1989-12-31 00:00:00 UTC)
+var fitEpoch = time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)
+
+var errFITCorrupt = errors.New("corrupt FIT file")
+
+type fitFieldDef struct {
+	num  byte
+	size int
+}
+
+type fitDefinition struct {
+	global    uint16
+	order     binary.ByteOrder
+	fields    []fitFieldDef
+	devFields int // суммарный размер полей разработчика
+}
+
+// fitSports - значения перечисления sport из профиля FIT
+var fitSports = map[uint64]string{
+	1:  "run",
+	2:  "bike",
+	5:  "swim",
+	11: "run", // walking
+	15: "row",
+	17: "run", // hiking
+}
+
+func parseFIT(data []byte) (*Activity, error) {
+	if len(data) < 12 {
+		return nil, errFITCorrupt
+	}
+	headerSize := int(data[0])
+	if headerSize < 12 || len(data) < headerSize || string(data[8:12]) != ".FIT" {
+		return nil, errFITCorrupt
+	}
+	end := headerSize + int(binary.LittleEndian.Uint32(data[4:8]))
+	if end > len(data) {
+		end = len(data)
+	}
+
+	activity := &Activity{Format: FormatFIT, Sport: "other"}
+	definitions := make(map[byte]*fitDefinition)
+	var points []trackPoint
+	var lastTimestamp uint32
+
+	pos := headerSize
+	for pos < end {
+		header := data[pos]
+		pos++
+
+		// Сжатый заголовок с относительной меткой времени
+		if header&0x80 != 0 {
+			local := (header >> 5) & 0x03
+			offset := uint32(header & 0x1F)
+			rollover := offset < lastTimestamp&0x1F
+			lastTimestamp = lastTimestamp&^0x1F + offset
+			if rollover {
+				lastTimestamp += 0x20
+			}
+			def := definitions[local]
+			if def == nil {
+				return nil, errFITCorrupt
+			}
+			values, next, err := readFITMessage(data, pos, end, def)
+			if err != nil {
+				return nil, err
+			}
+			pos = next
+			if def.global == fitMesgRecord {
+				values[fitFieldTimestamp] = uint64(lastTimestamp)
+				points = appendFITRecord(points, values)
+			}
+			continue
+		}
+
+		local := header & 0x0F
+		if header&0x40 != 0 {
+			def, next, err := readFITDefinition(data, pos, end, header&0x20 != 0)
+			if err != nil {
+				return nil, err
+			}
+			definitions[local] = def
+			pos = next
+			continue
+		}
+
+		def := definitions[local]
+		if def == nil {
+			return nil, errFITCorrupt
+		}
+		values, next, err := readFITMessage(data, pos, end, def)
+		if err != nil {
+			return nil, err
+		}
+		pos = next
+		if ts, ok := values[fitFieldTimestamp]; ok {
+			lastTimestamp = uint32(ts)
+		}
+
+		switch def.global {
+		case fitMesgRecord:
+			points = appendFITRecord(points, values)
+		case fitMesgSession:
+			applyFITSession(activity, values)
+		}
+	}
+
+	if len(points) == 0 && activity.StartTime.IsZero() {
+		return nil, ErrNoData
+	}
+	summarize(activity, points)
+	return activity, nil
+}
+
+func readFITDefinition(data []byte, pos, end int, hasDevFields bool) (*fitDefinition, int, error) {
+	if pos+5 > end {
+		return nil, 0, errFITCorrupt
+	}
+	def := &fitDefinition{order: binary.LittleEndian}
+	if data[pos+1] == 1 {
+		def.order = binary.BigEndian
+	}
+	def.global = def.order.Uint16(data[pos+2 : pos+4])
+	count := int(data[pos+4])
+	pos += 5
+
+	if pos+count*3 > end {
+		return nil, 0, errFITCorrupt
+	}
+	for i := 0; i < count; i++ {
+		def.fields = append(def.fields, fitFieldDef{num: data[pos], size: int(data[pos+1])})
+		pos += 3
+	}
+
+	if hasDevFields {
+		if pos >= end {
+			return nil, 0, errFITCorrupt
+		}
+		devCount := int(data[pos])
+		pos++
+		if pos+devCount*3 > end {
+			return nil, 0, errFITCorrupt
+		}
+		for i := 0; i < devCount; i++ {
+			def.devFields += int(data[pos+1])
+			pos += 3
+		}
+	}
+	return def, pos, nil
+}
+
+// readFITMessage читает беззнаковые поля размером 1, 2 и 4 байта, невалидные значения пропускаются
+func readFITMessage(data []byte, pos, end int, def *fitDefinition) (map[byte]uint64, int, error) {
+	values := make(map[byte]uint64)
+	for _, f := range def.fields {
+		if pos+f.size > end {
+			return nil, 0, errFITCorrupt
+		}
+		raw := data[pos : pos+f.size]
+		pos += f.size
+
+		switch f.size {
+		case 1:
+			if raw[0] != 0xFF {
+				values[f.num] = uint64(raw[0])
+			}
+		case 2:
+			if v := def.order.Uint16(raw); v != 0xFFFF {
+				values[f.num] = uint64(v)
+			}
+		case 4:
+			if v := def.order.Uint32(raw); v != 0xFFFFFFFF && v != 0x7FFFFFFF {
+				values[f.num] = uint64(v)
+			}
+		}
+	}
+	if pos+def.devFields > end {
+		return nil, 0, errFITCorrupt
+	}
+	return values, pos + def.devFields, nil
+}
+
+func fitTime(v uint64) time.Time {
+	return fitEpoch.Add(time.Duration(v) * time.Second)
+}
+
+// fitDegrees переводит semicircles в градусы
+func fitDegrees(v uint64) float64 {
+	return float64(int32(uint32(v))) * (180.0 / math.Pow(2, 31))
+}
+
+func appendFITRecord(points []trackPoint, values map[byte]uint64) []trackPoint {
+	ts, ok := values[fitFieldTimestamp]
+	if !ok {
+		return points
+	}
+	p := trackPoint{Time: fitTime(ts)}
+	lat, hasLat := values[fitRecordLat]
+	lon, hasLon := values[fitRecordLon]
+	if hasLat && hasLon {
+		p.Lat, p.Lon, p.HasPos = fitDegrees(lat), fitDegrees(lon), true
+	}
+	if alt, ok := values[fitRecordEnhancedAlt]; ok {
+		p.Elevation, p.HasEle = float64(alt)/5-500, true
+	} else if alt, ok := values[fitRecordAltitude]; ok {
+		p.Elevation, p.HasEle = float64(alt)/5-500, true
+	}
+	if hr, ok := values[fitRecordHeartRate]; ok {
+		p.HeartRate = int(hr)
+	}
+	if dist, ok := values[fitRecordDistance]; ok {
+		p.Distance = float64(dist) / 100
+	}
+	return append(points, p)
+}
+
+func applyFITSession(activity *Activity, values map[byte]uint64) {
+	if v, ok := values[fitSessionStartTime]; ok {
+		activity.StartTime = fitTime(v)
+	}
+	if v, ok := values[fitSessionSport]; ok {
+		if sport, known := fitSports[v]; known {
+			activity.Sport = sport
+		}
+	}
+	if v, ok := values[fitSessionElapsedTime]; ok {
+		activity.Duration = int(math.Round(float64(v) / 1000))
+	}
+	if v, ok := values[fitSessionTotalDist]; ok {
+		activity.Distance = float64(v) / 100
+	}
+	if v, ok := values[fitSessionCalories]; ok {
+		activity.Calories = int(v)
+	}
+	if v, ok := values[fitSessionAvgHeartRate]; ok {
+		activity.AvgHeartRate = int(v)
+	}
+	if v, ok := values[fitSessionMaxHeartRate]; ok {
+		activity.MaxHeartRate = int(v)
+	}
+	if v, ok := values[fitSessionTotalAscent]; ok {
+		activity.ElevationGain = float64(v)
+	}
+}
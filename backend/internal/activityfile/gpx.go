@@ -0,0 +1,59 @@
+package activityfile
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type gpxFile struct {
+	Metadata struct {
+		Time string `xml:"time"`
+	} `xml:"metadata"`
+	Tracks []struct {
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []struct {
+				Lat       float64  `xml:"lat,attr"`
+				Lon       float64  `xml:"lon,attr"`
+				Elevation *float64 `xml:"ele"`
+				Time      string   `xml:"time"`
+				HeartRate int      `xml:"extensions>TrackPointExtension>hr"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+func parseGPX(data []byte) (*Activity, error) {
+	var file gpxFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	activity := &Activity{Format: FormatGPX, Sport: "other"}
+	var points []trackPoint
+	for _, trk := range file.Tracks {
+		if trk.Type != "" {
+			activity.Sport = normalizeSport(trk.Type)
+		}
+		for _, seg := range trk.Segments {
+			for _, pt := range seg.Points {
+				t, err := time.Parse(time.RFC3339, pt.Time)
+				if err != nil {
+					continue
+				}
+				p := trackPoint{Time: t, Lat: pt.Lat, Lon: pt.Lon, HasPos: true, HeartRate: pt.HeartRate}
+				if pt.Elevation != nil {
+					p.Elevation = *pt.Elevation
+					p.HasEle = true
+				}
+				points = append(points, p)
+			}
+		}
+	}
+	if len(points) == 0 {
+		return nil, ErrNoData
+	}
+
+	summarize(activity, points)
+	return activity, nil
+}
@@ -0,0 +1,89 @@
+package activityfile
+
+import (
+	"encoding/xml"
+	"math"
+	"time"
+)
+
+type tcxFile struct {
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		ID    string `xml:"Id"`
+		Laps  []struct {
+			StartTime        string  `xml:"StartTime,attr"`
+			TotalTimeSeconds float64 `xml:"TotalTimeSeconds"`
+			DistanceMeters   float64 `xml:"DistanceMeters"`
+			Calories         int     `xml:"Calories"`
+			AvgHeartRate     int     `xml:"AverageHeartRateBpm>Value"`
+			MaxHeartRate     int     `xml:"MaximumHeartRateBpm>Value"`
+			Trackpoints      []struct {
+				Time      string   `xml:"Time"`
+				Lat       *float64 `xml:"Position>LatitudeDegrees"`
+				Lon       *float64 `xml:"Position>LongitudeDegrees"`
+				Altitude  *float64 `xml:"AltitudeMeters"`
+				Distance  float64  `xml:"DistanceMeters"`
+				HeartRate int      `xml:"HeartRateBpm>Value"`
+			} `xml:"Track>Trackpoint"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+func parseTCX(data []byte) (*Activity, error) {
+	var file tcxFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if len(file.Activities) == 0 {
+		return nil, ErrNoData
+	}
+
+	// В файле обычно одна активность, берем первую
+	src := file.Activities[0]
+	activity := &Activity{Format: FormatTCX, Sport: normalizeSport(src.Sport)}
+	if t, err := time.Parse(time.RFC3339, src.ID); err == nil {
+		activity.StartTime = t
+	}
+
+	var totalTime, totalDistance float64
+	var hrWeighted float64
+	var points []trackPoint
+	for _, lap := range src.Laps {
+		totalTime += lap.TotalTimeSeconds
+		totalDistance += lap.DistanceMeters
+		activity.Calories += lap.Calories
+		hrWeighted += float64(lap.AvgHeartRate) * lap.TotalTimeSeconds
+		if lap.MaxHeartRate > activity.MaxHeartRate {
+			activity.MaxHeartRate = lap.MaxHeartRate
+		}
+		if activity.StartTime.IsZero() {
+			if t, err := time.Parse(time.RFC3339, lap.StartTime); err == nil {
+				activity.StartTime = t
+			}
+		}
+
+		for _, tp := range lap.Trackpoints {
+			t, err := time.Parse(time.RFC3339, tp.Time)
+			if err != nil {
+				continue
+			}
+			p := trackPoint{Time: t, Distance: tp.Distance, HeartRate: tp.HeartRate}
+			if tp.Lat != nil && tp.Lon != nil {
+				p.Lat, p.Lon, p.HasPos = *tp.Lat, *tp.Lon, true
+			}
+			if tp.Altitude != nil {
+				p.Elevation, p.HasEle = *tp.Altitude, true
+			}
+			points = append(points, p)
+		}
+	}
+
+	activity.Duration = int(math.Round(totalTime))
+	activity.Distance = totalDistance
+	if totalTime > 0 && hrWeighted > 0 {
+		activity.AvgHeartRate = int(math.Round(hrWeighted / totalTime))
+	}
+
+	summarize(activity, points)
+	return activity, nil
+}
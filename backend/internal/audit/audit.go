@@ -0,0 +1,375 @@
+// Package audit ведет журнал изменений данных: колбэки GORM записывают каждое
+// создание, изменение и удаление вместе с состоянием записи до и после.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+
+	"training-tracker/backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Actor - кто и откуда изменил данные
+type Actor struct {
+	Name   string
+	Source string
+}
+
+type actorKey struct{}
+
+// system - автор изменений вне HTTP-запросов (фоновые задачи, миграции)
+var system = Actor{Name: "system", Source: "background"}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func actorFrom(ctx context.Context) Actor {
+	if ctx == nil {
+		return system
+	}
+	if actor, ok := ctx.Value(actorKey{}).(Actor); ok {
+		return actor
+	}
+	return system
+}
+
+// Middleware кладет автора в контекст запроса. Авторизации в приложении нет,
+// поэтому клиент представляется заголовком X-Actor (например, имя устройства),
+// иначе автором считается IP-адрес.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.GetHeader("X-Actor")
+		if name == "" {
+			name = c.ClientIP()
+		}
+		actor := Actor{Name: name, Source: c.Request.Method + " " + c.Request.URL.Path}
+		c.Request = c.Request.WithContext(WithActor(c.Request.Context(), actor))
+		c.Next()
+	}
+}
+
+// entity - таблица под аудитом
+type entity struct {
+	model     func() interface{}
+	parent    string // таблица, через которую запись связана с профилем
+	parentKey string // JSON-поле со ссылкой на parent
+}
+
+var entities = map[string]entity{
+	"profiles":                   {model: func() interface{} { return &models.Profile{} }},
+	"exercises":                  {model: func() interface{} { return &models.Exercise{} }},
+	"body_weights":               {model: func() interface{} { return &models.BodyWeight{} }},
+	"personal_records":           {model: func() interface{} { return &models.PersonalRecord{} }},
+	"goals":                      {model: func() interface{} { return &models.Goal{} }},
+	"trainings":                  {model: func() interface{} { return &models.Training{} }},
+	"training_sessions":          {model: func() interface{} { return &models.TrainingSession{} }},
+	"training_programs":          {model: func() interface{} { return &models.TrainingProgram{} }},
+	"gyms":                       {model: func() interface{} { return &models.Gym{} }},
+	"readiness_check_ins":        {model: func() interface{} { return &models.ReadinessCheckIn{} }},
+	"training_session_exercises": {model: func() interface{} { return &models.TrainingSessionExercise{} }, parent: "training_sessions", parentKey: "trainingSessionId"},
+	"cardio_activities":          {model: func() interface{} { return &models.CardioActivity{} }, parent: "training_sessions", parentKey: "trainingSessionId"},
+	"program_exercises":          {model: func() interface{} { return &models.ProgramExercise{} }, parent: "training_programs", parentKey: "programId"},
+	"program_sessions":           {model: func() interface{} { return &models.ProgramSession{} }, parent: "training_programs", parentKey: "programId"},
+}
+
+// Known сообщает, ведется ли журнал для таблицы
+func Known(table string) bool {
+	_, ok := entities[table]
+	return ok
+}
+
+const beforeKey = "audit:before"
+
+// Register подключает журнал к db. Вызывается после миграций и начального заполнения,
+// чтобы они не попадали в журнал.
+func Register(db *gorm.DB) error {
+	if err := db.Callback().Create().After("gorm:create").Register("audit:after_create", afterCreate); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().Before("gorm:update").Register("audit:before_update", captureBefore); err != nil {
+		return err
+	}
+	if err := db.Callback().Update().After("gorm:update").Register("audit:after_update", afterChange); err != nil {
+		return err
+	}
+	if err := db.Callback().Delete().Before("gorm:delete").Register("audit:before_delete", captureBefore); err != nil {
+		return err
+	}
+	return db.Callback().Delete().After("gorm:delete").Register("audit:after_delete", afterChange)
+}
+
+// snapshot - состояние записи в журнале
+type snapshot struct {
+	id   uint
+	data json.RawMessage
+}
+
+func afterCreate(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Schema == nil || !Known(stmt.Schema.Table) || stmt.Schema.PrioritizedPrimaryField == nil {
+		return
+	}
+
+	var rows []reflect.Value
+	value := reflect.Indirect(stmt.ReflectValue)
+	switch value.Kind() {
+	case reflect.Struct:
+		rows = append(rows, value)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < value.Len(); i++ {
+			rows = append(rows, reflect.Indirect(value.Index(i)))
+		}
+	}
+
+	for _, row := range rows {
+		if row.Kind() != reflect.Struct || row.Type() != stmt.Schema.ModelType {
+			continue
+		}
+		id, zero := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, row)
+		if zero {
+			continue
+		}
+		data, err := json.Marshal(row.Interface())
+		if err != nil {
+			db.AddError(err)
+			return
+		}
+		write(db, stmt.Schema.Table, models.AuditActionCreate, snapshot{}, snapshot{id: toUint(id), data: data})
+	}
+}
+
+// captureBefore запоминает состояние записей, которые затронет изменение или удаление
+func captureBefore(db *gorm.DB) {
+	table := tableOf(db.Statement)
+	if db.Error != nil || !Known(table) {
+		return
+	}
+	query, ok := targetQuery(db, table)
+	if !ok {
+		return
+	}
+	rows, err := load(query, table)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	db.Statement.Settings.Store(beforeKey, rows)
+}
+
+// afterChange сравнивает запомненное состояние с текущим и пишет изменившиеся записи
+func afterChange(db *gorm.DB) {
+	stored, ok := db.Statement.Settings.Load(beforeKey)
+	if !ok {
+		return
+	}
+	db.Statement.Settings.Delete(beforeKey)
+	before := stored.([]snapshot)
+	if db.Error != nil || len(before) == 0 || db.RowsAffected == 0 {
+		return
+	}
+
+	table := tableOf(db.Statement)
+	ids := make([]uint, len(before))
+	for i, s := range before {
+		ids[i] = s.id
+	}
+	after, err := load(session(db).Unscoped().Model(entities[table].model()).Where("id IN ?", ids), table)
+	if err != nil {
+		db.AddError(err)
+		return
+	}
+	afterByID := make(map[uint]snapshot, len(after))
+	for _, s := range after {
+		afterByID[s.id] = s
+	}
+
+	for _, b := range before {
+		a, exists := afterByID[b.id]
+		if exists && bytes.Equal(a.data, b.data) {
+			continue
+		}
+		write(db, table, action(b, a, exists), b, a)
+	}
+}
+
+// action определяет действие по снимкам: окончательное удаление, перенос в корзину,
+// возврат из корзины или обычное изменение
+func action(before, after snapshot, exists bool) string {
+	if !exists {
+		return models.AuditActionDelete
+	}
+	wasDeleted, isDeleted := deleted(before.data), deleted(after.data)
+	switch {
+	case !wasDeleted && isDeleted:
+		return models.AuditActionDelete
+	case wasDeleted && !isDeleted:
+		return models.AuditActionRestore
+	default:
+		return models.AuditActionUpdate
+	}
+}
+
+func deleted(data json.RawMessage) bool {
+	var fields struct {
+		DeletedAt *json.RawMessage `json:"deletedAt"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil || fields.DeletedAt == nil {
+		return false
+	}
+	return string(*fields.DeletedAt) != "null"
+}
+
+// targetQuery строит выборку записей, которых коснется запрос: его условия WHERE
+// и первичный ключ модели, если он задан
+func targetQuery(db *gorm.DB, table string) (*gorm.DB, bool) {
+	stmt := db.Statement
+	query := session(db).Model(entities[table].model())
+	// Запросы через Table() и Unscoped() не добавляют условие deleted_at IS NULL
+	if stmt.Unscoped || stmt.Schema == nil {
+		query = query.Unscoped()
+	}
+
+	conditions := false
+	if where, ok := stmt.Clauses["WHERE"]; ok {
+		if expr, ok := where.Expression.(clause.Where); ok && len(expr.Exprs) > 0 {
+			query = query.Clauses(clause.Where{Exprs: expr.Exprs})
+			conditions = true
+		}
+	}
+	if id, ok := primaryKey(stmt); ok {
+		query = query.Where("id = ?", id)
+		conditions = true
+	}
+	return query, conditions
+}
+
+func primaryKey(stmt *gorm.Statement) (interface{}, bool) {
+	if stmt.Schema == nil || stmt.Schema.PrioritizedPrimaryField == nil {
+		return nil, false
+	}
+	for _, candidate := range []interface{}{stmt.Model, stmt.Dest} {
+		if candidate == nil {
+			continue
+		}
+		value := reflect.Indirect(reflect.ValueOf(candidate))
+		if value.Kind() != reflect.Struct || value.Type() != stmt.Schema.ModelType {
+			continue
+		}
+		if id, zero := stmt.Schema.PrioritizedPrimaryField.ValueOf(stmt.Context, value); !zero {
+			return id, true
+		}
+	}
+	return nil, false
+}
+
+// load читает записи и сериализует их в JSON по тегам модели
+func load(query *gorm.DB, table string) ([]snapshot, error) {
+	modelType := reflect.TypeOf(entities[table].model()).Elem()
+	rows := reflect.New(reflect.SliceOf(modelType))
+	if err := query.Find(rows.Interface()).Error; err != nil {
+		return nil, err
+	}
+
+	list := rows.Elem()
+	snapshots := make([]snapshot, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		row := list.Index(i)
+		data, err := json.Marshal(row.Interface())
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, snapshot{id: toUint(row.FieldByName("ID").Interface()), data: data})
+	}
+	return snapshots, nil
+}
+
+func write(db *gorm.DB, table, action string, before, after snapshot) {
+	actor := actorFrom(db.Statement.Context)
+	entry := models.AuditLog{
+		ProfileID: profileOf(db, table, before, after),
+		Entity:    table,
+		EntityID:  before.id,
+		Action:    action,
+		Before:    before.data,
+		After:     after.data,
+		Actor:     actor.Name,
+		Source:    actor.Source,
+	}
+	if entry.EntityID == 0 {
+		entry.EntityID = after.id
+	}
+	if revertOf, ok := db.Statement.Context.Value(revertKey{}).(uint); ok {
+		entry.RevertOf = &revertOf
+	}
+	if err := session(db).Create(&entry).Error; err != nil {
+		db.AddError(err)
+	}
+}
+
+// profileOf находит профиль записи: напрямую по profileId или через родителя
+func profileOf(db *gorm.DB, table string, snapshots ...snapshot) *uint {
+	for _, s := range snapshots {
+		if len(s.data) == 0 {
+			continue
+		}
+		if table == "profiles" {
+			id := s.id
+			return &id
+		}
+
+		var fields map[string]interface{}
+		if err := json.Unmarshal(s.data, &fields); err != nil {
+			continue
+		}
+		if profileID, ok := fields["profileId"].(float64); ok {
+			id := uint(profileID)
+			return &id
+		}
+
+		e := entities[table]
+		parentID, ok := fields[e.parentKey].(float64)
+		if e.parent == "" || !ok {
+			continue
+		}
+		var profileID uint
+		if err := session(db).Table(e.parent).Select("profile_id").Where("id = ?", uint(parentID)).Scan(&profileID).Error; err == nil && profileID != 0 {
+			return &profileID
+		}
+	}
+	return nil
+}
+
+// session - новый запрос в том же соединении (и транзакции), что и изменение
+func session(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true})
+}
+
+func tableOf(stmt *gorm.Statement) string {
+	if stmt.Schema != nil {
+		return stmt.Schema.Table
+	}
+	return stmt.Table
+}
+
+func toUint(value interface{}) uint {
+	switch v := value.(type) {
+	case uint:
+		return v
+	case uint32:
+		return uint(v)
+	case uint64:
+		return uint(v)
+	case int:
+		return uint(v)
+	case int64:
+		return uint(v)
+	}
+	return 0
+}
@@ -0,0 +1,131 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+
+	"training-tracker/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrNothingToRevert - состояние записи уже совпадает с тем, к которому ведет откат
+var ErrNothingToRevert = errors.New("nothing to revert")
+
+// ErrNotRevertible - запись журнала нельзя откатить
+var ErrNotRevertible = errors.New("change cannot be reverted")
+
+// AfterRevert выполняется в транзакции отката после записи: так вызывающий код
+// обновляет связанные данные (ленту синхронизации, активную программу) атомарно
+// с откатом. previous - запись до отката (nil, если ее не было в базе),
+// deleted - после отката запись удалена.
+type AfterRevert func(tx *gorm.DB, record, previous interface{}, deleted bool) error
+
+// BeforeRevert выполняется в транзакции отката перед записью снимка record: так
+// вызывающий код освобождает место для записи, например снимает выбор с другого зала.
+type BeforeRevert func(tx *gorm.DB, record interface{}) error
+
+// revertKey помечает изменения, сделанные откатом, чтобы журнал сослался на исходную запись
+type revertKey struct{}
+
+// Revert возвращает запись в состояние до изменения entry. Созданная запись удаляется
+// (в корзину, если модель это поддерживает), остальные изменения откатываются к снимку Before.
+// Откат сам попадает в журнал с RevertOf = entry.ID. Для записи после отката возвращается nil.
+// before и after, если заданы, вызываются в той же транзакции.
+func Revert(db *gorm.DB, entry models.AuditLog, before BeforeRevert, after AfterRevert) (interface{}, error) {
+	e, ok := entities[entry.Entity]
+	if !ok {
+		return nil, ErrNotRevertible
+	}
+	db = db.WithContext(context.WithValue(db.Statement.Context, revertKey{}, entry.ID))
+
+	var result interface{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		current := e.model()
+		err := tx.Unscoped().First(current, entry.EntityID).Error
+		found := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if entry.Action == models.AuditActionCreate {
+			if !found || isDeleted(current) {
+				return ErrNothingToRevert
+			}
+			if err := tx.Delete(e.model(), entry.EntityID).Error; err != nil {
+				return err
+			}
+			if after == nil {
+				return nil
+			}
+			// перечитываем запись, чтобы after получил отметку удаления
+			record := e.model()
+			if err := tx.Unscoped().First(record, entry.EntityID).Error; err != nil {
+				return err
+			}
+			return after(tx, record, current, true)
+		}
+
+		if len(entry.Before) == 0 || string(entry.Before) == "null" {
+			return ErrNotRevertible
+		}
+		record := e.model()
+		if err := json.Unmarshal(entry.Before, record); err != nil {
+			return err
+		}
+		var previous interface{}
+		if found {
+			previous = current
+			bumpVersion(record, current)
+			keepHidden(record, current)
+		}
+		if before != nil {
+			if err := before(tx, record); err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Save(record).Error; err != nil {
+			return err
+		}
+		if after != nil {
+			if err := after(tx, record, previous, isDeleted(record)); err != nil {
+				return err
+			}
+		}
+		result = record
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// bumpVersion ставит версию на единицу больше текущей, чтобы откат не выглядел
+// для клиентов с If-Match как старая версия записи
+func bumpVersion(record, current interface{}) {
+	target := reflect.ValueOf(record).Elem().FieldByName("Version")
+	source := reflect.ValueOf(current).Elem().FieldByName("Version")
+	if target.IsValid() && source.IsValid() && target.CanSet() && target.Kind() == reflect.Int {
+		target.SetInt(source.Int() + 1)
+	}
+}
+
+// keepHidden переносит в запись текущие значения полей с json:"-": их нет в снимке
+// журнала, и без этого откат затер бы их пустыми (например, хеш импортированного файла)
+func keepHidden(record, current interface{}) {
+	target := reflect.ValueOf(record).Elem()
+	source := reflect.ValueOf(current).Elem()
+	for i := 0; i < target.NumField(); i++ {
+		if target.Type().Field(i).Tag.Get("json") == "-" && target.Field(i).CanSet() {
+			target.Field(i).Set(source.Field(i))
+		}
+	}
+}
+
+func isDeleted(record interface{}) bool {
+	data, err := json.Marshal(record)
+	return err == nil && deleted(data)
+}
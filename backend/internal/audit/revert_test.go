@@ -0,0 +1,28 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"training-tracker/backend/internal/models"
+)
+
+func TestKeepHidden(t *testing.T) {
+	current := &models.CardioActivity{SourceHash: "abc", Notes: "now"}
+	before, err := json.Marshal(models.CardioActivity{SourceHash: "abc", Notes: "then"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	record := &models.CardioActivity{}
+	if err := json.Unmarshal(before, record); err != nil {
+		t.Fatal(err)
+	}
+	if record.SourceHash != "" {
+		t.Fatalf("snapshot unexpectedly carries SourceHash %q", record.SourceHash)
+	}
+	keepHidden(record, current)
+	if record.SourceHash != "abc" || record.Notes != "then" {
+		t.Errorf("keepHidden = %q, %q, want the current hash and the snapshot notes", record.SourceHash, record.Notes)
+	}
+}
@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 	"sort"
+	"time"
 
 	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/progression"
+	"training-tracker/backend/internal/recommendations"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -16,7 +20,7 @@ func HandleGetAnalytics(c *gin.Context, db *gorm.DB) {
 
 	var profile models.Profile
 	if err := db.First(&profile, profileID).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		respondError(c, http.StatusNotFound, "Profile not found")
 		return
 	}
 
@@ -30,28 +34,56 @@ func HandleGetAnalytics(c *gin.Context, db *gorm.DB) {
 		exerciseMap[ex.Name] = ex
 	}
 
-	analytics := calculateAnalytics(profile, trainings, exerciseMap)
+	sessions, err := loadSessionsWithExercises(db, profileID)
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	var bodyWeights []models.BodyWeight
+	db.Where("profile_id = ?", profileID).Find(&bodyWeights)
+	sets := collectSessionSets(sessions, exerciseMap, newBodyWeightLookup(profile, bodyWeights))
+
+	gym, err := findGym(db, profile.ID, "")
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	analytics := calculateAnalytics(profile, trainings, sessions, sets, exerciseMap, gym, recommendationOptions(c, profile))
 	c.JSON(http.StatusOK, analytics)
 }
 
-func calculateAnalytics(profile models.Profile, trainings []models.Training, exerciseMap map[string]models.Exercise) models.AnalyticsResponse {
-	profileStats := calculateProfileStats(profile, trainings)
-	progress := calculateProgress(trainings)
-	muscleBalance := calculateMuscleGroupBalance(trainings, exerciseMap)
-	exerciseStats := calculateExerciseStats(trainings)
-	recommendations := generateRecommendations(profile, trainings, muscleBalance, exerciseStats)
+func calculateAnalytics(profile models.Profile, trainings []models.Training, sessions []models.TrainingSessionWithExercises, sets []sessionSet, exerciseMap map[string]models.Exercise, gym *models.Gym, opts recommendations.Options) models.AnalyticsResponse {
+	profileStats := calculateProfileStats(profile, trainings, len(sessions), sets)
+	progress := calculateProgress(trainings, sessions, sets, exerciseMap)
+	muscleBalance := calculateMuscleGroupBalance(trainings, sets, exerciseMap)
+	exerciseStats := calculateExerciseStats(trainings, sets, exerciseMap)
+	now := time.Now()
+	load := calculateTrainingLoad(sessions, sets, "", now, now)
+	trends := analyzeProgression(sets, exerciseMap, gym, progression.DefaultStallSessions, load.Current)
+	advice := recommendations.Evaluate(recommendations.Input{
+		Profile:       profile,
+		Trainings:     len(trainings),
+		MuscleBalance: muscleBalance,
+		ExerciseStats: exerciseStats,
+		Load:          load.Current,
+		Progression:   &trends,
+	}, opts)
 
 	return models.AnalyticsResponse{
 		Profile:            profileStats,
 		Progress:           progress,
 		MuscleGroupBalance: muscleBalance,
 		ExerciseStats:      exerciseStats,
-		Recommendations:    recommendations,
+		Conditioning:       calculateConditioning(sessions),
+		Recommendations:    advice,
+		TrainingLoad:       load.Current,
 	}
 }
 
-func calculateProfileStats(profile models.Profile, trainings []models.Training) models.ProfileStats {
-	totalWorkouts := len(trainings)
+func calculateProfileStats(profile models.Profile, trainings []models.Training, sessionCount int, sets []sessionSet) models.ProfileStats {
+	totalWorkouts := len(trainings) + sessionCount
 	exerciseSet := make(map[string]bool)
 	var totalVolume float64
 
@@ -69,6 +101,10 @@ func calculateProfileStats(profile models.Profile, trainings []models.Training)
 			}
 		}
 	}
+	for _, s := range sets {
+		exerciseSet[s.Exercise] = true
+		totalVolume += float64(s.Set.Reps) * s.Load
+	}
 
 	stats := models.ProfileStats{
 		TotalWorkouts:    totalWorkouts,
@@ -85,8 +121,8 @@ func calculateProfileStats(profile models.Profile, trainings []models.Training)
 	return stats
 }
 
-func calculateProgress(trainings []models.Training) models.ProgressStats {
-	if len(trainings) == 0 {
+func calculateProgress(trainings []models.Training, sessions []models.TrainingSessionWithExercises, sets []sessionSet, exerciseMap map[string]models.Exercise) models.ProgressStats {
+	if len(trainings) == 0 && len(sessions) == 0 {
 		return models.ProgressStats{}
 	}
 
@@ -110,6 +146,10 @@ func calculateProgress(trainings []models.Training) models.ProgressStats {
 		}
 	}
 
+	for exercise, series := range sessionPerformanceSeries(sets, exerciseMap) {
+		exerciseProgress[exercise] = append(exerciseProgress[exercise], series...)
+	}
+
 	var mostImproved string
 	var maxProgressPercent float64
 	for exercise, weights := range exerciseProgress {
@@ -124,15 +164,77 @@ func calculateProgress(trainings []models.Training) models.ProgressStats {
 		}
 	}
 
+	frequency := float64(len(trainings)) / 4.0
+	if len(sessions) > 0 {
+		frequency = sessionsPerWeek(sessions)
+	}
+
 	return models.ProgressStats{
 		WeightProgress:       maxProgressPercent,
 		VolumeProgress:       0,
-		FrequencyPerWeek:     float64(len(trainings)) / 4.0,
+		FrequencyPerWeek:     frequency,
 		MostImprovedExercise: mostImproved,
 	}
 }
 
-func calculateMuscleGroupBalance(trainings []models.Training, exerciseMap map[string]models.Exercise) []models.MuscleGroupStat {
+// sessionsPerWeek - среднее число сессий (силовых и кардио) в неделю между первой и последней сессией
+func sessionsPerWeek(sessions []models.TrainingSessionWithExercises) float64 {
+	first := sessions[0].Date
+	last := sessions[len(sessions)-1].Date
+	weeks := math.Ceil(weekStart(last).Sub(weekStart(first)).Hours()/(24*7)) + 1
+	return round(float64(len(sessions)) / weeks)
+}
+
+// calculateConditioning - сводка по кардио-активностям
+func calculateConditioning(sessions []models.TrainingSessionWithExercises) models.ConditioningStats {
+	stats := models.ConditioningStats{Activities: []models.ActivityStat{}}
+	byActivity := make(map[string]*models.ActivityStat)
+	var heartRateSum, heartRateCount int
+
+	for _, s := range sessions {
+		if len(s.Activities) == 0 {
+			continue
+		}
+		stats.TotalSessions++
+		for _, a := range s.Activities {
+			stats.TotalDuration += a.Duration
+			stats.TotalDistance += a.Distance
+			stats.TotalCalories += a.Calories
+			if a.AvgHeartRate > 0 {
+				heartRateSum += a.AvgHeartRate
+				heartRateCount++
+			}
+
+			stat := byActivity[a.Activity]
+			if stat == nil {
+				stat = &models.ActivityStat{Activity: a.Activity}
+				byActivity[a.Activity] = stat
+			}
+			stat.Count++
+			stat.Duration += a.Duration
+			stat.Distance += a.Distance
+			stat.Calories += a.Calories
+			pace := a.Pace
+			if pace == 0 {
+				pace = calculatePace(a.Duration, a.Distance)
+			}
+			if pace > 0 && (stat.BestPace == 0 || pace < stat.BestPace) {
+				stat.BestPace = pace
+			}
+		}
+	}
+
+	if heartRateCount > 0 {
+		stats.AverageHeartRate = round(float64(heartRateSum) / float64(heartRateCount))
+	}
+	for _, stat := range byActivity {
+		stats.Activities = append(stats.Activities, *stat)
+	}
+	sort.Slice(stats.Activities, func(i, j int) bool { return stats.Activities[i].Duration > stats.Activities[j].Duration })
+	return stats
+}
+
+func calculateMuscleGroupBalance(trainings []models.Training, sets []sessionSet, exerciseMap map[string]models.Exercise) []models.MuscleGroupStat {
 	muscleGroups := make(map[string]*models.MuscleGroupStat)
 	var totalVolume float64
 	for _, t := range trainings {
@@ -156,6 +258,24 @@ func calculateMuscleGroupBalance(trainings []models.Training, exerciseMap map[st
 			}
 		}
 	}
+	counted := make(map[sessionExerciseKey]bool)
+	for _, s := range sets {
+		ex, exists := exerciseMap[s.Exercise]
+		if !exists || ex.MuscleGroup == "" {
+			continue
+		}
+		if muscleGroups[ex.MuscleGroup] == nil {
+			muscleGroups[ex.MuscleGroup] = &models.MuscleGroupStat{MuscleGroup: ex.MuscleGroup}
+		}
+		key := sessionExerciseKey{s.SessionID, s.Exercise}
+		if !counted[key] {
+			counted[key] = true
+			muscleGroups[ex.MuscleGroup].Count++
+		}
+		volume := float64(s.Set.Reps) * s.Load
+		muscleGroups[ex.MuscleGroup].Volume += volume
+		totalVolume += volume
+	}
 	result := make([]models.MuscleGroupStat, 0, len(muscleGroups))
 	for _, stat := range muscleGroups {
 		if totalVolume > 0 {
@@ -167,7 +287,7 @@ func calculateMuscleGroupBalance(trainings []models.Training, exerciseMap map[st
 	return result
 }
 
-func calculateExerciseStats(trainings []models.Training) []models.ExerciseStat {
+func calculateExerciseStats(trainings []models.Training, sets []sessionSet, exerciseMap map[string]models.Exercise) []models.ExerciseStat {
 	exerciseData := make(map[string]*models.ExerciseStat)
 	for _, t := range trainings {
 		if t.Exercise == "" {
@@ -195,6 +315,31 @@ func calculateExerciseStats(trainings []models.Training) []models.ExerciseStat {
 		}
 		exerciseData[t.Exercise].TotalVolume += totalVolume
 	}
+	for _, s := range sets {
+		stat := exerciseData[s.Exercise]
+		if stat == nil {
+			stat = &models.ExerciseStat{Exercise: s.Exercise}
+			exerciseData[s.Exercise] = stat
+		}
+		if s.Load > stat.MaxWeight {
+			stat.MaxWeight = s.Load
+		}
+		stat.TotalVolume += float64(s.Set.Reps) * s.Load
+		if e1rm := round(estimateOneRM(s.Load, s.Set.Reps)); e1rm > stat.EstimatedOneRM {
+			stat.EstimatedOneRM = e1rm
+		}
+		if s.Set.Duration > stat.BestDuration {
+			stat.BestDuration = s.Set.Duration
+		}
+		if s.Set.Distance > stat.BestDistance {
+			stat.BestDistance = s.Set.Distance
+		}
+	}
+	for exercise, series := range sessionPerformanceSeries(sets, exerciseMap) {
+		if stat := exerciseData[exercise]; stat != nil {
+			stat.Progress = progressPercent(series)
+		}
+	}
 	result := make([]models.ExerciseStat, 0, len(exerciseData))
 	for _, stat := range exerciseData {
 		result = append(result, *stat)
@@ -203,45 +348,37 @@ func calculateExerciseStats(trainings []models.Training) []models.ExerciseStat {
 	return result
 }
 
-func generateRecommendations(profile models.Profile, trainings []models.Training, muscleBalance []models.MuscleGroupStat, exerciseStats []models.ExerciseStat) []string {
-	recommendations := []string{}
-	if profile.Weight != nil && profile.Height != nil && *profile.Height > 0 {
-		heightM := float64(*profile.Height) / 100.0
-		bmi := *profile.Weight / (heightM * heightM)
-		if bmi < 18.5 {
-			recommendations = append(recommendations, "⚠️ Ваш BMI ниже нормы. Рекомендуется увеличить калорийность питания и сосредоточиться на наборе мышечной массы.")
-		} else if bmi > 25 {
-			recommendations = append(recommendations, "⚠️ Ваш BMI выше нормы. Рекомендуется добавить кардио и контролировать калорийность питания.")
+// sessionPerformanceSeries - лучший результат по основной метрике упражнения в каждой сессии.
+// Для силовых упражнений это нагрузка, для планки - время, для кардио - дистанция.
+func sessionPerformanceSeries(sets []sessionSet, exerciseMap map[string]models.Exercise) map[string][]float64 {
+	var order []sessionExerciseKey
+	best := make(map[sessionExerciseKey]float64)
+	for _, s := range sets {
+		key := sessionExerciseKey{s.SessionID, s.Exercise}
+		if _, seen := best[key]; !seen {
+			order = append(order, key)
 		}
-	}
-	if len(muscleBalance) > 0 {
-		maxVolume := muscleBalance[0].Volume
-		for _, mg := range muscleBalance {
-			if mg.Volume < maxVolume*0.3 {
-				recommendations = append(recommendations, fmt.Sprintf("💪 Уделите больше внимания группе мышц: %s (всего %.1f%% от общего объема)", mg.MuscleGroup, mg.Percentage))
-			}
+		value := setPerformance(s, primaryMetric(exerciseMap[s.Exercise]))
+		if value > best[key] {
+			best[key] = value
 		}
 	}
-	if len(trainings) < 8 {
-		recommendations = append(recommendations, "📅 Рекомендуется увеличить частоту тренировок до 3-4 раз в неделю для лучших результатов.")
-	}
-	if len(exerciseStats) < 5 {
-		recommendations = append(recommendations, "🎯 Добавьте больше разнообразия в программу. Рекомендуется выполнять 8-12 различных упражнений.")
-	}
-	switch profile.Goal {
-	case "strength":
-		recommendations = append(recommendations, "💪 Для развития силы фокусируйтесь на весах 85-95% от 1ПМ с 1-5 повторениями.")
-	case "mass":
-		recommendations = append(recommendations, "🏋️ Для роста массы оптимальны веса 70-85% от 1ПМ с 6-12 повторениями.")
-	case "endurance":
-		recommendations = append(recommendations, "🏃 Для развития выносливости используйте веса 50-70% от 1ПМ с 15-20+ повторениями.")
-	case "weight_loss":
-		recommendations = append(recommendations, "🔥 Для похудения сочетайте силовые тренировки с кардио и контролируйте калорийность.")
+
+	series := make(map[string][]float64)
+	for _, key := range order {
+		if best[key] > 0 {
+			series[key.exercise] = append(series[key.exercise], best[key])
+		}
 	}
-	if len(recommendations) == 0 {
-		recommendations = append(recommendations, "✅ Отличная работа! Продолжайте в том же духе.")
+	return series
+}
+
+// progressPercent - изменение между первым и последним значением в процентах
+func progressPercent(values []float64) float64 {
+	if len(values) < 2 || values[0] == 0 {
+		return 0
 	}
-	return recommendations
+	return round((values[len(values)-1] - values[0]) / values[0] * 100)
 }
 
 func getFieldValue(t models.Training, fieldName string) float64 {
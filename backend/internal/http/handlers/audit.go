@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+
+	"training-tracker/backend/internal/audit"
+	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// HandleGetProfileAudit - журнал изменений профиля, новые записи сверху.
+// Фильтры: entity (имя таблицы), entityId, action; before - курсор страницы.
+func HandleGetProfileAudit(c *gin.Context, db *gorm.DB) {
+	query := db.Where("profile_id = ?", c.Param("id"))
+	if entity := c.Query("entity"); entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+	if entityID := c.Query("entityId"); entityID != "" {
+		id, err := strconv.ParseUint(entityID, 10, 32)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid entityId")
+			return
+		}
+		query = query.Where("entity_id = ?", id)
+	}
+	if action := c.Query("action"); action != "" {
+		query = query.Where("action = ?", action)
+	}
+	respondAuditPage(c, query)
+}
+
+// HandleGetEntityAudit - история одной записи, в том числе не привязанной к профилю
+func HandleGetEntityAudit(c *gin.Context, db *gorm.DB) {
+	entity := c.Param("entity")
+	if !audit.Known(entity) {
+		respondError(c, http.StatusNotFound, "Unknown entity")
+		return
+	}
+	entityID, err := strconv.ParseUint(c.Param("entityId"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid entity ID")
+		return
+	}
+	respondAuditPage(c, db.Where("entity = ? AND entity_id = ?", entity, entityID))
+}
+
+func respondAuditPage(c *gin.Context, query *gorm.DB) {
+	if before := c.Query("before"); before != "" {
+		cursor, err := strconv.ParseUint(before, 10, 64)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, "Invalid cursor")
+			return
+		}
+		query = query.Where("id < ?", cursor)
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultAuditLimit)))
+	if limit < 1 || limit > maxAuditLimit {
+		limit = defaultAuditLimit
+	}
+
+	var entries []models.AuditLog
+	if err := query.Order("id DESC").Limit(limit + 1).Find(&entries).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	response := models.AuditLogResponse{Entries: entries, HasMore: len(entries) > limit}
+	if response.HasMore {
+		response.Entries = entries[:limit]
+	}
+	if len(response.Entries) > 0 {
+		response.Before = response.Entries[len(response.Entries)-1].ID
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// HandleRevertAudit откатывает одно изменение из журнала профиля.
+// Отвечает восстановленной записью или 204, если откатывалось создание.
+func HandleRevertAudit(c *gin.Context, db *gorm.DB) {
+	var entry models.AuditLog
+	if err := db.Where("id = ? AND profile_id = ?", c.Param("auditId"), c.Param("id")).First(&entry).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Audit entry not found")
+		return
+	}
+
+	var publish func()
+	beforeRevert := func(tx *gorm.DB, record interface{}) error {
+		if _, deleted := deletedStamp(record); deleted {
+			return nil
+		}
+		return beforeRestore(tx, record)
+	}
+	record, err := audit.Revert(db, entry, beforeRevert, func(tx *gorm.DB, record, previous interface{}, deleted bool) error {
+		if err := revertChildren(tx, entry, record, previous); err != nil {
+			return err
+		}
+		var err error
+		publish, err = afterRevert(tx, record, deleted)
+		return err
+	})
+	switch {
+	case errors.Is(err, audit.ErrNothingToRevert):
+		respondError(c, http.StatusConflict, "Record is already in the reverted state")
+		return
+	case errors.Is(err, audit.ErrNotRevertible):
+		respondError(c, http.StatusUnprocessableEntity, "This change cannot be reverted")
+		return
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		respondError(c, http.StatusConflict, restoreConflict(entry.Entity))
+		return
+	case err != nil:
+		respondInternal(c, err)
+		return
+	}
+
+	if publish != nil {
+		publish()
+	}
+	if record == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// revertChildren переносит дочерние записи вслед за родителем, как это делает корзина:
+// если откат удалил запись, живые дочерние получают ее отметку удаления, а если
+// вернул из корзины - восстанавливаются дочерние с прежней отметкой
+func revertChildren(tx *gorm.DB, entry models.AuditLog, record, previous interface{}) error {
+	kind, ok := findTrashTable(entry.Entity)
+	if !ok || len(kind.children) == 0 {
+		return nil
+	}
+	stamp, isDeleted := deletedStamp(record)
+	prevStamp, wasDeleted := deletedStamp(previous)
+	switch {
+	case isDeleted && !wasDeleted:
+		return kind.trashChildren(tx, entry.EntityID, stamp)
+	case wasDeleted && !isDeleted:
+		return kind.restoreChildren(tx, entry.EntityID, prevStamp)
+	}
+	return nil
+}
+
+// deletedStamp - отметка удаления записи модели с полем DeletedAt
+func deletedStamp(record interface{}) (time.Time, bool) {
+	if record == nil {
+		return time.Time{}, false
+	}
+	field := reflect.Indirect(reflect.ValueOf(record)).FieldByName("DeletedAt")
+	if !field.IsValid() {
+		return time.Time{}, false
+	}
+	deletedAt, ok := field.Interface().(gorm.DeletedAt)
+	return deletedAt.Time, ok && deletedAt.Valid
+}
+
+// afterRevert - те же шаги, что после восстановления из корзины, для записи после отката:
+// лента синхронизации и одна активная программа. Возвращает
+// отправку события в поток тренировки, которую нужно вызвать после фиксации транзакции.
+func afterRevert(tx *gorm.DB, record interface{}, deleted bool) (func(), error) {
+	if !deleted {
+		if err := afterRestore(tx, record); err != nil {
+			return nil, err
+		}
+		switch r := record.(type) {
+		case *models.TrainingSession:
+			return func() { sessionEvents.Publish(r.ID, realtime.EventSessionUpdated, r) }, nil
+		case *models.TrainingSessionExercise:
+			return func() { sessionEvents.Publish(r.TrainingSessionID, realtime.EventExerciseUpdated, r) }, nil
+		}
+		return nil, nil
+	}
+
+	switch r := record.(type) {
+	case *models.TrainingSession:
+		if err := logChange(tx, sessionChange(*r, models.SyncOpDelete)); err != nil {
+			return nil, err
+		}
+		return func() { sessionEvents.Publish(r.ID, realtime.EventSessionDeleted, gin.H{"id": r.ID}) }, nil
+	case *models.TrainingSessionExercise:
+		var session models.TrainingSession
+		if err := tx.Unscoped().First(&session, r.TrainingSessionID).Error; err != nil {
+			return nil, err
+		}
+		if err := logChange(tx, exerciseChange(session.ProfileID, *r, models.SyncOpDelete)); err != nil {
+			return nil, err
+		}
+		return func() { sessionEvents.Publish(r.TrainingSessionID, realtime.EventExerciseDeleted, gin.H{"id": r.ID}) }, nil
+	case *models.BodyWeight:
+		return nil, logChange(tx, bodyWeightChange(*r, models.SyncOpDelete))
+	}
+	return nil, nil
+}
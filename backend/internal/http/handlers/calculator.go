@@ -1,19 +1,26 @@
 package handlers
 
 import (
+	"errors"
 	"math"
 	"net/http"
+	"strconv"
 
 	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/realtime"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// HandleCalculate1RM - расчет повторного максимума и формирование программы подходов
-func HandleCalculate1RM(c *gin.Context) {
+// HandleCalculate1RM - расчет повторного максимума и формирование программы подходов.
+// Если указаны RPE или RIR, к повторениям добавляется запас (5 повторений @ RPE 8 ≈ 7ПМ).
+// Подходы строятся по выбранной схеме; с apply схема сразу записывается в программу или сессию.
+func HandleCalculate1RM(c *gin.Context, db *gorm.DB) {
 	var req models.OneRMRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
 		return
 	}
 
@@ -21,73 +28,271 @@ func HandleCalculate1RM(c *gin.Context) {
 		req.Formula = "brzycki"
 	}
 
-	oneRM := calculate1RM(req.Weight, req.Reps, req.Formula)
-	targetWeight := round(oneRM * req.Percentage / 100.0)
-	targetReps := calculateTargetReps(req.Percentage)
+	reps := float64(req.Reps)
+	if req.RIR != nil {
+		reps += float64(*req.RIR)
+	} else if req.RPE > 0 {
+		reps += rpeReserve(req.RPE)
+	}
+	reps = math.Min(reps, maxRepsCap)
+
+	profileID := req.ProfileID
+	if req.Apply != nil {
+		// Схема округляется по залу того профиля, в который записывается
+		if profileID != 0 && profileID != req.Apply.ProfileID {
+			respondInvalid(c, invalidField("apply.profileId", "eqfield", "must match profileId"))
+			return
+		}
+		profileID = req.Apply.ProfileID
+	}
+	var rounder func(float64) float64
+	if profileID != 0 {
+		var profile models.Profile
+		if err := db.First(&profile, profileID).Error; err != nil {
+			respondError(c, http.StatusNotFound, "Profile not found")
+			return
+		}
+		gymID := ""
+		if req.GymID != 0 {
+			gymID = strconv.FormatUint(uint64(req.GymID), 10)
+		}
+		gym, err := findGym(db, profile.ID, gymID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				respondError(c, http.StatusNotFound, "Gym not found")
+				return
+			}
+			respondInternal(c, err)
+			return
+		}
+		if req.Apply != nil {
+			rounder = exerciseRounder(gym, findExercise(db, req.Apply.Exercise), profileRounder(profile, gym))
+		} else {
+			rounder = profileRounder(profile, gym)
+		}
+	}
+
+	oneRM := calculate1RM(req.Weight, reps, req.Formula)
+	scheme := newSchemeOptions(req, oneRM, rounder)
+	sets := buildScheme(scheme)
 
-	sets := make([]models.SetValues, 6)
-	for i := 0; i < 6; i++ {
-		sets[i] = models.SetValues{Reps: targetReps, Weight: targetWeight}
+	estimates := make([]models.FormulaEstimate, 0, len(oneRMFormulaNames)+1)
+	for _, name := range append(oneRMFormulaNames, averageFormula) {
+		estimates = append(estimates, models.FormulaEstimate{Formula: name, OneRM: round(calculate1RM(req.Weight, reps, name))})
 	}
 
 	resp := models.OneRMResponse{
-		OneRM:        round(oneRM),
-		TargetWeight: targetWeight,
-		Percentage:   req.Percentage,
-		Formula:      req.Formula,
-		Sets:         sets,
+		OneRM:         round(oneRM),
+		TargetWeight:  scheme.Round(oneRM * req.Percentage / 100.0),
+		Percentage:    req.Percentage,
+		Formula:       req.Formula,
+		EffectiveReps: reps,
+		Estimates:     estimates,
+		Scheme:        scheme.Scheme,
+		Sets:          sets,
+	}
+
+	if req.Apply != nil {
+		if err := applyScheme(db, *req.Apply, scheme.Scheme, sets, &resp); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				detail := "Training session not found"
+				if req.Apply.ProgramID != 0 {
+					detail = "Program not found"
+				}
+				respondError(c, http.StatusNotFound, detail)
+				return
+			}
+			respondInternal(c, err)
+			return
+		}
+		c.JSON(http.StatusCreated, resp)
+		return
 	}
 
 	c.JSON(http.StatusOK, resp)
 }
 
-func calculate1RM(weight float64, reps int, formula string) float64 {
-	switch formula {
-	case "epley":
-		return calculateEpley(weight, reps)
-	case "lander":
-		return calculateLander(weight, reps)
-	default:
-		return calculateBrzycki(weight, reps)
-	}
+// averageFormula - среднее по всем формулам
+const averageFormula = "average"
+
+// oneRMFormulaNames - формулы в порядке вывода в ответе
+var oneRMFormulaNames = []string{"brzycki", "epley", "lander", "mayhew", "oconner", "wathan", "lombardi"}
+
+// oneRMFormulas - оценка 1ПМ по весу и числу повторений. Повторения дробные,
+// чтобы учитывать половинки RPE.
+var oneRMFormulas = map[string]func(weight, reps float64) float64{
+	"brzycki":  calculateBrzycki,
+	"epley":    calculateEpley,
+	"lander":   calculateLander,
+	"mayhew":   calculateMayhew,
+	"oconner":  calculateOConner,
+	"wathan":   calculateWathan,
+	"lombardi": calculateLombardi,
 }
 
-func calculateBrzycki(weight float64, reps int) float64 {
-	if reps == 1 {
+// validFormula сообщает, известна ли формула (включая среднее)
+func validFormula(name string) bool {
+	_, ok := oneRMFormulas[name]
+	return ok || name == averageFormula
+}
+
+// calculate1RM - оценка 1ПМ по выбранной формуле, неизвестная формула считается как brzycki.
+// Один подход в одно повторение и есть максимум, формулы для него не применяются.
+// Повторения сверх maxRepsCap (например, 20 повторений и еще 10 в запасе) считаются
+// как maxRepsCap: дальше формулы не работают, а brzycki и lander уходят в бесконечность.
+func calculate1RM(weight, reps float64, formula string) float64 {
+	if reps <= 1 {
 		return weight
 	}
-	return weight * (36.0 / (37.0 - float64(reps)))
+	reps = math.Min(reps, maxRepsCap)
+	if formula == averageFormula {
+		var sum float64
+		for _, name := range oneRMFormulaNames {
+			sum += oneRMFormulas[name](weight, reps)
+		}
+		return sum / float64(len(oneRMFormulaNames))
+	}
+	if calc, ok := oneRMFormulas[formula]; ok {
+		return calc(weight, reps)
+	}
+	return calculateBrzycki(weight, reps)
 }
 
-func calculateEpley(weight float64, reps int) float64 {
-	if reps == 1 {
-		return weight
+// calculateBrzycki - знаменатель обращается в ноль на 37 повторениях, поэтому
+// повторения ограничены maxRepsCap
+func calculateBrzycki(weight, reps float64) float64 {
+	return weight * (36.0 / (37.0 - math.Min(reps, maxRepsCap)))
+}
+
+func calculateEpley(weight, reps float64) float64 {
+	return weight * (1.0 + reps/30.0)
+}
+
+// calculateLander - знаменатель становится отрицательным после 37 повторений,
+// поэтому повторения ограничены maxRepsCap
+func calculateLander(weight, reps float64) float64 {
+	return weight * (100.0 / (101.3 - 2.67123*math.Min(reps, maxRepsCap)))
+}
+
+func calculateMayhew(weight, reps float64) float64 {
+	return weight * 100.0 / (52.2 + 41.9*math.Exp(-0.055*reps))
+}
+
+func calculateOConner(weight, reps float64) float64 {
+	return weight * (1.0 + 0.025*reps)
+}
+
+func calculateWathan(weight, reps float64) float64 {
+	return weight * 100.0 / (48.8 + 53.8*math.Exp(-0.075*reps))
+}
+
+func calculateLombardi(weight, reps float64) float64 {
+	return weight * math.Pow(reps, 0.10)
+}
+
+// rpeReserveTable - повторения в запасе по RPE (шкала RIR)
+var rpeReserveTable = map[float64]float64{
+	10:  0,
+	9.5: 0.5,
+	9:   1,
+	8.5: 1.5,
+	8:   2,
+	7.5: 2.5,
+	7:   3,
+	6.5: 3.5,
+	6:   4,
+}
+
+// rpeReserve - повторения в запасе для RPE; значения между шагами таблицы
+// округляются вверх до ближайшей половинки, чтобы оценка не завышалась
+func rpeReserve(rpe float64) float64 {
+	if rpe >= 10 {
+		return 0
 	}
-	return weight * (1.0 + float64(reps)/30.0)
+	if rpe < 6 {
+		return rpeReserveTable[6]
+	}
+	return rpeReserveTable[math.Ceil(rpe*2)/2]
 }
 
-func calculateLander(weight float64, reps int) float64 {
-	if reps == 1 {
-		return weight
+// applyScheme записывает рабочие подходы схемы в упражнение программы или всю схему
+// с разминкой в упражнение сессии
+func applyScheme(db *gorm.DB, target models.SchemeTarget, scheme string, sets []models.SetValues, resp *models.OneRMResponse) error {
+	if target.ProgramID != 0 {
+		var working []models.SetValues
+		for _, s := range sets {
+			if s.Type != models.SetTypeWarmup {
+				working = append(working, s)
+			}
+		}
+		// В упражнении программы один вес и одно число повторений - берется самый тяжелый подход
+		top := working[0]
+		for _, s := range working {
+			if s.Weight > top.Weight {
+				top = s
+			}
+		}
+
+		exercise := models.ProgramExercise{
+			Exercise:  target.Exercise,
+			DayOfWeek: target.DayOfWeek,
+			Sets:      len(working),
+			Reps:      top.Reps,
+			Weight:    top.Weight,
+			Notes:     schemeSummary(scheme, sets),
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Блокируем программу, чтобы параллельные записи не получили один порядок
+			var program models.TrainingProgram
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ? AND profile_id = ?", target.ProgramID, target.ProfileID).First(&program).Error; err != nil {
+				return err
+			}
+			var maxOrder int
+			if err := tx.Model(&models.ProgramExercise{}).Where("program_id = ? AND day_of_week = ?", program.ID, target.DayOfWeek).
+				Select("COALESCE(MAX(\"order\"), 0)").Scan(&maxOrder).Error; err != nil {
+				return err
+			}
+			exercise.ProgramID = program.ID
+			exercise.Order = maxOrder + 1
+			return tx.Create(&exercise).Error
+		})
+		if err != nil {
+			return err
+		}
+		resp.ProgramExercise = &exercise
+		return nil
+	}
+
+	var session models.TrainingSession
+	exercise := models.TrainingSessionExercise{
+		Exercise: target.Exercise,
+		Sets:     schemeSets(sets),
 	}
-	return weight * (100.0 / (101.3 - 2.67123*float64(reps)))
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := lockSession(tx, target.ProfileID, target.SessionID, &session); err != nil {
+			return err
+		}
+		order, err := nextSessionExerciseOrder(tx, session.ID)
+		if err != nil {
+			return err
+		}
+		exercise.TrainingSessionID = session.ID
+		exercise.Order = order
+		if err := tx.Create(&exercise).Error; err != nil {
+			return err
+		}
+		return logChange(tx, exerciseChange(session.ProfileID, exercise, models.SyncOpUpsert))
+	})
+	if err != nil {
+		return err
+	}
+
+	sessionEvents.Publish(session.ID, realtime.EventExerciseAdded, exercise)
+	resp.SessionExercise = &exercise
+	return nil
 }
 
 func round(v float64) float64 {
 	return math.Round(v*100) / 100
 }
-
-func calculateTargetReps(percentage float64) int {
-	switch {
-	case percentage >= 90:
-		return 3
-	case percentage >= 80:
-		return 5
-	case percentage >= 70:
-		return 8
-	case percentage >= 60:
-		return 10
-	default:
-		return 12
-	}
-}
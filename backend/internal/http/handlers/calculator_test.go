@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalculate1RM(t *testing.T) {
+	tests := []struct {
+		name    string
+		weight  float64
+		reps    float64
+		formula string
+		want    float64
+	}{
+		{"single rep is the max", 100, 1, "brzycki", 100},
+		{"zero reps", 100, 0, "epley", 100},
+		{"brzycki", 100, 5, "brzycki", 112.5},
+		{"epley", 100, 10, "epley", 133.33},
+		{"oconner", 100, 10, "oconner", 125},
+		{"lander", 100, 5, "lander", 113.71},
+		{"half reps from RPE", 100, 5.5, "epley", 118.33},
+		{"unknown formula falls back to brzycki", 100, 5, "unknown", 112.5},
+		{"reps above the cap count as the cap", 100, 30, "brzycki", 211.76},
+		{"brzycki singularity", 100, 37, "brzycki", 211.76},
+		{"lander past its singularity", 100, 40, "lander", 208.88},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := round(calculate1RM(tt.weight, tt.reps, tt.formula))
+			if got != tt.want {
+				t.Errorf("calculate1RM(%v, %v, %q) = %v, want %v", tt.weight, tt.reps, tt.formula, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalculate1RMAverage(t *testing.T) {
+	var sum float64
+	for _, name := range oneRMFormulaNames {
+		sum += calculate1RM(100, 8, name)
+	}
+	want := round(sum / float64(len(oneRMFormulaNames)))
+	if got := round(calculate1RM(100, 8, averageFormula)); got != want {
+		t.Errorf("average = %v, want %v", got, want)
+	}
+}
+
+// Каждая формула должна давать конечную оценку не меньше веса при любом числе
+// повторений, которое допускает запрос (до 30 повторений и 10 в запасе)
+func TestCalculate1RMFiniteForAllFormulas(t *testing.T) {
+	for _, name := range append(oneRMFormulaNames, averageFormula) {
+		for reps := 1.0; reps <= 40; reps += 0.5 {
+			got := calculate1RM(100, reps, name)
+			if math.IsInf(got, 0) || math.IsNaN(got) || got < 100 {
+				t.Errorf("calculate1RM(100, %v, %q) = %v", reps, name, got)
+			}
+		}
+	}
+}
+
+func TestRPEReserve(t *testing.T) {
+	tests := []struct {
+		rpe  float64
+		want float64
+	}{
+		{10, 0},
+		{10.5, 0},
+		{9.5, 0.5},
+		{9, 1},
+		{8, 2},
+		{7.8, 2},
+		{7.2, 2.5},
+		{6, 4},
+		{4, 4},
+	}
+	for _, tt := range tests {
+		if got := rpeReserve(tt.rpe); got != tt.want {
+			t.Errorf("rpeReserve(%v) = %v, want %v", tt.rpe, got, tt.want)
+		}
+	}
+}
+
+func TestEstimateCapsRepsInReserve(t *testing.T) {
+	rir := 10
+	set := sessionSet{Load: 100}
+	set.Set.Reps = 20
+	set.Set.RIR = &rir
+
+	opts := oneRMOptions{Formula: "brzycki", MaxReps: maxRepsCap, UseRPE: true}
+	want := calculate1RM(100, maxRepsCap, "brzycki")
+	if got := opts.estimate(set); got != want {
+		t.Errorf("estimate = %v, want %v", got, want)
+	}
+}
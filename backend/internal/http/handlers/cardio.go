@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"training-tracker/backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Cardio activities
+
+func HandleAddCardioActivity(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	sessionID := c.Param("sessionId")
+
+	var session models.TrainingSession
+	if err := db.Where("id = ? AND profile_id = ?", sessionID, profileID).First(&session).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Training session not found")
+		return
+	}
+
+	var req models.CardioActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	sid, _ := strconv.ParseUint(sessionID, 10, 64)
+	activity := models.CardioActivity{TrainingSessionID: uint(sid)}
+	applyCardioActivityRequest(&activity, req)
+
+	if err := db.Create(&activity).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, activity)
+}
+
+func HandleUpdateCardioActivity(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	sessionID := c.Param("sessionId")
+	activityID := c.Param("activityId")
+
+	var session models.TrainingSession
+	if err := db.Where("id = ? AND profile_id = ?", sessionID, profileID).First(&session).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Training session not found")
+		return
+	}
+
+	var activity models.CardioActivity
+	if err := db.Where("id = ? AND training_session_id = ?", activityID, sessionID).First(&activity).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Activity not found")
+		return
+	}
+	if !checkIfMatch(c, activity.Version, activity) {
+		return
+	}
+
+	var req models.CardioActivityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	applyCardioActivityRequest(&activity, req)
+	activity.UpdatedAt = time.Now()
+
+	activity.Version++
+	if err := saveVersioned(db, &activity, activity.Version-1); err != nil {
+		writeSaveError(c, db, &activity, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(activity.Version))
+	c.JSON(http.StatusOK, activity)
+}
+
+func HandleDeleteCardioActivity(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	sessionID := c.Param("sessionId")
+	activityID := c.Param("activityId")
+
+	var session models.TrainingSession
+	if err := db.Where("id = ? AND profile_id = ?", sessionID, profileID).First(&session).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Training session not found")
+		return
+	}
+
+	result := db.Where("id = ? AND training_session_id = ?", activityID, sessionID).Delete(&models.CardioActivity{})
+	if result.Error != nil {
+		respondInternal(c, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "Activity not found")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func applyCardioActivityRequest(activity *models.CardioActivity, req models.CardioActivityRequest) {
+	activity.Activity = req.Activity
+	activity.Duration = req.Duration
+	activity.Distance = req.Distance
+	activity.Pace = req.Pace
+	if activity.Pace == 0 {
+		activity.Pace = calculatePace(req.Duration, req.Distance)
+	}
+	activity.AvgHeartRate = req.AvgHeartRate
+	activity.MaxHeartRate = req.MaxHeartRate
+	activity.Calories = req.Calories
+	activity.Intervals = req.Intervals
+	activity.Notes = req.Notes
+}
+
+// calculatePace - темп в секундах на километр
+func calculatePace(duration int, distance float64) float64 {
+	if duration <= 0 || distance <= 0 {
+		return 0
+	}
+	return round(float64(duration) / (distance / 1000))
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"training-tracker/backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// currentOneRMWindow - текущий 1ПМ считается как лучший результат за это окно
+// до последней тренировки упражнения, чтобы один неудачный день его не обнулял
+const currentOneRMWindow = 42 * 24 * time.Hour
+
+// maxRepsCap - верхняя граница для параметра maxReps: дальше формулы теряют смысл
+const maxRepsCap = 20
+
+// oneRMOptions - параметры оценки 1ПМ по подходам из журнала
+type oneRMOptions struct {
+	Formula string
+	MaxReps int  // подходы с большим числом повторений не учитываются
+	UseRPE  bool // добавлять к повторениям запас (RIR или 10 - RPE)
+}
+
+var defaultOneRMOptions = oneRMOptions{Formula: "brzycki", MaxReps: maxRepsForEstimate}
+
+// estimate - оценка 1ПМ по подходу, 0 если подход для оценки непригоден
+func (o oneRMOptions) estimate(s sessionSet) float64 {
+	if s.Load <= 0 || s.Set.Reps <= 0 || s.Set.Reps > o.MaxReps {
+		return 0
+	}
+	reps := float64(s.Set.Reps)
+	if o.UseRPE {
+		reps += repsInReserve(s.Set)
+	}
+	return calculate1RM(s.Load, reps, o.Formula)
+}
+
+// repsInReserve - сколько повторений оставалось в запасе: RIR, если указан, иначе по RPE
+func repsInReserve(set models.Set) float64 {
+	if set.RIR != nil {
+		return float64(*set.RIR)
+	}
+	if set.RPE > 0 {
+		return rpeReserve(set.RPE)
+	}
+	return 0
+}
+
+// oneRMHistory строит по каждому упражнению ряд лучших оценок 1ПМ за сессию
+func oneRMHistory(sets []sessionSet, opts oneRMOptions) map[string]*models.OneRMHistory {
+	type key struct {
+		exercise  string
+		sessionID uint
+	}
+	best := make(map[key]*models.OneRMPoint)
+	var order []key
+	for _, s := range sets {
+		e1rm := opts.estimate(s)
+		if e1rm <= 0 {
+			continue
+		}
+		k := key{s.Exercise, s.SessionID}
+		point, ok := best[k]
+		if !ok {
+			point = &models.OneRMPoint{SessionID: s.SessionID, Date: s.Date.Format(dateLayout)}
+			best[k] = point
+			order = append(order, k)
+		}
+		if e1rm > point.EstimatedOneRM {
+			point.EstimatedOneRM = round(e1rm)
+			point.Weight = s.Load
+			point.Reps = s.Set.Reps
+			point.RPE = s.Set.RPE
+		}
+	}
+
+	history := make(map[string]*models.OneRMHistory)
+	for _, k := range order {
+		h, ok := history[k.exercise]
+		if !ok {
+			h = &models.OneRMHistory{Exercise: k.exercise}
+			history[k.exercise] = h
+		}
+		h.Points = append(h.Points, *best[k])
+	}
+	for _, h := range history {
+		sort.SliceStable(h.Points, func(i, j int) bool { return h.Points[i].Date < h.Points[j].Date })
+		for _, p := range h.Points {
+			if p.EstimatedOneRM > h.Best {
+				h.Best = p.EstimatedOneRM
+			}
+		}
+		h.Current, h.CurrentDate = currentOneRM(h.Points)
+	}
+	return history
+}
+
+// currentOneRM - лучший результат за currentOneRMWindow до последней точки
+func currentOneRM(points []models.OneRMPoint) (float64, string) {
+	if len(points) == 0 {
+		return 0, ""
+	}
+	last, err := time.Parse(dateLayout, points[len(points)-1].Date)
+	if err != nil {
+		return 0, ""
+	}
+	windowStart := last.Add(-currentOneRMWindow).Format(dateLayout)
+
+	var current float64
+	var date string
+	for _, p := range points {
+		if p.Date >= windowStart && p.EstimatedOneRM > current {
+			current, date = p.EstimatedOneRM, p.Date
+		}
+	}
+	return current, date
+}
+
+// profileOneRMs - текущий 1ПМ по каждому упражнению профиля с настройками по умолчанию
+func profileOneRMs(db *gorm.DB, profile models.Profile) (map[string]float64, error) {
+	sets, err := loadProfileSets(db, profile)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]float64)
+	for exercise, h := range oneRMHistory(sets, defaultOneRMOptions) {
+		result[exercise] = h.Current
+	}
+	return result, nil
+}
+
+// HandleGetOneRMHistory - история расчетного 1ПМ по упражнениям из журнала тренировок.
+// Параметры: exercise (можно несколько), formula, maxReps, rpe, dateFrom, dateTo.
+// Текущий 1ПМ считается по всей истории, даты ограничивают только точки ряда.
+func HandleGetOneRMHistory(c *gin.Context, db *gorm.DB) {
+	var profile models.Profile
+	if err := db.First(&profile, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Profile not found")
+		return
+	}
+
+	opts := defaultOneRMOptions
+	if formula := c.Query("formula"); formula != "" {
+		if !validFormula(formula) {
+			respondInvalid(c, invalidField("formula", "oneof", "must be one of: "+oneRMFormulaList()))
+			return
+		}
+		opts.Formula = formula
+	}
+	if maxReps := c.Query("maxReps"); maxReps != "" {
+		value, err := strconv.Atoi(maxReps)
+		if err != nil || value < 1 || value > maxRepsCap {
+			respondInvalid(c, invalidField("maxReps", "range", "must be between 1 and "+strconv.Itoa(maxRepsCap)))
+			return
+		}
+		opts.MaxReps = value
+	}
+	if rpe := c.Query("rpe"); rpe != "" {
+		value, err := strconv.ParseBool(rpe)
+		if err != nil {
+			respondInvalid(c, invalidField("rpe", "type", "must be true or false"))
+			return
+		}
+		opts.UseRPE = value
+	}
+
+	var dateFrom, dateTo string
+	for _, param := range []struct {
+		name string
+		dst  *string
+	}{{"dateFrom", &dateFrom}, {"dateTo", &dateTo}} {
+		if value := c.Query(param.name); value != "" {
+			if _, err := parseDate(param.name, value); err != nil {
+				respondInvalid(c, err)
+				return
+			}
+			*param.dst = value
+		}
+	}
+
+	sets, err := loadProfileSets(db, profile)
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	filter := make(map[string]bool)
+	for _, exercise := range c.QueryArray("exercise") {
+		filter[exercise] = true
+	}
+
+	history := oneRMHistory(sets, opts)
+	exercises := make([]models.OneRMHistory, 0, len(history))
+	for name, h := range history {
+		if len(filter) > 0 && !filter[name] {
+			continue
+		}
+		points := make([]models.OneRMPoint, 0, len(h.Points))
+		for _, p := range h.Points {
+			if (dateFrom == "" || p.Date >= dateFrom) && (dateTo == "" || p.Date <= dateTo) {
+				points = append(points, p)
+			}
+		}
+		h.Points = points
+		exercises = append(exercises, *h)
+	}
+	sort.Slice(exercises, func(i, j int) bool { return exercises[i].Exercise < exercises[j].Exercise })
+
+	c.JSON(http.StatusOK, models.OneRMHistoryResponse{
+		Formula:     opts.Formula,
+		MaxReps:     opts.MaxReps,
+		RPEAdjusted: opts.UseRPE,
+		Exercises:   exercises,
+	})
+}
+
+func oneRMFormulaList() string {
+	return strings.Join(append(oneRMFormulaNames, averageFormula), ", ")
+}
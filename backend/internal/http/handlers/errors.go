@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"training-tracker/backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Ошибки API отдаются в формате RFC 7807 (application/problem+json)
+// с машиночитаемым code и ошибками по полям в errors.
+
+const problemContentType = "application/problem+json"
+
+var problemCodes = map[int]string{
+	http.StatusBadRequest:            "bad_request",
+	http.StatusForbidden:             "forbidden",
+	http.StatusNotFound:              "not_found",
+	http.StatusConflict:              "conflict",
+	http.StatusPreconditionFailed:    "precondition_failed",
+	http.StatusRequestEntityTooLarge: "payload_too_large",
+	http.StatusUnprocessableEntity:   "unprocessable_entity",
+	http.StatusInternalServerError:   "internal_error",
+}
+
+func respondProblem(c *gin.Context, problem models.Problem) {
+	if problem.Type == "" {
+		problem.Type = "about:blank"
+	}
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+	if problem.Code == "" {
+		problem.Code = problemCodes[problem.Status]
+	}
+	problem.Instance = c.Request.URL.Path
+	problem.Error = problem.Detail
+
+	c.Header("Content-Type", problemContentType)
+	c.JSON(problem.Status, problem)
+}
+
+func respondError(c *gin.Context, status int, detail string) {
+	respondProblem(c, models.Problem{Status: status, Detail: detail})
+}
+
+// respondInternal переводит известные ошибки GORM в 404/409,
+// остальные логирует и отвечает 500 без текста ошибки базы
+func respondInternal(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		respondError(c, http.StatusNotFound, "Record not found")
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		respondError(c, http.StatusConflict, "Record already exists")
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		respondError(c, http.StatusConflict, "Record is referenced by other data")
+	default:
+		log.Printf("error: %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		respondError(c, http.StatusInternalServerError, "Internal server error")
+	}
+}
+
+// respondInvalid - 400 для ошибок разбора и проверки запроса
+func respondInvalid(c *gin.Context, err error) {
+	if errors.Is(err, io.EOF) {
+		respondError(c, http.StatusBadRequest, "Request body is required")
+		return
+	}
+
+	fields := fieldErrors(err)
+	if len(fields) == 0 {
+		respondError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	respondProblem(c, models.Problem{
+		Status: http.StatusBadRequest,
+		Title:  "Validation failed",
+		Code:   "validation_failed",
+		Detail: validationDetail(fields),
+		Errors: fields,
+	})
+}
+
+// validationDetail собирает ошибки полей в одну строку для detail
+func validationDetail(fields []models.FieldError) string {
+	messages := make([]string, len(fields))
+	for i, f := range fields {
+		messages[i] = f.Field + " " + f.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// respondStale - 412 с актуальным состоянием записи
+func respondStale(c *gin.Context, current interface{}) {
+	respondProblem(c, models.Problem{
+		Status:  http.StatusPreconditionFailed,
+		Detail:  "Resource was modified by another request",
+		Current: current,
+	})
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Оптимистичная блокировка: ETag записи - ее версия. Клиент присылает его в If-Match,
+// и устаревшая запись (например, из второй вкладки с автосохранением) получает 412.
+
+var errStaleVersion = errors.New("resource was modified")
+
+func versionETag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// checkIfMatch сравнивает If-Match с текущей версией записи и при несовпадении отвечает 412.
+// Запросы без заголовка не проверяются, чтобы старые клиенты продолжали работать.
+func checkIfMatch(c *gin.Context, version int, current interface{}) bool {
+	header := c.GetHeader("If-Match")
+	if header == "" || header == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == versionETag(version) {
+			return true
+		}
+	}
+	c.Header("ETag", versionETag(version))
+	respondStale(c, current)
+	return false
+}
+
+// saveVersioned сохраняет запись, только если в базе осталась версия prev.
+// Версию в model вызывающий код уже увеличил.
+func saveVersioned(db *gorm.DB, model interface{}, prev int) error {
+	result := db.Model(model).Where("version = ?", prev).Select("*").Updates(model)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errStaleVersion
+	}
+	return nil
+}
+
+// writeSaveError отвечает на ошибку saveVersioned: 412 с перечитанной записью,
+// 404, если запись успели удалить, или 500
+func writeSaveError(c *gin.Context, db *gorm.DB, model interface{}, err error) {
+	if errors.Is(err, errStaleVersion) {
+		err = db.First(model).Error
+	}
+	if err == nil {
+		respondStale(c, model)
+		return
+	}
+	respondInternal(c, err)
+}
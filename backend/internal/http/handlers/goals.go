@@ -16,7 +16,7 @@ func HandleGetGoals(c *gin.Context, db *gorm.DB) {
 
 	var goals []models.Goal
 	if err := db.Where("profile_id = ?", profileID).Order("created_at DESC").Find(&goals).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -28,7 +28,7 @@ func HandleCreateGoal(c *gin.Context, db *gorm.DB) {
 
 	var req models.GoalRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
 		return
 	}
 
@@ -36,9 +36,9 @@ func HandleCreateGoal(c *gin.Context, db *gorm.DB) {
 	var targetDate time.Time
 	var err error
 	if req.TargetDate != "" {
-		targetDate, err = time.Parse("2006-01-02", req.TargetDate)
+		targetDate, err = parseDate("targetDate", req.TargetDate)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			respondInvalid(c, err)
 			return
 		}
 	} else {
@@ -77,7 +77,7 @@ func HandleCreateGoal(c *gin.Context, db *gorm.DB) {
 	}
 
 	if err := db.Create(&goal).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -85,25 +85,37 @@ func HandleCreateGoal(c *gin.Context, db *gorm.DB) {
 }
 
 func HandleUpdateGoal(c *gin.Context, db *gorm.DB) {
+	updateGoal(c, db, bindJSON)
+}
+
+// HandlePatchGoal - частичное обновление цели (JSON merge patch)
+func HandlePatchGoal(c *gin.Context, db *gorm.DB) {
+	updateGoal(c, db, bindMergePatch)
+}
+
+func updateGoal(c *gin.Context, db *gorm.DB, bind requestBinder) {
 	profileID := c.Param("id")
 	goalID := c.Param("goalId")
 
 	var goal models.Goal
 	if err := db.Where("id = ? AND profile_id = ?", goalID, profileID).First(&goal).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		respondError(c, http.StatusNotFound, "Goal not found")
+		return
+	}
+	if !checkIfMatch(c, goal.Version, goal) {
 		return
 	}
 
 	var req models.GoalRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bind(c, goalRequest(goal), &req); err != nil {
+		respondInvalid(c, err)
 		return
 	}
 
 	if req.TargetDate != "" {
-		targetDate, err := time.Parse("2006-01-02", req.TargetDate)
+		targetDate, err := parseDate("targetDate", req.TargetDate)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			respondInvalid(c, err)
 			return
 		}
 		goal.TargetDate = targetDate
@@ -125,27 +137,45 @@ func HandleUpdateGoal(c *gin.Context, db *gorm.DB) {
 		goal.AchievedDate = &now
 	}
 
-	if err := db.Save(&goal).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	goal.Version++
+	if err := saveVersioned(db, &goal, goal.Version-1); err != nil {
+		writeSaveError(c, db, &goal, err)
 		return
 	}
 
+	c.Header("ETag", versionETag(goal.Version))
 	c.JSON(http.StatusOK, goal)
 }
 
+// goalRequest - текущее состояние цели в виде запроса на обновление
+func goalRequest(goal models.Goal) models.GoalRequest {
+	return models.GoalRequest{
+		Title:       goal.Title,
+		Description: goal.Description,
+		Type:        goal.Type,
+		Exercise:    goal.Exercise,
+		TargetValue: goal.TargetValue,
+		Unit:        goal.Unit,
+		TargetDate:  goal.TargetDate.Format("2006-01-02"),
+	}
+}
+
 func HandleUpdateGoalProgress(c *gin.Context, db *gorm.DB) {
 	profileID := c.Param("id")
 	goalID := c.Param("goalId")
 
 	var goal models.Goal
 	if err := db.Where("id = ? AND profile_id = ?", goalID, profileID).First(&goal).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Goal not found"})
+		respondError(c, http.StatusNotFound, "Goal not found")
+		return
+	}
+	if !checkIfMatch(c, goal.Version, goal) {
 		return
 	}
 
 	var req models.GoalProgressRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
 		return
 	}
 
@@ -158,11 +188,13 @@ func HandleUpdateGoalProgress(c *gin.Context, db *gorm.DB) {
 		goal.AchievedDate = &now
 	}
 
-	if err := db.Save(&goal).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	goal.Version++
+	if err := saveVersioned(db, &goal, goal.Version-1); err != nil {
+		writeSaveError(c, db, &goal, err)
 		return
 	}
 
+	c.Header("ETag", versionETag(goal.Version))
 	c.JSON(http.StatusOK, goal)
 }
 
@@ -170,8 +202,13 @@ func HandleDeleteGoal(c *gin.Context, db *gorm.DB) {
 	profileID := c.Param("id")
 	goalID := c.Param("goalId")
 
-	if err := db.Where("id = ? AND profile_id = ?", goalID, profileID).Delete(&models.Goal{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	result := db.Where("id = ? AND profile_id = ?", goalID, profileID).Delete(&models.Goal{})
+	if result.Error != nil {
+		respondInternal(c, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "Goal not found")
 		return
 	}
 
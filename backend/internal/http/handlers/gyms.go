@@ -0,0 +1,268 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/plates"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Gym handlers
+
+func HandleGetGyms(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+
+	var gyms []models.Gym
+	if err := db.Where("profile_id = ?", profileID).Order("name ASC").Find(&gyms).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gyms)
+}
+
+func HandleCreateGym(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	pid, err := strconv.ParseUint(profileID, 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid profile ID")
+		return
+	}
+
+	var req models.GymRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	gym := models.Gym{ProfileID: uint(pid)}
+	applyGymRequest(&gym, req)
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Первый зал профиля сразу становится выбранным
+		var selected int64
+		if err := tx.Model(&models.Gym{}).Where("profile_id = ? AND is_selected = ?", pid, true).Count(&selected).Error; err != nil {
+			return err
+		}
+		if selected == 0 {
+			gym.IsSelected = true
+		} else if gym.IsSelected {
+			if err := deselectGyms(tx, gym.ProfileID, 0); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&gym).Error
+	})
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gym)
+}
+
+func HandleUpdateGym(c *gin.Context, db *gorm.DB) {
+	updateGym(c, db, bindJSON)
+}
+
+// HandlePatchGym - частичное обновление зала (JSON merge patch)
+func HandlePatchGym(c *gin.Context, db *gorm.DB) {
+	updateGym(c, db, bindMergePatch)
+}
+
+func updateGym(c *gin.Context, db *gorm.DB, bind requestBinder) {
+	profileID := c.Param("id")
+	gymID := c.Param("gymId")
+
+	var gym models.Gym
+	if err := db.Where("id = ? AND profile_id = ?", gymID, profileID).First(&gym).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Gym not found")
+		return
+	}
+	if !checkIfMatch(c, gym.Version, gym) {
+		return
+	}
+
+	var req models.GymRequest
+	if err := bind(c, gymRequest(gym), &req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	selecting := req.IsSelected && !gym.IsSelected
+	applyGymRequest(&gym, req)
+	gym.UpdatedAt = time.Now()
+
+	gym.Version++
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if selecting {
+			if err := deselectGyms(tx, gym.ProfileID, gym.ID); err != nil {
+				return err
+			}
+		}
+		return saveVersioned(tx, &gym, gym.Version-1)
+	})
+	if err != nil {
+		writeSaveError(c, db, &gym, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(gym.Version))
+	c.JSON(http.StatusOK, gym)
+}
+
+// HandleSelectGym делает зал выбранным: по нему округляются веса и подбираются упражнения
+func HandleSelectGym(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	gymID := c.Param("gymId")
+
+	var gym models.Gym
+	if err := db.Where("id = ? AND profile_id = ?", gymID, profileID).First(&gym).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Gym not found")
+		return
+	}
+	if !checkIfMatch(c, gym.Version, gym) {
+		return
+	}
+
+	gym.IsSelected = true
+	gym.UpdatedAt = time.Now()
+	gym.Version++
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := deselectGyms(tx, gym.ProfileID, gym.ID); err != nil {
+			return err
+		}
+		return saveVersioned(tx, &gym, gym.Version-1)
+	})
+	if err != nil {
+		writeSaveError(c, db, &gym, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(gym.Version))
+	c.JSON(http.StatusOK, gym)
+}
+
+// HandleDeleteGym убирает зал в корзину. Если он был выбранным, выбранным
+// становится зал профиля, который менялся последним.
+func HandleDeleteGym(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	gymID := c.Param("gymId")
+
+	var gym models.Gym
+	if err := db.Where("id = ? AND profile_id = ?", gymID, profileID).First(&gym).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Gym not found")
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := moveToTrash(tx, "gym", gym.ID); err != nil {
+			return err
+		}
+		if !gym.IsSelected {
+			return nil
+		}
+		var next models.Gym
+		err := tx.Where("profile_id = ?", gym.ProfileID).Order("updated_at DESC, id DESC").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Updates(map[string]interface{}{"is_selected": true, "version": gorm.Expr("version + 1")}).Error
+	})
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func applyGymRequest(gym *models.Gym, req models.GymRequest) {
+	gym.Name = req.Name
+	gym.IsSelected = req.IsSelected
+	gym.Unit = req.Unit
+	if gym.Unit == "" {
+		gym.Unit = models.UnitKg
+	}
+	gym.Bars = req.Bars
+	gym.CollarWeight = req.CollarWeight
+	gym.Plates = req.Plates
+	gym.DumbbellMin = req.DumbbellMin
+	gym.DumbbellMax = req.DumbbellMax
+	gym.DumbbellIncrement = req.DumbbellIncrement
+	gym.Machines = req.Machines
+	gym.Notes = req.Notes
+}
+
+// gymRequest - текущее состояние зала в виде запроса на обновление
+func gymRequest(gym models.Gym) models.GymRequest {
+	return models.GymRequest{
+		Name:              gym.Name,
+		IsSelected:        gym.IsSelected,
+		Unit:              gym.Unit,
+		Bars:              gym.Bars,
+		CollarWeight:      gym.CollarWeight,
+		Plates:            gym.Plates,
+		DumbbellMin:       gym.DumbbellMin,
+		DumbbellMax:       gym.DumbbellMax,
+		DumbbellIncrement: gym.DumbbellIncrement,
+		Machines:          gym.Machines,
+		Notes:             gym.Notes,
+	}
+}
+
+// deselectGyms снимает выбор со всех залов профиля, кроме exceptID; версия этих залов
+// растет, чтобы If-Match со старым состоянием не прошел
+func deselectGyms(db *gorm.DB, profileID, exceptID uint) error {
+	return db.Model(&models.Gym{}).Where("profile_id = ? AND id != ? AND is_selected = ?", profileID, exceptID, true).
+		Updates(map[string]interface{}{"is_selected": false, "version": gorm.Expr("version + 1")}).Error
+}
+
+// findGym - зал профиля: указанный gymID или выбранный. nil без ошибки, если gymID
+// не указан и выбранного зала нет.
+func findGym(db *gorm.DB, profileID uint, gymID string) (*models.Gym, error) {
+	var gym models.Gym
+	query := db.Where("profile_id = ?", profileID)
+	if gymID != "" {
+		id, err := strconv.ParseUint(gymID, 10, 32)
+		if err != nil {
+			return nil, gorm.ErrRecordNotFound
+		}
+		query = query.Where("id = ?", id)
+	} else {
+		query = query.Where("is_selected = ?", true)
+	}
+	if err := query.First(&gym).Error; err != nil {
+		if gymID == "" && errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &gym, nil
+}
+
+// roundDumbbell - ближайшая гантель зала к весу в кг; вне ряда - крайняя
+func roundDumbbell(gym models.Gym, weight float64) float64 {
+	if gym.DumbbellMax <= 0 || gym.DumbbellIncrement <= 0 {
+		return weight
+	}
+	if gym.Unit == models.UnitLb {
+		weight /= plates.KgPerLb
+	}
+	steps := math.Round((weight - gym.DumbbellMin) / gym.DumbbellIncrement)
+	rounded := math.Min(math.Max(gym.DumbbellMin+steps*gym.DumbbellIncrement, gym.DumbbellMin), gym.DumbbellMax)
+	if gym.Unit == models.UnitLb {
+		rounded *= plates.KgPerLb
+	}
+	return round(rounded)
+}
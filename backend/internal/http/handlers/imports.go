@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"training-tracker/backend/internal/activityfile"
+	"training-tracker/backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// maxActivityFileSize - ограничение размера загружаемого файла активности
+const maxActivityFileSize = 20 << 20
+
+// HandleImportActivity - импорт GPX/TCX/FIT файла как кардио-сессии.
+// Повторная загрузка того же файла возвращает уже созданную сессию.
+func HandleImportActivity(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	pid, err := strconv.ParseUint(profileID, 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid profile ID")
+		return
+	}
+
+	var profile models.Profile
+	if err := db.First(&profile, pid).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Profile not found")
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "File is required")
+		return
+	}
+	if fileHeader.Size > maxActivityFileSize {
+		respondError(c, http.StatusRequestEntityTooLarge, "File is too large")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondInvalid(c, err)
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxActivityFileSize))
+	if err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	if respondImported(c, db, uint(pid), hash) {
+		return
+	}
+
+	parsed, err := activityfile.Parse(fileHeader.Filename, data)
+	if err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	session := models.TrainingSession{
+		ProfileID: uint(pid),
+		Type:      models.SessionTypeCardio,
+		Date:      parsed.StartTime,
+		Duration:  (parsed.Duration + 59) / 60,
+	}
+	activity := models.CardioActivity{
+		Activity:         parsed.Sport,
+		Duration:         parsed.Duration,
+		Distance:         parsed.Distance,
+		Pace:             calculatePace(parsed.Duration, parsed.Distance),
+		AvgHeartRate:     parsed.AvgHeartRate,
+		MaxHeartRate:     parsed.MaxHeartRate,
+		Calories:         parsed.Calories,
+		ElevationGain:    parsed.ElevationGain,
+		HeartRateSamples: parsed.HeartRate,
+		SourceFile:       fileHeader.Filename,
+		SourceHash:       hash,
+		ImportProfileID:  &profile.ID,
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		activity.TrainingSessionID = session.ID
+		if err := tx.Create(&activity).Error; err != nil {
+			return err
+		}
+		return logChange(tx, sessionChange(session, models.SyncOpUpsert))
+	})
+	// Тот же файл могли загрузить параллельно: уникальный индекс пропустил только одну загрузку
+	if errors.Is(err, gorm.ErrDuplicatedKey) && respondImported(c, db, uint(pid), hash) {
+		return
+	}
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.TrainingSessionWithExercises{
+		TrainingSession: session,
+		Exercises:       []models.TrainingSessionExercise{},
+		Activities:      []models.CardioActivity{activity},
+	})
+}
+
+// respondImported отвечает сессией, уже созданной из файла с хешем hash, если она есть
+func respondImported(c *gin.Context, db *gorm.DB, profileID uint, hash string) bool {
+	var existing models.CardioActivity
+	err := db.Where("import_profile_id = ? AND source_hash = ?", profileID, hash).First(&existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false
+	}
+	if err != nil {
+		respondInternal(c, err)
+		return true
+	}
+
+	var session models.TrainingSession
+	if err := db.First(&session, existing.TrainingSessionID).Error; err != nil {
+		respondInternal(c, err)
+		return true
+	}
+	c.JSON(http.StatusOK, models.TrainingSessionWithExercises{
+		TrainingSession: session,
+		Exercises:       []models.TrainingSessionExercise{},
+		Activities:      []models.CardioActivity{existing},
+	})
+	return true
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"training-tracker/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// maxRepsForEstimate - подходы с большим числом повторений не используются для оценки 1ПМ
+const maxRepsForEstimate = 12
+
+// bodyWeightLookup - вес тела профиля на произвольную дату
+type bodyWeightLookup struct {
+	entries  []models.BodyWeight // отсортированы по дате
+	fallback float64
+}
+
+func newBodyWeightLookup(profile models.Profile, entries []models.BodyWeight) bodyWeightLookup {
+	sorted := make([]models.BodyWeight, len(entries))
+	copy(sorted, entries)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Date.Before(sorted[j].Date) })
+
+	var fallback float64
+	if profile.Weight != nil {
+		fallback = *profile.Weight
+	}
+	return bodyWeightLookup{entries: sorted, fallback: fallback}
+}
+
+// at возвращает последнее взвешивание не позже даты, иначе ближайшее после,
+// иначе вес из профиля
+func (l bodyWeightLookup) at(date time.Time) float64 {
+	if len(l.entries) == 0 {
+		return l.fallback
+	}
+	idx := sort.Search(len(l.entries), func(i int) bool { return l.entries[i].Date.After(date) })
+	if idx == 0 {
+		return l.entries[0].Weight
+	}
+	return l.entries[idx-1].Weight
+}
+
+// nearest возвращает взвешивание, ближайшее к дате с любой стороны, иначе вес из профиля
+func (l bodyWeightLookup) nearest(date time.Time) float64 {
+	if len(l.entries) == 0 {
+		return l.fallback
+	}
+	idx := sort.Search(len(l.entries), func(i int) bool { return !l.entries[i].Date.Before(date) })
+	switch {
+	case idx == 0:
+		return l.entries[0].Weight
+	case idx == len(l.entries):
+		return l.entries[idx-1].Weight
+	}
+	before, after := l.entries[idx-1], l.entries[idx]
+	if date.Sub(before.Date) <= after.Date.Sub(date) {
+		return before.Weight
+	}
+	return after.Weight
+}
+
+// effectiveLoad - фактическая нагрузка подхода в кг.
+// Для упражнений с собственным весом это доля веса тела плюс дополнительный вес минус помощь.
+func effectiveLoad(set models.Set, exercise models.Exercise, bodyWeight float64) float64 {
+	if !exercise.IsBodyweight {
+		return set.Weight
+	}
+	ratio := exercise.BodyweightRatio
+	if ratio <= 0 {
+		ratio = 1
+	}
+	load := bodyWeight*ratio + set.Weight - set.Assistance
+	if load < 0 {
+		return 0
+	}
+	return load
+}
+
+// sessionSet - подход из журнала тренировок с рассчитанной нагрузкой
+type sessionSet struct {
+	SessionID uint
+	Date      time.Time
+	Exercise  string
+	Set       models.Set
+	Load      float64
+}
+
+// sessionExerciseKey - упражнение в рамках одной сессии
+type sessionExerciseKey struct {
+	sessionID uint
+	exercise  string
+}
+
+// loadSessionsWithExercises загружает все сессии профиля вместе с упражнениями и кардио
+func loadSessionsWithExercises(db *gorm.DB, profileID string) ([]models.TrainingSessionWithExercises, error) {
+	var sessions []models.TrainingSession
+	if err := db.Where("profile_id = ?", profileID).Order("date ASC").Find(&sessions).Error; err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return []models.TrainingSessionWithExercises{}, nil
+	}
+
+	ids := make([]uint, 0, len(sessions))
+	for _, s := range sessions {
+		ids = append(ids, s.ID)
+	}
+
+	var exercises []models.TrainingSessionExercise
+	if err := db.Where("training_session_id IN ?", ids).Order("\"order\" ASC, id ASC").Find(&exercises).Error; err != nil {
+		return nil, err
+	}
+	bySession := make(map[uint][]models.TrainingSessionExercise)
+	for _, ex := range exercises {
+		bySession[ex.TrainingSessionID] = append(bySession[ex.TrainingSessionID], ex)
+	}
+
+	var activities []models.CardioActivity
+	if err := db.Where("training_session_id IN ?", ids).Order("id ASC").Find(&activities).Error; err != nil {
+		return nil, err
+	}
+	activitiesBySession := make(map[uint][]models.CardioActivity)
+	for _, a := range activities {
+		activitiesBySession[a.TrainingSessionID] = append(activitiesBySession[a.TrainingSessionID], a)
+	}
+
+	result := make([]models.TrainingSessionWithExercises, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, models.TrainingSessionWithExercises{
+			TrainingSession: s,
+			Exercises:       bySession[s.ID],
+			Activities:      activitiesBySession[s.ID],
+		})
+	}
+	return result, nil
+}
+
+// loadProfileSets загружает подходы профиля с эффективной нагрузкой
+func loadProfileSets(db *gorm.DB, profile models.Profile) ([]sessionSet, error) {
+	var exercises []models.Exercise
+	if err := db.Find(&exercises).Error; err != nil {
+		return nil, err
+	}
+	exerciseMap := make(map[string]models.Exercise)
+	for _, ex := range exercises {
+		exerciseMap[ex.Name] = ex
+	}
+
+	sessions, err := loadSessionsWithExercises(db, strconv.FormatUint(uint64(profile.ID), 10))
+	if err != nil {
+		return nil, err
+	}
+
+	var bodyWeights []models.BodyWeight
+	if err := db.Where("profile_id = ?", profile.ID).Find(&bodyWeights).Error; err != nil {
+		return nil, err
+	}
+	return collectSessionSets(sessions, exerciseMap, newBodyWeightLookup(profile, bodyWeights)), nil
+}
+
+// collectSessionSets разворачивает сессии в плоский список подходов с эффективной нагрузкой.
+// Разминочные подходы пропускаются: они не входят в объем, рекорды и оценку 1ПМ.
+func collectSessionSets(sessions []models.TrainingSessionWithExercises, exerciseMap map[string]models.Exercise, bw bodyWeightLookup) []sessionSet {
+	var result []sessionSet
+	for _, s := range sessions {
+		bodyWeight := bw.at(s.Date)
+		for _, ex := range s.Exercises {
+			info := exerciseMap[ex.Exercise]
+			for _, set := range ex.Sets {
+				if set.IsWarmup() {
+					continue
+				}
+				result = append(result, sessionSet{
+					SessionID: s.ID,
+					Date:      s.Date,
+					Exercise:  ex.Exercise,
+					Set:       set,
+					Load:      effectiveLoad(set, info, bodyWeight),
+				})
+			}
+		}
+	}
+	return result
+}
+
+// estimateOneRM - оценка 1ПМ по подходу с настройками по умолчанию, 0 если подход для оценки непригоден
+func estimateOneRM(load float64, reps int) float64 {
+	return defaultOneRMOptions.estimate(sessionSet{Load: load, Set: models.Set{Reps: reps}})
+}
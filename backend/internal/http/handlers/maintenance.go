@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+
+	"training-tracker/backend/internal/integrity"
+	"training-tracker/backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HandleGetOrphans - отчет о записях, чей родитель уже удален
+func HandleGetOrphans(c *gin.Context, db *gorm.DB) {
+	reports, err := integrity.FindOrphans(db)
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, orphansResponse(reports, false))
+}
+
+// HandleDeleteOrphans удаляет найденных сирот, после чего можно создать внешние ключи
+func HandleDeleteOrphans(c *gin.Context, db *gorm.DB) {
+	reports, err := integrity.DeleteOrphans(db)
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+	integrity.EnsureForeignKeys(db)
+	c.JSON(http.StatusOK, orphansResponse(reports, true))
+}
+
+func orphansResponse(reports []models.OrphanReport, deleted bool) models.OrphansResponse {
+	total := 0
+	for _, r := range reports {
+		total += r.Count
+	}
+	return models.OrphansResponse{Orphans: reports, Total: total, Deleted: deleted}
+}
@@ -18,7 +18,7 @@ func HandleGetBodyWeight(c *gin.Context, db *gorm.DB) {
 
 	var weights []models.BodyWeight
 	if err := db.Where("profile_id = ?", profileID).Order("date DESC").Find(&weights).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -29,22 +29,22 @@ func HandleAddBodyWeight(c *gin.Context, db *gorm.DB) {
 	profileID := c.Param("id")
 	profileIDUint, err := strconv.ParseUint(profileID, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile ID"})
+		respondError(c, http.StatusBadRequest, "Invalid profile ID")
 		return
 	}
 
 	var req models.BodyWeightRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
 		return
 	}
 
 	// Parse date
 	var date time.Time
 	if req.Date != "" {
-		date, err = time.Parse("2006-01-02", req.Date)
+		date, err = parseDate("date", req.Date)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			respondInvalid(c, err)
 			return
 		}
 	} else {
@@ -58,8 +58,14 @@ func HandleAddBodyWeight(c *gin.Context, db *gorm.DB) {
 		Notes:     req.Notes,
 	}
 
-	if err := db.Create(&bodyWeight).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&bodyWeight).Error; err != nil {
+			return err
+		}
+		return logChange(tx, bodyWeightChange(bodyWeight, models.SyncOpUpsert))
+	})
+	if err != nil {
+		respondInternal(c, err)
 		return
 	}
 
@@ -67,26 +73,39 @@ func HandleAddBodyWeight(c *gin.Context, db *gorm.DB) {
 }
 
 func HandleUpdateBodyWeight(c *gin.Context, db *gorm.DB) {
+	updateBodyWeight(c, db, bindJSON)
+}
+
+// HandlePatchBodyWeight - частичное обновление записи веса (JSON merge patch)
+func HandlePatchBodyWeight(c *gin.Context, db *gorm.DB) {
+	updateBodyWeight(c, db, bindMergePatch)
+}
+
+func updateBodyWeight(c *gin.Context, db *gorm.DB, bind requestBinder) {
 	profileID := c.Param("id")
 	weightID := c.Param("weightId")
 
 	var bodyWeight models.BodyWeight
 	if err := db.Where("id = ? AND profile_id = ?", weightID, profileID).First(&bodyWeight).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Body weight record not found"})
+		respondError(c, http.StatusNotFound, "Body weight record not found")
+		return
+	}
+	if !checkIfMatch(c, bodyWeight.Version, bodyWeight) {
 		return
 	}
 
+	current := bodyWeightRequest(bodyWeight)
 	var req models.BodyWeightRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bind(c, current, &req); err != nil {
+		respondInvalid(c, err)
 		return
 	}
 
-	// Parse date if provided
-	if req.Date != "" {
-		date, err := time.Parse("2006-01-02", req.Date)
+	// Parse date if provided; the same day keeps the original time of the record
+	if req.Date != "" && req.Date != current.Date {
+		date, err := parseDate("date", req.Date)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			respondInvalid(c, err)
 			return
 		}
 		bodyWeight.Date = date
@@ -94,21 +113,49 @@ func HandleUpdateBodyWeight(c *gin.Context, db *gorm.DB) {
 
 	bodyWeight.Weight = req.Weight
 	bodyWeight.Notes = req.Notes
-
-	if err := db.Save(&bodyWeight).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	bodyWeight.Version++
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, &bodyWeight, bodyWeight.Version-1); err != nil {
+			return err
+		}
+		return logChange(tx, bodyWeightChange(bodyWeight, models.SyncOpUpsert))
+	})
+	if err != nil {
+		writeSaveError(c, db, &bodyWeight, err)
 		return
 	}
 
+	c.Header("ETag", versionETag(bodyWeight.Version))
 	c.JSON(http.StatusOK, bodyWeight)
 }
 
+// bodyWeightRequest - текущее состояние записи веса в виде запроса на обновление
+func bodyWeightRequest(bodyWeight models.BodyWeight) models.BodyWeightRequest {
+	return models.BodyWeightRequest{
+		Weight: bodyWeight.Weight,
+		Notes:  bodyWeight.Notes,
+		Date:   bodyWeight.Date.Format("2006-01-02"),
+	}
+}
+
 func HandleDeleteBodyWeight(c *gin.Context, db *gorm.DB) {
 	profileID := c.Param("id")
 	weightID := c.Param("weightId")
 
-	if err := db.Where("id = ? AND profile_id = ?", weightID, profileID).Delete(&models.BodyWeight{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var bodyWeight models.BodyWeight
+	if err := db.Where("id = ? AND profile_id = ?", weightID, profileID).First(&bodyWeight).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Body weight record not found")
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&bodyWeight).Error; err != nil {
+			return err
+		}
+		return logChange(tx, bodyWeightChange(bodyWeight, models.SyncOpDelete))
+	})
+	if err != nil {
+		respondInternal(c, err)
 		return
 	}
 
@@ -122,7 +169,7 @@ func HandleGetPersonalRecords(c *gin.Context, db *gorm.DB) {
 
 	var records []models.PersonalRecord
 	if err := db.Where("profile_id = ?", profileID).Order("date DESC").Find(&records).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -133,22 +180,22 @@ func HandleAddPersonalRecord(c *gin.Context, db *gorm.DB) {
 	profileID := c.Param("id")
 	profileIDUint, err := strconv.ParseUint(profileID, 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid profile ID"})
+		respondError(c, http.StatusBadRequest, "Invalid profile ID")
 		return
 	}
 
 	var req models.PersonalRecordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
 		return
 	}
 
 	// Parse date
 	var date time.Time
 	if req.Date != "" {
-		date, err = time.Parse("2006-01-02", req.Date)
+		date, err = parseDate("date", req.Date)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			respondInvalid(c, err)
 			return
 		}
 	} else {
@@ -164,7 +211,7 @@ func HandleAddPersonalRecord(c *gin.Context, db *gorm.DB) {
 	}
 
 	if err := db.Create(&record).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -175,8 +222,13 @@ func HandleDeletePersonalRecord(c *gin.Context, db *gorm.DB) {
 	profileID := c.Param("id")
 	recordID := c.Param("recordId")
 
-	if err := db.Where("id = ? AND profile_id = ?", recordID, profileID).Delete(&models.PersonalRecord{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	result := db.Where("id = ? AND profile_id = ?", recordID, profileID).Delete(&models.PersonalRecord{})
+	if result.Error != nil {
+		respondInternal(c, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "Personal record not found")
 		return
 	}
 
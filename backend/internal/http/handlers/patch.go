@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// requestBinder заполняет DTO запроса на обновление.
+// PUT берет тело целиком, PATCH накладывает его на текущее состояние записи.
+type requestBinder func(c *gin.Context, current interface{}, req interface{}) error
+
+func bindJSON(c *gin.Context, _ interface{}, req interface{}) error {
+	return c.ShouldBindJSON(req)
+}
+
+// bindMergePatch применяет JSON merge patch (RFC 7396) к current и проверяет результат
+// теми же правилами binding, что и PUT. null в патче сбрасывает поле.
+func bindMergePatch(c *gin.Context, current interface{}, req interface{}) error {
+	body, err := c.GetRawData()
+	if err != nil {
+		return err
+	}
+
+	patch, err := decodeJSONValue(body)
+	if err != nil {
+		return errors.New("invalid JSON in merge patch")
+	}
+	if _, ok := patch.(map[string]interface{}); !ok {
+		return errors.New("merge patch must be a JSON object")
+	}
+
+	original, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	target, err := decodeJSONValue(original)
+	if err != nil {
+		return err
+	}
+
+	merged, err := json.Marshal(mergePatch(target, patch))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(merged, req); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(req)
+}
+
+// mergePatch - алгоритм MergePatch из RFC 7396
+func mergePatch(target, patch interface{}) interface{} {
+	fields, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	result, ok := target.(map[string]interface{})
+	if !ok {
+		result = make(map[string]interface{})
+	}
+	for key, value := range fields {
+		if value == nil {
+			delete(result, key)
+			continue
+		}
+		result[key] = mergePatch(result[key], value)
+	}
+	return result
+}
+
+// decodeJSONValue разбирает JSON без потери точности чисел
+func decodeJSONValue(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/plates"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HandleGetPlateLoad - раскладка дисков на сторону для веса из набора зала (gymId
+// или выбранного), а без залов - из набора профиля.
+// Параметры: weight, unit (единицы weight, по умолчанию - набора), bar (другой гриф),
+// mode (nearest, down, up), gymId.
+func HandleGetPlateLoad(c *gin.Context, db *gorm.DB) {
+	var profile models.Profile
+	if err := db.First(&profile, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Profile not found")
+		return
+	}
+	gym, err := findGym(db, profile.ID, c.Query("gymId"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Gym not found")
+			return
+		}
+		respondInternal(c, err)
+		return
+	}
+	inventory := plateInventory(profile, gym)
+
+	weight, err := strconv.ParseFloat(c.Query("weight"), 64)
+	if err != nil || weight <= 0 || weight > 1000 {
+		respondInvalid(c, invalidField("weight", "range", "must be a number greater than 0 and at most 1000"))
+		return
+	}
+
+	switch unit := c.Query("unit"); {
+	case unit == "" || unit == inventory.Unit:
+	case unit == models.UnitKg:
+		weight /= plates.KgPerLb
+	case unit == models.UnitLb:
+		weight *= plates.KgPerLb
+	default:
+		respondInvalid(c, invalidField("unit", "oneof", "must be one of: kg, lb"))
+		return
+	}
+
+	if bar := c.Query("bar"); bar != "" {
+		value, err := strconv.ParseFloat(bar, 64)
+		if err != nil || value <= 0 || value > 100 {
+			respondInvalid(c, invalidField("bar", "range", "must be a number greater than 0 and at most 100"))
+			return
+		}
+		inventory.BarWeight = value
+	}
+
+	mode := c.DefaultQuery("mode", plates.ModeNearest)
+	if mode != plates.ModeNearest && mode != plates.ModeDown && mode != plates.ModeUp {
+		respondInvalid(c, invalidField("mode", "oneof", "must be one of: nearest, down, up"))
+		return
+	}
+
+	c.JSON(http.StatusOK, plates.NewLoader(inventory).Load(round(weight), mode))
+}
+
+// plateInventory - набор дисков зала, если в нем есть грифы, иначе набор профиля
+func plateInventory(profile models.Profile, gym *models.Gym) models.PlateInventory {
+	if gym != nil && gym.Has(models.EquipmentBarbell) {
+		inventory := gym.PlateInventory()
+		return plates.Normalize(&inventory)
+	}
+	return plates.Normalize(profile.Plates)
+}
+
+// profileRounder - округление веса в кг до собираемого набором дисков зала или профиля.
+// Таблица раскладок строится один раз, поэтому округлитель создается на весь запрос.
+func profileRounder(profile models.Profile, gym *models.Gym) func(float64) float64 {
+	return plates.NewLoader(plateInventory(profile, gym)).Round
+}
+
+// exerciseRounder - округление веса под оборудование упражнения: гантели зала
+// для упражнений с гантелями, диски (plateRounder из profileRounder) для остальных
+func exerciseRounder(gym *models.Gym, exercise models.Exercise, plateRounder func(float64) float64) func(float64) float64 {
+	if gym != nil && gym.Has(models.EquipmentDumbbell) && requires(exercise, models.EquipmentDumbbell) && !requires(exercise, models.EquipmentBarbell) {
+		return func(weight float64) float64 { return roundDumbbell(*gym, weight) }
+	}
+	return plateRounder
+}
+
+func requires(exercise models.Exercise, equipment string) bool {
+	for _, e := range exercise.Equipment {
+		if e == equipment {
+			return true
+		}
+	}
+	return false
+}
+
+// roundProgramWeight округляет вес упражнения программы под оборудование выбранного зала
+// или набор дисков профиля
+func roundProgramWeight(db *gorm.DB, profileID uint, exercise string, weight float64) (float64, error) {
+	if weight == 0 {
+		return 0, nil
+	}
+	var profile models.Profile
+	if err := db.First(&profile, profileID).Error; err != nil {
+		return 0, err
+	}
+	gym, err := findGym(db, profileID, "")
+	if err != nil {
+		return 0, err
+	}
+	return exerciseRounder(gym, findExercise(db, exercise), profileRounder(profile, gym))(weight), nil
+}
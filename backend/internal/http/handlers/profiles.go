@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
 	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/realtime"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -11,10 +14,42 @@ import (
 
 // Profile handlers
 
+// restoreProfilePath - единственная запись, разрешенная профилю в корзине
+const restoreProfilePath = "/api/profiles/:id/restore"
+
+// RequireActiveProfile отклоняет запись в данные профиля, пока профиль в корзине
+// (или не существует): иначе данные, добавленные к удаленному профилю, вернулись бы
+// при восстановлении рядом с теми, что удалены вместе с ним. Чтение не проверяется.
+func RequireActiveProfile(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions {
+			c.Next()
+			return
+		}
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil || c.FullPath() == restoreProfilePath {
+			c.Next()
+			return
+		}
+		if !profileExists(db.WithContext(c.Request.Context()), uint(id)) {
+			respondError(c, http.StatusNotFound, "Profile not found")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// profileExists - профиль есть и не в корзине
+func profileExists(db *gorm.DB, id uint) bool {
+	var count int64
+	return db.Model(&models.Profile{}).Where("id = ?", id).Count(&count).Error == nil && count > 0
+}
+
 func HandleListProfiles(c *gin.Context, db *gorm.DB) {
 	var profiles []models.Profile
 	if err := db.Find(&profiles).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, profiles)
@@ -23,12 +58,17 @@ func HandleListProfiles(c *gin.Context, db *gorm.DB) {
 func HandleCreateProfile(c *gin.Context, db *gorm.DB) {
 	var input models.Profile
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
+		return
+	}
+	if err := validateDisabledRecommendations(input.DisabledRecommendations); err != nil {
+		respondInvalid(c, err)
 		return
 	}
 
+	input.DeletedAt = gorm.DeletedAt{}
 	if err := db.Create(&input).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -36,17 +76,33 @@ func HandleCreateProfile(c *gin.Context, db *gorm.DB) {
 }
 
 func HandleUpdateProfile(c *gin.Context, db *gorm.DB) {
+	updateProfile(c, db, bindJSON)
+}
+
+// HandlePatchProfile - частичное обновление профиля (JSON merge patch)
+func HandlePatchProfile(c *gin.Context, db *gorm.DB) {
+	updateProfile(c, db, bindMergePatch)
+}
+
+func updateProfile(c *gin.Context, db *gorm.DB, bind requestBinder) {
 	id := c.Param("id")
 	var profile models.Profile
 
 	if err := db.First(&profile, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Profile not found"})
+		respondError(c, http.StatusNotFound, "Profile not found")
+		return
+	}
+	if !checkIfMatch(c, profile.Version, profile) {
 		return
 	}
 
 	var input models.Profile
-	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bind(c, profile, &input); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+	if err := validateDisabledRecommendations(input.DisabledRecommendations); err != nil {
+		respondInvalid(c, err)
 		return
 	}
 
@@ -59,24 +115,137 @@ func HandleUpdateProfile(c *gin.Context, db *gorm.DB) {
 	profile.Goal = input.Goal
 	profile.Experience = input.Experience
 	profile.Notes = input.Notes
+	profile.Plates = input.Plates
+	profile.DisabledRecommendations = input.DisabledRecommendations
 
-	if err := db.Save(&profile).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	profile.Version++
+	if err := saveVersioned(db, &profile, profile.Version-1); err != nil {
+		writeSaveError(c, db, &profile, err)
 		return
 	}
 
+	c.Header("ETag", versionETag(profile.Version))
 	c.JSON(http.StatusOK, profile)
 }
 
 func HandleDeleteProfile(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
 
-	if err := db.Delete(&models.Profile{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	var profile models.Profile
+	if err := db.First(&profile, id).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Profile not found")
+		return
+	}
+
+	var sessionIDs []uint
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.TrainingSession{}).Where("profile_id = ?", profile.ID).Pluck("id", &sessionIDs).Error; err != nil {
+			return err
+		}
+		if err := logProfileChanges(tx, profile.ID, models.SyncOpDelete); err != nil {
+			return err
+		}
+		// Все данные профиля уходят в корзину с одной отметкой времени, чтобы вернуться вместе с ним
+		now := time.Now()
+		for _, step := range profileDataSteps(tx, profile.ID) {
+			if err := tx.Model(step.model).Where(step.where, step.arg).Update("deleted_at", now).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(&profile).Update("deleted_at", now).Error
+	})
+	if err != nil {
+		respondInternal(c, err)
 		return
 	}
 
-	c.JSON(http.StatusNoContent, nil)
+	for _, sessionID := range sessionIDs {
+		sessionEvents.Publish(sessionID, realtime.EventSessionDeleted, gin.H{"id": sessionID})
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// dataStep - выборка данных профиля в одной таблице
+type dataStep struct {
+	model interface{}
+	where string
+	arg   interface{}
+}
+
+// profileDataSteps - данные профиля в корзине, от листьев к корню.
+// Подзапросы строятся на db: для восстановления и очистки он должен быть Unscoped,
+// чтобы видеть удаленные сессии и программы.
+func profileDataSteps(db *gorm.DB, profileID uint) []dataStep {
+	sessions := db.Model(&models.TrainingSession{}).Select("id").Where("profile_id = ?", profileID)
+	programs := db.Model(&models.TrainingProgram{}).Select("id").Where("profile_id = ?", profileID)
+
+	return []dataStep{
+		{&models.TrainingSessionExercise{}, "training_session_id IN (?)", sessions},
+		{&models.CardioActivity{}, "training_session_id IN (?)", sessions},
+		{&models.ProgramExercise{}, "program_id IN (?)", programs},
+		{&models.ProgramSession{}, "program_id IN (?)", programs},
+		{&models.TrainingSession{}, "profile_id = ?", profileID},
+		{&models.TrainingProgram{}, "profile_id = ?", profileID},
+		{&models.BodyWeight{}, "profile_id = ?", profileID},
+		{&models.PersonalRecord{}, "profile_id = ?", profileID},
+		{&models.Goal{}, "profile_id = ?", profileID},
+		{&models.Gym{}, "profile_id = ?", profileID},
+		{&models.ReadinessCheckIn{}, "profile_id = ?", profileID},
+	}
+}
+
+// logProfileChanges записывает в ленту синхронизации все сессии, упражнения
+// и замеры веса профиля, которые видит tx: перед удалением профиля или после
+// его восстановления
+func logProfileChanges(tx *gorm.DB, profileID uint, op string) error {
+	var sessions []models.TrainingSession
+	if err := tx.Where("profile_id = ?", profileID).Find(&sessions).Error; err != nil {
+		return err
+	}
+	var exercises []models.TrainingSessionExercise
+	err := tx.Where("training_session_id IN (?)", tx.Model(&models.TrainingSession{}).Select("id").Where("profile_id = ?", profileID)).
+		Find(&exercises).Error
+	if err != nil {
+		return err
+	}
+	var bodyWeights []models.BodyWeight
+	if err := tx.Where("profile_id = ?", profileID).Find(&bodyWeights).Error; err != nil {
+		return err
+	}
+
+	changes := make([]models.SyncChange, 0, len(sessions)+len(exercises)+len(bodyWeights))
+	for _, s := range sessions {
+		changes = append(changes, sessionChange(s, op))
+	}
+	for _, e := range exercises {
+		changes = append(changes, exerciseChange(profileID, e, op))
+	}
+	for _, w := range bodyWeights {
+		changes = append(changes, bodyWeightChange(w, op))
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&changes, 500).Error
+}
+
+// purgeProfileData окончательно удаляет профиль вместе со всеми его данными.
+// Внешние ключи делают то же каскадом, но на базах, где ключи еще не созданы
+// из-за сирот, удаление явное.
+func purgeProfileData(tx *gorm.DB, profileID uint) error {
+	unscoped := tx.Unscoped().Session(&gorm.Session{})
+	steps := append(profileDataSteps(unscoped, profileID),
+		dataStep{&models.Training{}, "profile_id = ?", profileID},
+		dataStep{&models.SyncChange{}, "profile_id = ?", profileID},
+		dataStep{&models.Profile{}, "id = ?", profileID},
+	)
+	for _, step := range steps {
+		if err := unscoped.Where(step.where, step.arg).Delete(step.model).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // handleGetProfileExercises - получение списка упражнений профиля
@@ -85,7 +254,7 @@ func HandleGetProfileExercises(c *gin.Context, db *gorm.DB) {
 
 	var trainings []models.Training
 	if err := db.Where("profile_id = ?", profileID).Find(&trainings).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -16,7 +17,7 @@ func HandleGetPrograms(c *gin.Context, db *gorm.DB) {
 
 	var programs []models.TrainingProgram
 	if err := db.Where("profile_id = ?", profileID).Order("created_at DESC").Find(&programs).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -28,23 +29,32 @@ func HandleCreateProgram(c *gin.Context, db *gorm.DB) {
 
 	var req models.TrainingProgramRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
 		return
 	}
 
-	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if req.StartDate == "" {
+		respondInvalid(c, invalidField("startDate", "required", "is required"))
+		return
+	}
+	if req.EndDate == "" {
+		respondInvalid(c, invalidField("endDate", "required", "is required"))
+		return
+	}
+	startDate, err := parseDate("startDate", req.StartDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format. Use YYYY-MM-DD"})
+		respondInvalid(c, err)
 		return
 	}
-	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	endDate, err := parseDate("endDate", req.EndDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format. Use YYYY-MM-DD"})
+		respondInvalid(c, err)
 		return
 	}
 
-	if req.IsActive {
-		db.Model(&models.TrainingProgram{}).Where("profile_id = ?", profileID).Update("is_active", false)
+	if err := checkDateOrder(startDate, endDate); err != nil {
+		respondInvalid(c, err)
+		return
 	}
 
 	pid, _ := strconv.ParseUint(profileID, 10, 64)
@@ -57,8 +67,16 @@ func HandleCreateProgram(c *gin.Context, db *gorm.DB) {
 		IsActive:    req.IsActive,
 	}
 
-	if err := db.Create(&program).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if program.IsActive {
+			if err := deactivatePrograms(tx, program.ProfileID, 0); err != nil {
+				return err
+			}
+		}
+		return tx.Create(&program).Error
+	})
+	if err != nil {
+		respondInternal(c, err)
 		return
 	}
 
@@ -66,58 +84,112 @@ func HandleCreateProgram(c *gin.Context, db *gorm.DB) {
 }
 
 func HandleUpdateProgram(c *gin.Context, db *gorm.DB) {
+	updateProgram(c, db, bindJSON)
+}
+
+// HandlePatchProgram - частичное обновление программы (JSON merge patch)
+func HandlePatchProgram(c *gin.Context, db *gorm.DB) {
+	updateProgram(c, db, bindMergePatch)
+}
+
+func updateProgram(c *gin.Context, db *gorm.DB, bind requestBinder) {
 	profileID := c.Param("id")
 	programID := c.Param("programId")
 
 	var program models.TrainingProgram
 	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).First(&program).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+		respondError(c, http.StatusNotFound, "Program not found")
+		return
+	}
+	if !checkIfMatch(c, program.Version, program) {
 		return
 	}
 
 	var req models.TrainingProgramRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bind(c, programRequest(program), &req); err != nil {
+		respondInvalid(c, err)
 		return
 	}
 
 	if req.StartDate != "" {
-		if date, err := time.Parse("2006-01-02", req.StartDate); err == nil {
-			program.StartDate = date
+		date, err := parseDate("startDate", req.StartDate)
+		if err != nil {
+			respondInvalid(c, err)
+			return
 		}
+		program.StartDate = date
 	}
 	if req.EndDate != "" {
-		if date, err := time.Parse("2006-01-02", req.EndDate); err == nil {
-			program.EndDate = date
+		date, err := parseDate("endDate", req.EndDate)
+		if err != nil {
+			respondInvalid(c, err)
+			return
 		}
+		program.EndDate = date
 	}
 
-	if req.IsActive && !program.IsActive {
-		db.Model(&models.TrainingProgram{}).Where("profile_id = ? AND id != ?", profileID, programID).Update("is_active", false)
+	if err := checkDateOrder(program.StartDate, program.EndDate); err != nil {
+		respondInvalid(c, err)
+		return
 	}
 
+	activate := req.IsActive && !program.IsActive
 	program.Name = req.Name
 	program.Description = req.Description
 	program.IsActive = req.IsActive
 	program.UpdatedAt = time.Now()
 
-	if err := db.Save(&program).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	program.Version++
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if activate {
+			if err := deactivatePrograms(tx, program.ProfileID, program.ID); err != nil {
+				return err
+			}
+		}
+		return saveVersioned(tx, &program, program.Version-1)
+	})
+	if err != nil {
+		writeSaveError(c, db, &program, err)
 		return
 	}
 
+	c.Header("ETag", versionETag(program.Version))
 	c.JSON(http.StatusOK, program)
 }
 
+// programRequest - текущее состояние программы в виде запроса на обновление
+func programRequest(program models.TrainingProgram) models.TrainingProgramRequest {
+	return models.TrainingProgramRequest{
+		Name:        program.Name,
+		Description: program.Description,
+		StartDate:   program.StartDate.Format("2006-01-02"),
+		EndDate:     program.EndDate.Format("2006-01-02"),
+		IsActive:    program.IsActive,
+	}
+}
+
+// deactivatePrograms снимает активность с остальных программ профиля, чтобы активной
+// осталась одна; версии меняются, чтобы устаревшие правки получили 412
+func deactivatePrograms(db *gorm.DB, profileID, exceptID uint) error {
+	return db.Model(&models.TrainingProgram{}).Where("profile_id = ? AND id != ? AND is_active = ?", profileID, exceptID, true).
+		Updates(map[string]interface{}{"is_active": false, "version": gorm.Expr("version + 1")}).Error
+}
+
 func HandleDeleteProgram(c *gin.Context, db *gorm.DB) {
 	profileID := c.Param("id")
 	programID := c.Param("programId")
 
-	db.Where("program_id = ?", programID).Delete(&models.ProgramExercise{})
-	db.Where("program_id = ?", programID).Delete(&models.ProgramSession{})
+	var program models.TrainingProgram
+	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).First(&program).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Program not found")
+		return
+	}
 
-	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).Delete(&models.TrainingProgram{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return moveToTrash(tx, "program", program.ID)
+	})
+	if err != nil {
+		respondInternal(c, err)
 		return
 	}
 
@@ -130,13 +202,13 @@ func HandleGetProgramExercises(c *gin.Context, db *gorm.DB) {
 
 	var program models.TrainingProgram
 	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).First(&program).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+		respondError(c, http.StatusNotFound, "Program not found")
 		return
 	}
 
 	var exercises []models.ProgramExercise
 	if err := db.Where("program_id = ?", programID).Order("day_of_week ASC, \"order\" ASC").Find(&exercises).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -149,16 +221,25 @@ func HandleCreateProgramExercise(c *gin.Context, db *gorm.DB) {
 
 	var program models.TrainingProgram
 	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).First(&program).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+		respondError(c, http.StatusNotFound, "Program not found")
 		return
 	}
 
 	var req models.ProgramExerciseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
 		return
 	}
 
+	if req.RoundToPlates {
+		weight, err := roundProgramWeight(db, program.ProfileID, req.Exercise, req.Weight)
+		if err != nil {
+			respondInternal(c, err)
+			return
+		}
+		req.Weight = weight
+	}
+
 	pid, _ := strconv.ParseUint(programID, 10, 64)
 	exercise := models.ProgramExercise{
 		ProgramID: uint(pid),
@@ -169,10 +250,12 @@ func HandleCreateProgramExercise(c *gin.Context, db *gorm.DB) {
 		Reps:      req.Reps,
 		Weight:    req.Weight,
 		Notes:     req.Notes,
+		GroupID:   req.GroupID,
+		GroupType: req.GroupType,
 	}
 
 	if err := db.Create(&exercise).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -186,22 +269,34 @@ func HandleUpdateProgramExercise(c *gin.Context, db *gorm.DB) {
 
 	var program models.TrainingProgram
 	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).First(&program).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+		respondError(c, http.StatusNotFound, "Program not found")
 		return
 	}
 
 	var exercise models.ProgramExercise
 	if err := db.Where("id = ? AND program_id = ?", exerciseID, programID).First(&exercise).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+		respondError(c, http.StatusNotFound, "Exercise not found")
+		return
+	}
+	if !checkIfMatch(c, exercise.Version, exercise) {
 		return
 	}
 
 	var req models.ProgramExerciseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
 		return
 	}
 
+	if req.RoundToPlates {
+		weight, err := roundProgramWeight(db, program.ProfileID, req.Exercise, req.Weight)
+		if err != nil {
+			respondInternal(c, err)
+			return
+		}
+		req.Weight = weight
+	}
+
 	exercise.Exercise = req.Exercise
 	exercise.DayOfWeek = req.DayOfWeek
 	exercise.Order = req.Order
@@ -209,13 +304,17 @@ func HandleUpdateProgramExercise(c *gin.Context, db *gorm.DB) {
 	exercise.Reps = req.Reps
 	exercise.Weight = req.Weight
 	exercise.Notes = req.Notes
+	exercise.GroupID = req.GroupID
+	exercise.GroupType = req.GroupType
 	exercise.UpdatedAt = time.Now()
 
-	if err := db.Save(&exercise).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	exercise.Version++
+	if err := saveVersioned(db, &exercise, exercise.Version-1); err != nil {
+		writeSaveError(c, db, &exercise, err)
 		return
 	}
 
+	c.Header("ETag", versionETag(exercise.Version))
 	c.JSON(http.StatusOK, exercise)
 }
 
@@ -226,18 +325,83 @@ func HandleDeleteProgramExercise(c *gin.Context, db *gorm.DB) {
 
 	var program models.TrainingProgram
 	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).First(&program).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+		respondError(c, http.StatusNotFound, "Program not found")
 		return
 	}
 
-	if err := db.Where("id = ? AND program_id = ?", exerciseID, programID).Delete(&models.ProgramExercise{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	result := db.Where("id = ? AND program_id = ?", exerciseID, programID).Delete(&models.ProgramExercise{})
+	if result.Error != nil {
+		respondInternal(c, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "Exercise not found")
 		return
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
+// HandleReorderProgramExercises - порядок и группировка упражнений программы внутри дня
+func HandleReorderProgramExercises(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	programID := c.Param("programId")
+
+	var program models.TrainingProgram
+	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).First(&program).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Program not found")
+		return
+	}
+
+	var req models.ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range req.Items {
+			query := tx.Model(&models.ProgramExercise{}).Where("id = ? AND program_id = ?", item.ID, program.ID)
+			if item.Version > 0 {
+				query = query.Where("version = ?", item.Version)
+			}
+			result := query.Updates(map[string]interface{}{
+				"order":      item.Order,
+				"group_id":   item.GroupID,
+				"group_type": item.GroupType,
+				"version":    gorm.Expr("version + 1"),
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return reorderMiss(tx.Model(&models.ProgramExercise{}).Where("id = ? AND program_id = ?", item.ID, program.ID))
+			}
+		}
+		return nil
+	})
+	if err == errItemNotFound {
+		respondError(c, http.StatusBadRequest, "Exercise does not belong to this program")
+		return
+	}
+	if err != nil && err != errStaleVersion {
+		respondInternal(c, err)
+		return
+	}
+
+	var exercises []models.ProgramExercise
+	if err := db.Where("program_id = ?", program.ID).Order("day_of_week ASC, \"order\" ASC").Find(&exercises).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+	if err == errStaleVersion {
+		respondStale(c, exercises)
+		return
+	}
+
+	c.JSON(http.StatusOK, exercises)
+}
+
 func HandleGetProgramSessions(c *gin.Context, db *gorm.DB) {
 	profileID := c.Param("id")
 	programID := c.Param("programId")
@@ -246,7 +410,7 @@ func HandleGetProgramSessions(c *gin.Context, db *gorm.DB) {
 
 	var program models.TrainingProgram
 	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).First(&program).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+		respondError(c, http.StatusNotFound, "Program not found")
 		return
 	}
 
@@ -263,7 +427,7 @@ func HandleGetProgramSessions(c *gin.Context, db *gorm.DB) {
 
 	var sessions []models.ProgramSession
 	if err := query.Order("date ASC").Find(&sessions).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -276,22 +440,22 @@ func HandleCreateProgramSession(c *gin.Context, db *gorm.DB) {
 
 	var program models.TrainingProgram
 	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).First(&program).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+		respondError(c, http.StatusNotFound, "Program not found")
 		return
 	}
 
 	var req models.ProgramSessionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
 		return
 	}
 
 	var date time.Time
 	var err error
 	if req.Date != "" {
-		date, err = time.Parse("2006-01-02", req.Date)
+		date, err = parseDate("date", req.Date)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			respondInvalid(c, err)
 			return
 		}
 	} else {
@@ -307,7 +471,7 @@ func HandleCreateProgramSession(c *gin.Context, db *gorm.DB) {
 	}
 
 	if err := db.Create(&session).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -321,26 +485,29 @@ func HandleUpdateProgramSession(c *gin.Context, db *gorm.DB) {
 
 	var program models.TrainingProgram
 	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).First(&program).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+		respondError(c, http.StatusNotFound, "Program not found")
 		return
 	}
 
 	var session models.ProgramSession
 	if err := db.Where("id = ? AND program_id = ?", sessionID, programID).First(&session).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		respondError(c, http.StatusNotFound, "Session not found")
+		return
+	}
+	if !checkIfMatch(c, session.Version, session) {
 		return
 	}
 
 	var req models.ProgramSessionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
 		return
 	}
 
 	if req.Date != "" {
-		date, err := time.Parse("2006-01-02", req.Date)
+		date, err := parseDate("date", req.Date)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			respondInvalid(c, err)
 			return
 		}
 		session.Date = date
@@ -350,11 +517,13 @@ func HandleUpdateProgramSession(c *gin.Context, db *gorm.DB) {
 	session.Notes = req.Notes
 	session.UpdatedAt = time.Now()
 
-	if err := db.Save(&session).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	session.Version++
+	if err := saveVersioned(db, &session, session.Version-1); err != nil {
+		writeSaveError(c, db, &session, err)
 		return
 	}
 
+	c.Header("ETag", versionETag(session.Version))
 	c.JSON(http.StatusOK, session)
 }
 
@@ -365,12 +534,17 @@ func HandleDeleteProgramSession(c *gin.Context, db *gorm.DB) {
 
 	var program models.TrainingProgram
 	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).First(&program).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+		respondError(c, http.StatusNotFound, "Program not found")
 		return
 	}
 
-	if err := db.Where("id = ? AND program_id = ?", sessionID, programID).Delete(&models.ProgramSession{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	result := db.Where("id = ? AND program_id = ?", sessionID, programID).Delete(&models.ProgramSession{})
+	if result.Error != nil {
+		respondInternal(c, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "Program session not found")
 		return
 	}
 
@@ -385,23 +559,53 @@ func HandleGetProgramPlanDays(c *gin.Context, db *gorm.DB) {
 
 	var program models.TrainingProgram
 	if err := db.Where("id = ? AND profile_id = ?", programID, profileID).First(&program).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Program not found"})
+		respondError(c, http.StatusNotFound, "Program not found")
 		return
 	}
 
 	y, yErr := strconv.Atoi(year)
 	m, mErr := strconv.Atoi(month)
 	if yErr != nil || mErr != nil || m < 1 || m > 12 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year or month"})
+		respondError(c, http.StatusBadRequest, "Invalid year or month")
 		return
 	}
 
 	var exercises []models.ProgramExercise
 	if err := db.Where("program_id = ?", programID).Order("day_of_week ASC, \"order\" ASC").Find(&exercises).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
+	// План под оборудование зала: веса округляются, недоступные упражнения отмечаются
+	gym, err := findGym(db, program.ProfileID, c.Query("gymId"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Gym not found")
+			return
+		}
+		respondInternal(c, err)
+		return
+	}
+	unavailable := make(map[string]bool)
+	if gym != nil {
+		var profile models.Profile
+		if err := db.First(&profile, program.ProfileID).Error; err != nil {
+			respondInternal(c, err)
+			return
+		}
+		plateRounder := profileRounder(profile, gym)
+		for i, ex := range exercises {
+			catalog := findExercise(db, ex.Exercise)
+			if !gym.CanPerform(catalog) {
+				unavailable[ex.Exercise] = true
+				continue
+			}
+			if ex.Weight > 0 {
+				exercises[i].Weight = exerciseRounder(gym, catalog, plateRounder)(ex.Weight)
+			}
+		}
+	}
+
 	dowToExercises := make(map[int][]models.ProgramExercise)
 	for _, ex := range exercises {
 		dowToExercises[ex.DayOfWeek] = append(dowToExercises[ex.DayOfWeek], ex)
@@ -430,11 +634,38 @@ func HandleGetProgramPlanDays(c *gin.Context, db *gorm.DB) {
 		if len(dayExercises) == 0 {
 			continue
 		}
+		var missing []string
+		for _, ex := range dayExercises {
+			if unavailable[ex.Exercise] {
+				missing = append(missing, ex.Exercise)
+			}
+		}
 		result = append(result, models.PlanDay{
-			Date:      d.Format("2006-01-02"),
-			Exercises: dayExercises,
+			Date:        d.Format("2006-01-02"),
+			Exercises:   dayExercises,
+			Groups:      groupProgramExercises(dayExercises),
+			Unavailable: missing,
 		})
 	}
 
 	c.JSON(http.StatusOK, result)
 }
+
+// groupProgramExercises объединяет подряд идущие упражнения с одинаковым GroupID.
+// Упражнения без группы становятся отдельными элементами.
+func groupProgramExercises(exercises []models.ProgramExercise) []models.ExerciseGroup {
+	groups := []models.ExerciseGroup{}
+	for _, ex := range exercises {
+		last := len(groups) - 1
+		if ex.GroupID != "" && last >= 0 && groups[last].GroupID == ex.GroupID {
+			groups[last].Exercises = append(groups[last].Exercises, ex)
+			continue
+		}
+		groups = append(groups, models.ExerciseGroup{
+			GroupID:   ex.GroupID,
+			GroupType: ex.GroupType,
+			Exercises: []models.ProgramExercise{ex},
+		})
+	}
+	return groups
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"sort"
+	"time"
 
 	"training-tracker/backend/internal/models"
 
@@ -17,13 +18,18 @@ func HandleGetProgressCharts(c *gin.Context, db *gorm.DB) {
 	period := c.DefaultQuery("period", "all")
 	exercises := c.QueryArray("exercises")
 
+	if chartType == models.MetricDuration || chartType == models.MetricDistance {
+		handleSessionProgressCharts(c, db, profileID, chartType, period, exercises)
+		return
+	}
+
 	var trainings []models.Training
 	query := db.Where("profile_id = ?", profileID)
 	if len(exercises) > 0 {
 		query = query.Where("exercise IN ?", exercises)
 	}
 	if err := query.Find(&trainings).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -117,3 +123,79 @@ func calculateIntensityForWeek(training models.Training, week int) float64 {
 	avgWeight := totalVolume / totalReps
 	return (avgWeight / maxWeight) * 100
 }
+
+// handleSessionProgressCharts строит графики времени и дистанции по журналу тренировок.
+// Точка графика - неделя (дата понедельника), значение - лучший подход за неделю.
+func handleSessionProgressCharts(c *gin.Context, db *gorm.DB, profileID, chartType, period string, exercises []string) {
+	sessions, err := loadSessionsWithExercises(db, profileID)
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	filter := make(map[string]bool)
+	for _, ex := range exercises {
+		filter[ex] = true
+	}
+
+	weekData := make(map[string]map[string]float64)
+	exerciseSet := make(map[string]bool)
+	for _, s := range sessions {
+		week := weekStart(s.Date).Format("2006-01-02")
+		for _, ex := range s.Exercises {
+			if len(filter) > 0 && !filter[ex.Exercise] {
+				continue
+			}
+			for _, set := range ex.Sets {
+				if set.IsWarmup() {
+					continue
+				}
+				value := float64(set.Duration)
+				if chartType == models.MetricDistance {
+					value = set.Distance
+				}
+				if value <= 0 {
+					continue
+				}
+				if weekData[week] == nil {
+					weekData[week] = map[string]float64{}
+				}
+				exerciseSet[ex.Exercise] = true
+				if value > weekData[week][ex.Exercise] {
+					weekData[week][ex.Exercise] = value
+				}
+			}
+		}
+	}
+
+	allExercises := make([]string, 0, len(exerciseSet))
+	for ex := range exerciseSet {
+		allExercises = append(allExercises, ex)
+	}
+	sort.Strings(allExercises)
+
+	weeks := make([]string, 0, len(weekData))
+	for week := range weekData {
+		weeks = append(weeks, week)
+	}
+	sort.Strings(weeks)
+
+	chartData := make([]models.ChartDataPoint, 0, len(weeks))
+	for _, week := range weeks {
+		point := models.ChartDataPoint{Week: week, ExerciseData: map[string]float64{}}
+		for _, ex := range allExercises {
+			point.ExerciseData[ex] = weekData[week][ex]
+		}
+		chartData = append(chartData, point)
+	}
+
+	resp := models.ProgressChartsResponse{ChartData: chartData, Exercises: allExercises, Period: period, ChartType: chartType}
+	c.JSON(http.StatusOK, resp)
+}
+
+// weekStart - понедельник недели, к которой относится дата
+func weekStart(date time.Time) time.Time {
+	offset := (int(date.Weekday()) + 6) % 7
+	y, m, d := date.AddDate(0, 0, -offset).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, date.Location())
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/progression"
+	"training-tracker/backend/internal/recommendations"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HandleGetProgression - застой и спад расчетного 1ПМ по упражнениям, накопленная усталость
+// и рекомендации: разгрузка, смена диапазона повторений, замена упражнения.
+// Параметры: sessions - сколько тренировок без прироста считать застоем (по умолчанию 4),
+// gymId - зал, в котором подбираются замены (по умолчанию выбранный).
+func HandleGetProgression(c *gin.Context, db *gorm.DB) {
+	var profile models.Profile
+	if err := db.First(&profile, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Profile not found")
+		return
+	}
+
+	stallSessions := progression.DefaultStallSessions
+	if value := c.Query("sessions"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < progression.MinStallSessions || n > progression.MaxStallSessions {
+			respondInvalid(c, invalidField("sessions", "range", "must be between "+
+				strconv.Itoa(progression.MinStallSessions)+" and "+strconv.Itoa(progression.MaxStallSessions)))
+			return
+		}
+		stallSessions = n
+	}
+
+	gym, err := findGym(db, profile.ID, c.Query("gymId"))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			respondError(c, http.StatusNotFound, "Gym not found")
+			return
+		}
+		respondInternal(c, err)
+		return
+	}
+
+	var exercises []models.Exercise
+	if err := db.Find(&exercises).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+	exerciseMap := make(map[string]models.Exercise)
+	for _, ex := range exercises {
+		exerciseMap[ex.Name] = ex
+	}
+	sessions, err := loadSessionsWithExercises(db, c.Param("id"))
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+	var bodyWeights []models.BodyWeight
+	if err := db.Where("profile_id = ?", profile.ID).Find(&bodyWeights).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+	sets := collectSessionSets(sessions, exerciseMap, newBodyWeightLookup(profile, bodyWeights))
+
+	now := time.Now()
+	load := calculateTrainingLoad(sessions, sets, "", now, now)
+	response := analyzeProgression(sets, exerciseMap, gym, stallSessions, load.Current)
+	response.Recommendations = recommendations.Evaluate(recommendations.Input{Progression: &response},
+		recommendationOptions(c, profile, recommendations.CategoryProgression))
+	c.JSON(http.StatusOK, response)
+}
+
+// analyzeProgression готовит ряды упражнений и нагрузку на сегодня для progression.Analyze
+func analyzeProgression(sets []sessionSet, exerciseMap map[string]models.Exercise, gym *models.Gym, stallSessions int, load *models.TrainingLoadDay) models.ProgressionResponse {
+	input := progression.Input{
+		Exercises:     progressionExercises(sets, exerciseMap, gym),
+		Now:           time.Now(),
+		StallSessions: stallSessions,
+	}
+	if load != nil {
+		input.ACWR = load.ACWR
+		input.Monotony = load.Monotony
+	}
+	return progression.Analyze(input)
+}
+
+// progressionExercises - ряд тренировок каждого упражнения с лучшим расчетным 1ПМ
+func progressionExercises(sets []sessionSet, exerciseMap map[string]models.Exercise, gym *models.Gym) []progression.Exercise {
+	type sessionTotals struct {
+		session        progression.Session
+		sets, rpeCount int
+		reps, rpe      float64
+	}
+	var order []sessionExerciseKey
+	totals := make(map[sessionExerciseKey]*sessionTotals)
+	for _, s := range sets {
+		key := sessionExerciseKey{s.SessionID, s.Exercise}
+		t := totals[key]
+		if t == nil {
+			t = &sessionTotals{session: progression.Session{Date: s.Date}}
+			totals[key] = t
+			order = append(order, key)
+		}
+		if e1rm := defaultOneRMOptions.estimate(s); e1rm > t.session.OneRM {
+			t.session.OneRM = round(e1rm)
+		}
+		t.session.Volume += float64(s.Set.Reps) * s.Load
+		t.sets++
+		t.reps += float64(s.Set.Reps)
+		if s.Set.RPE > 0 {
+			t.rpe += s.Set.RPE
+			t.rpeCount++
+		}
+	}
+
+	byExercise := make(map[string]*progression.Exercise)
+	var names []string
+	for _, key := range order {
+		t := totals[key]
+		if t.session.OneRM <= 0 {
+			continue
+		}
+		t.session.Reps = t.reps / float64(t.sets)
+		if t.rpeCount > 0 {
+			t.session.RPE = t.rpe / float64(t.rpeCount)
+		}
+		ex := byExercise[key.exercise]
+		if ex == nil {
+			ex = &progression.Exercise{Name: key.exercise, Alternatives: exerciseAlternatives(exerciseMap, key.exercise, gym)}
+			byExercise[key.exercise] = ex
+			names = append(names, key.exercise)
+		}
+		ex.Sessions = append(ex.Sessions, t.session)
+	}
+
+	result := make([]progression.Exercise, 0, len(names))
+	for _, name := range names {
+		ex := byExercise[name]
+		sort.SliceStable(ex.Sessions, func(i, j int) bool { return ex.Sessions[i].Date.Before(ex.Sessions[j].Date) })
+		result = append(result, *ex)
+	}
+	return result
+}
+
+// exerciseAlternatives - упражнения каталога на ту же группу мышц и того же типа
+// (базовое/изолирующее), которые можно выполнить в зале
+func exerciseAlternatives(exerciseMap map[string]models.Exercise, name string, gym *models.Gym) []string {
+	info, ok := exerciseMap[name]
+	if !ok || info.MuscleGroup == "" {
+		return nil
+	}
+	var alternatives []string
+	for _, ex := range exerciseMap {
+		if ex.Name == name || ex.MuscleGroup != info.MuscleGroup || ex.Category != info.Category {
+			continue
+		}
+		if gym != nil && !gym.CanPerform(ex) {
+			continue
+		}
+		alternatives = append(alternatives, ex.Name)
+	}
+	sort.Strings(alternatives)
+	return alternatives
+}
@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/recommendations"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Readiness handlers
+
+var errCheckInExists = errors.New("readiness check-in for this date already exists")
+
+func HandleGetReadinessCheckIns(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+
+	query := db.Where("profile_id = ?", profileID)
+	for _, param := range []struct{ name, op string }{{"dateFrom", ">="}, {"dateTo", "<="}} {
+		if value := c.Query(param.name); value != "" {
+			date, err := parseDate(param.name, value)
+			if err != nil {
+				respondInvalid(c, err)
+				return
+			}
+			query = query.Where("date "+param.op+" ?", date)
+		}
+	}
+
+	var checkIns []models.ReadinessCheckIn
+	if err := query.Order("date DESC").Find(&checkIns).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checkIns)
+}
+
+// HandleAddReadinessCheckIn - оценка готовности за день. На одну дату у профиля
+// может быть только одна оценка, исправляется она через PUT/PATCH.
+func HandleAddReadinessCheckIn(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	pid, err := strconv.ParseUint(profileID, 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid profile ID")
+		return
+	}
+
+	var req models.ReadinessCheckInRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	checkIn := models.ReadinessCheckIn{ProfileID: uint(pid), Date: calendarDay(time.Now())}
+	if err := applyReadinessRequest(&checkIn, req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		if err := tx.Model(&models.ReadinessCheckIn{}).Where("profile_id = ? AND date = ?", pid, checkIn.Date).Count(&existing).Error; err != nil {
+			return err
+		}
+		if existing > 0 {
+			return errCheckInExists
+		}
+		return tx.Create(&checkIn).Error
+	})
+	if err != nil {
+		// Уникальный частичный индекс защищает от двух одновременных запросов
+		if errors.Is(err, errCheckInExists) || errors.Is(err, gorm.ErrDuplicatedKey) {
+			respondError(c, http.StatusConflict, errCheckInExists.Error())
+			return
+		}
+		respondInternal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, checkIn)
+}
+
+func HandleUpdateReadinessCheckIn(c *gin.Context, db *gorm.DB) {
+	updateReadinessCheckIn(c, db, bindJSON)
+}
+
+// HandlePatchReadinessCheckIn - частичное обновление оценки готовности (JSON merge patch)
+func HandlePatchReadinessCheckIn(c *gin.Context, db *gorm.DB) {
+	updateReadinessCheckIn(c, db, bindMergePatch)
+}
+
+func updateReadinessCheckIn(c *gin.Context, db *gorm.DB, bind requestBinder) {
+	profileID := c.Param("id")
+	checkInID := c.Param("checkInId")
+
+	var checkIn models.ReadinessCheckIn
+	if err := db.Where("id = ? AND profile_id = ?", checkInID, profileID).First(&checkIn).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Readiness check-in not found")
+		return
+	}
+	if !checkIfMatch(c, checkIn.Version, checkIn) {
+		return
+	}
+
+	var req models.ReadinessCheckInRequest
+	if err := bind(c, readinessRequest(checkIn), &req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+	if err := applyReadinessRequest(&checkIn, req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+	checkIn.UpdatedAt = time.Now()
+
+	checkIn.Version++
+	if err := saveVersioned(db, &checkIn, checkIn.Version-1); err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			respondError(c, http.StatusConflict, errCheckInExists.Error())
+			return
+		}
+		writeSaveError(c, db, &checkIn, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(checkIn.Version))
+	c.JSON(http.StatusOK, checkIn)
+}
+
+func HandleDeleteReadinessCheckIn(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	checkInID := c.Param("checkInId")
+
+	result := db.Where("id = ? AND profile_id = ?", checkInID, profileID).Delete(&models.ReadinessCheckIn{})
+	if result.Error != nil {
+		respondInternal(c, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "Readiness check-in not found")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func applyReadinessRequest(checkIn *models.ReadinessCheckIn, req models.ReadinessCheckInRequest) error {
+	if req.Date != "" {
+		date, err := parseDate("date", req.Date)
+		if err != nil {
+			return err
+		}
+		checkIn.Date = date
+	}
+	checkIn.SleepHours = req.SleepHours
+	checkIn.SleepQuality = req.SleepQuality
+	checkIn.Stress = req.Stress
+	checkIn.HRV = req.HRV
+	checkIn.RestingHeartRate = req.RestingHeartRate
+	checkIn.Notes = req.Notes
+	return nil
+}
+
+// readinessRequest - текущее состояние оценки в виде запроса на обновление
+func readinessRequest(checkIn models.ReadinessCheckIn) models.ReadinessCheckInRequest {
+	return models.ReadinessCheckInRequest{
+		Date:             checkIn.Date.Format(dateLayout),
+		SleepHours:       checkIn.SleepHours,
+		SleepQuality:     checkIn.SleepQuality,
+		Stress:           checkIn.Stress,
+		HRV:              checkIn.HRV,
+		RestingHeartRate: checkIn.RestingHeartRate,
+		Notes:            checkIn.Notes,
+	}
+}
+
+// Показатели результата тренировки для корреляции
+const (
+	readinessMetricE1RM   = "e1rmChange" // средний прирост расчетного 1ПМ к прошлой тренировке упражнения, %
+	readinessMetricVolume = "volume"     // объем тренировки, вес × повторения
+)
+
+// minCorrelationSamples - меньше тренировок с показателем - коэффициент не считается
+const minCorrelationSamples = 5
+
+// readinessFactor - показатель готовности: название и значение на тренировку (0 - не указан). Energy, Mood и Soreness оцениваются в самой тренировке,
+// остальное берется из оценки готовности за тот же день.
+type readinessFactor struct {
+	name  string
+	value func(session models.TrainingSession, checkIn models.ReadinessCheckIn) float64
+}
+
+var readinessFactors = []readinessFactor{
+	{"sleepHours", func(_ models.TrainingSession, r models.ReadinessCheckIn) float64 { return r.SleepHours }},
+	{"sleepQuality", func(_ models.TrainingSession, r models.ReadinessCheckIn) float64 { return float64(r.SleepQuality) }},
+	{"stress", func(_ models.TrainingSession, r models.ReadinessCheckIn) float64 { return float64(r.Stress) }},
+	{"hrv", func(_ models.TrainingSession, r models.ReadinessCheckIn) float64 { return r.HRV }},
+	{"restingHeartRate", func(_ models.TrainingSession, r models.ReadinessCheckIn) float64 { return float64(r.RestingHeartRate) }},
+	{"energy", func(s models.TrainingSession, _ models.ReadinessCheckIn) float64 { return float64(s.Energy) }},
+	{"mood", func(s models.TrainingSession, _ models.ReadinessCheckIn) float64 { return float64(s.Mood) }},
+	{"soreness", func(s models.TrainingSession, _ models.ReadinessCheckIn) float64 { return float64(s.Soreness) }},
+}
+
+// HandleGetReadinessAnalysis - корреляция показателей готовности с результатом тренировок:
+// приростом расчетного 1ПМ и объемом. Параметры: dateFrom, dateTo - какие тренировки сравнивать.
+func HandleGetReadinessAnalysis(c *gin.Context, db *gorm.DB) {
+	var profile models.Profile
+	if err := db.First(&profile, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Profile not found")
+		return
+	}
+
+	var dateFrom, dateTo string
+	for _, param := range []struct {
+		name string
+		dst  *string
+	}{{"dateFrom", &dateFrom}, {"dateTo", &dateTo}} {
+		if value := c.Query(param.name); value != "" {
+			if _, err := parseDate(param.name, value); err != nil {
+				respondInvalid(c, err)
+				return
+			}
+			*param.dst = value
+		}
+	}
+
+	var exercises []models.Exercise
+	if err := db.Find(&exercises).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+	exerciseMap := make(map[string]models.Exercise)
+	for _, ex := range exercises {
+		exerciseMap[ex.Name] = ex
+	}
+	sessions, err := loadSessionsWithExercises(db, c.Param("id"))
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+	var bodyWeights []models.BodyWeight
+	if err := db.Where("profile_id = ?", profile.ID).Find(&bodyWeights).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+	var checkIns []models.ReadinessCheckIn
+	if err := db.Where("profile_id = ?", profile.ID).Order("date ASC, id ASC").Find(&checkIns).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+	sets := collectSessionSets(sessions, exerciseMap, newBodyWeightLookup(profile, bodyWeights))
+
+	response := analyzeReadiness(sessions, sets, checkIns, dateFrom, dateTo)
+	response.Insights = recommendations.Evaluate(recommendations.Input{Readiness: &response},
+		recommendationOptions(c, profile, recommendations.CategoryReadiness))
+	c.JSON(http.StatusOK, response)
+}
+
+// sessionOutcome - результат тренировки; e1rmChange nil, если ни одно упражнение
+// не с чем сравнить
+type sessionOutcome struct {
+	session    models.TrainingSession
+	e1rmChange *float64
+	volume     float64
+}
+
+// sessionOutcomes считает результат каждой силовой тренировки. Прирост 1ПМ сравнивается
+// с прошлой тренировкой упражнения, даже если она раньше dateFrom.
+func sessionOutcomes(sessions []models.TrainingSessionWithExercises, sets []sessionSet) []sessionOutcome {
+	setsBySession := make(map[uint][]sessionSet)
+	for _, s := range sets {
+		setsBySession[s.SessionID] = append(setsBySession[s.SessionID], s)
+	}
+
+	previous := make(map[string]float64) // лучший 1ПМ упражнения на прошлой тренировке
+	var outcomes []sessionOutcome
+	for _, s := range sessions {
+		best := make(map[string]float64)
+		outcome := sessionOutcome{session: s.TrainingSession}
+		for _, set := range setsBySession[s.ID] {
+			outcome.volume += float64(set.Set.Reps) * set.Load
+			if e1rm := defaultOneRMOptions.estimate(set); e1rm > best[set.Exercise] {
+				best[set.Exercise] = e1rm
+			}
+		}
+		var change float64
+		var compared int
+		for exercise, e1rm := range best {
+			if prev := previous[exercise]; prev > 0 {
+				change += (e1rm/prev - 1) * 100
+				compared++
+			}
+			previous[exercise] = e1rm
+		}
+		if compared > 0 {
+			value := round(change / float64(compared))
+			outcome.e1rmChange = &value
+		}
+		if outcome.volume > 0 || outcome.e1rmChange != nil {
+			outcomes = append(outcomes, outcome)
+		}
+	}
+	return outcomes
+}
+
+func analyzeReadiness(sessions []models.TrainingSessionWithExercises, sets []sessionSet, checkIns []models.ReadinessCheckIn, dateFrom, dateTo string) models.ReadinessAnalysisResponse {
+	// Если за день несколько оценок, берется последняя
+	byDay := make(map[string]models.ReadinessCheckIn)
+	for _, r := range checkIns {
+		byDay[r.Date.Format(dateLayout)] = r
+	}
+
+	var outcomes []sessionOutcome
+	for _, o := range sessionOutcomes(sessions, sets) {
+		day := o.session.Date.Format(dateLayout)
+		if (dateFrom == "" || day >= dateFrom) && (dateTo == "" || day <= dateTo) {
+			outcomes = append(outcomes, o)
+		}
+	}
+
+	response := models.ReadinessAnalysisResponse{
+		Sessions: len(outcomes),
+		Factors:  make([]models.ReadinessFactor, 0, len(readinessFactors)),
+	}
+	strongest := make(map[string]float64)
+	for _, f := range readinessFactors {
+		var values, e1rmValues, e1rmChanges, volumeValues, volumes []float64
+		for _, o := range outcomes {
+			value := f.value(o.session, byDay[o.session.Date.Format(dateLayout)])
+			if value == 0 {
+				continue
+			}
+			values = append(values, value)
+			if o.e1rmChange != nil {
+				e1rmValues = append(e1rmValues, value)
+				e1rmChanges = append(e1rmChanges, *o.e1rmChange)
+			}
+			if o.volume > 0 {
+				volumeValues = append(volumeValues, value)
+				volumes = append(volumes, o.volume)
+			}
+		}
+
+		factor := models.ReadinessFactor{
+			Factor: f.name,
+			Correlations: []models.ReadinessCorrelation{
+				readinessCorrelation(readinessMetricE1RM, e1rmValues, e1rmChanges),
+				readinessCorrelation(readinessMetricVolume, volumeValues, volumes),
+			},
+		}
+		if len(values) > 0 {
+			factor.Average = round(sum(values) / float64(len(values)))
+		}
+		for _, corr := range factor.Correlations {
+			if corr.Coefficient == nil {
+				continue
+			}
+			strongest[f.name] = math.Max(strongest[f.name], math.Abs(*corr.Coefficient))
+		}
+		response.Factors = append(response.Factors, factor)
+	}
+
+	sort.SliceStable(response.Factors, func(i, j int) bool {
+		return strongest[response.Factors[i].Factor] > strongest[response.Factors[j].Factor]
+	})
+	return response
+}
+
+func readinessCorrelation(metric string, x, y []float64) models.ReadinessCorrelation {
+	corr := models.ReadinessCorrelation{Metric: metric, Samples: len(x), Strength: "none"}
+	if len(x) < minCorrelationSamples {
+		return corr
+	}
+	r, ok := pearson(x, y)
+	if !ok {
+		return corr
+	}
+	r = round(r)
+	corr.Coefficient = &r
+	switch abs := math.Abs(r); {
+	case abs >= 0.5:
+		corr.Strength = "strong"
+	case abs >= 0.3:
+		corr.Strength = "moderate"
+	case abs >= 0.1:
+		corr.Strength = "weak"
+	}
+	return corr
+}
+
+// pearson - коэффициент корреляции Пирсона; false, если один из рядов постоянный
+func pearson(x, y []float64) (float64, bool) {
+	n := float64(len(x))
+	meanX, meanY := sum(x)/n, sum(y)/n
+	var cov, varX, varY float64
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0, false
+	}
+	return cov / math.Sqrt(varX*varY), true
+}
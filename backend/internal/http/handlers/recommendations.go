@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/recommendations"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// HandleGetRecommendationRules - правила рекомендаций и включены ли они в профиле
+func HandleGetRecommendationRules(c *gin.Context, db *gorm.DB) {
+	var profile models.Profile
+	if err := db.First(&profile, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Profile not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, recommendationRules(profile))
+}
+
+// HandleSetRecommendationRule включает или отключает правило рекомендаций в профиле
+func HandleSetRecommendationRule(c *gin.Context, db *gorm.DB) {
+	var profile models.Profile
+	if err := db.First(&profile, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Profile not found")
+		return
+	}
+	ruleID := c.Param("ruleId")
+	if _, ok := recommendations.Find(ruleID); !ok {
+		respondError(c, http.StatusNotFound, "Recommendation rule not found")
+		return
+	}
+	if !checkIfMatch(c, profile.Version, profile) {
+		return
+	}
+
+	var req models.RecommendationRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	disabled := make([]string, 0, len(profile.DisabledRecommendations)+1)
+	for _, id := range profile.DisabledRecommendations {
+		if id != ruleID {
+			disabled = append(disabled, id)
+		}
+	}
+	if !*req.Enabled {
+		disabled = append(disabled, ruleID)
+	}
+	profile.DisabledRecommendations = disabled
+	profile.UpdatedAt = time.Now()
+
+	profile.Version++
+	if err := saveVersioned(db, &profile, profile.Version-1); err != nil {
+		writeSaveError(c, db, &profile, err)
+		return
+	}
+
+	c.Header("ETag", versionETag(profile.Version))
+	c.JSON(http.StatusOK, recommendationRules(profile))
+}
+
+func recommendationRules(profile models.Profile) []models.RecommendationRule {
+	disabled := make(map[string]bool, len(profile.DisabledRecommendations))
+	for _, id := range profile.DisabledRecommendations {
+		disabled[id] = true
+	}
+	rules := recommendations.Rules()
+	result := make([]models.RecommendationRule, len(rules))
+	for i, rule := range rules {
+		result[i] = models.RecommendationRule{
+			ID:       rule.ID,
+			Severity: rule.Severity,
+			Category: rule.Category,
+			Enabled:  !disabled[rule.ID],
+		}
+	}
+	return result
+}
+
+// validateDisabledRecommendations проверяет, что в профиле отключаются только известные правила
+func validateDisabledRecommendations(ids []string) error {
+	for _, id := range ids {
+		if _, ok := recommendations.Find(id); !ok {
+			return invalidField("disabledRecommendations", "oneof", "unknown recommendation rule: "+id)
+		}
+	}
+	return nil
+}
+
+// recommendationOptions - правила профиля и язык сообщений запроса; categories
+// ограничивает проверку нужными категориями
+func recommendationOptions(c *gin.Context, profile models.Profile, categories ...string) recommendations.Options {
+	return recommendations.Options{
+		Locale:     requestLocale(c),
+		Disabled:   profile.DisabledRecommendations,
+		Categories: categories,
+	}
+}
+
+// requestLocale - язык из параметра lang или первого подходящего языка Accept-Language
+func requestLocale(c *gin.Context) string {
+	if lang := c.Query("lang"); recommendations.SupportedLocale(lang) {
+		return lang
+	}
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		tag := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		lang := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+		if recommendations.SupportedLocale(lang) {
+			return lang
+		}
+	}
+	return recommendations.DefaultLocale
+}
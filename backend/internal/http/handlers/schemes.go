@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"training-tracker/backend/internal/models"
+)
+
+// Схемы подходов калькулятора 1ПМ
+const (
+	SchemeStraight   = "straight"    // одинаковые подходы на заданном проценте
+	SchemeFiveByFive = "5x5"         // 5 подходов по 5 повторений
+	Scheme531Week1   = "531_week1"   // 5/3/1: 65/75/85% тренировочного максимума, 5/5/5+
+	Scheme531Week2   = "531_week2"   // 5/3/1: 70/80/90%, 3/3/3+
+	Scheme531Week3   = "531_week3"   // 5/3/1: 75/85/95%, 5/3/1+
+	SchemePyramid    = "pyramid"     // вес растет к заданному проценту, повторения падают
+	SchemeTopBackoff = "top_backoff" // верхний подход и подходы со сбросом веса
+)
+
+const (
+	defaultPlateIncrement = 2.5
+	defaultWarmupSets     = 3
+	// trainingMaxRatio - тренировочный максимум 5/3/1 от 1ПМ
+	trainingMaxRatio = 0.9
+	// backoffDrop - сброс веса в подходах после верхнего
+	backoffDrop = 0.10
+	// pyramidSpan - на сколько процентов 1ПМ легче первый подход пирамиды
+	pyramidSpan = 20.0
+	// targetRIR - запас повторений в рабочих подходах
+	targetRIR = 2
+)
+
+// wendlerWeeks - проценты тренировочного максимума и повторения недель 5/3/1
+var wendlerWeeks = map[string][]struct {
+	percentage float64
+	reps       int
+}{
+	Scheme531Week1: {{65, 5}, {75, 5}, {85, 5}},
+	Scheme531Week2: {{70, 3}, {80, 3}, {90, 3}},
+	Scheme531Week3: {{75, 5}, {85, 3}, {95, 1}},
+}
+
+// schemeOptions - параметры построения схемы
+type schemeOptions struct {
+	Scheme     string
+	OneRM      float64
+	Percentage float64
+	Formula    string
+	SetCount   int // 0 - по умолчанию для схемы
+	WarmupSets int
+	Increment  float64
+	// Round округляет вес до собираемого; по умолчанию - до шага Increment
+	Round func(float64) float64
+}
+
+// newSchemeOptions заполняет параметры схемы из запроса калькулятора.
+// Без схемы сохраняется прежний ответ: шесть одинаковых подходов без разминки.
+// rounder - округление по набору дисков профиля, явный increment в запросе важнее.
+func newSchemeOptions(req models.OneRMRequest, oneRM float64, rounder func(float64) float64) schemeOptions {
+	opts := schemeOptions{
+		Scheme:     req.Scheme,
+		OneRM:      oneRM,
+		Percentage: req.Percentage,
+		Formula:    req.Formula,
+		SetCount:   req.SetCount,
+		Increment:  req.Increment,
+	}
+	if opts.Scheme == "" {
+		opts.Scheme = SchemeStraight
+	}
+	switch {
+	case opts.Increment == 0 && rounder != nil:
+		opts.Round = rounder
+	case opts.Increment == 0:
+		opts.Increment = defaultPlateIncrement
+	}
+	if opts.Round == nil {
+		increment := opts.Increment
+		opts.Round = func(weight float64) float64 { return roundToIncrement(weight, increment) }
+	}
+	switch {
+	case req.WarmupSets != nil:
+		opts.WarmupSets = *req.WarmupSets
+	case opts.Scheme != SchemeStraight:
+		opts.WarmupSets = defaultWarmupSets
+	}
+	return opts
+}
+
+// buildScheme строит разминку и рабочие подходы схемы
+func buildScheme(opts schemeOptions) []models.SetValues {
+	working := workingSets(opts)
+	if len(working) == 0 {
+		return working
+	}
+	return append(warmupSets(working[0].Weight, opts), working...)
+}
+
+func workingSets(opts schemeOptions) []models.SetValues {
+	count := func(def int) int {
+		if opts.SetCount > 0 {
+			return opts.SetCount
+		}
+		return def
+	}
+
+	switch opts.Scheme {
+	case SchemeFiveByFive:
+		return repeatSet(opts.set(opts.Percentage, 5, models.SetTypeWorking), count(5))
+
+	case Scheme531Week1, Scheme531Week2, Scheme531Week3:
+		week := wendlerWeeks[opts.Scheme]
+		sets := make([]models.SetValues, 0, len(week))
+		for i, s := range week {
+			setType := models.SetTypeWorking
+			if i == len(week)-1 {
+				setType = models.SetTypeAMRAP
+			}
+			sets = append(sets, opts.set(s.percentage*trainingMaxRatio, s.reps, setType))
+		}
+		return sets
+
+	case SchemePyramid:
+		n := count(5)
+		sets := make([]models.SetValues, 0, n)
+		for i := 0; i < n; i++ {
+			percentage := opts.Percentage
+			if n > 1 {
+				percentage -= pyramidSpan * float64(n-1-i) / float64(n-1)
+			}
+			sets = append(sets, opts.set(percentage, calculateTargetReps(percentage, opts.Formula), models.SetTypeWorking))
+		}
+		return sets
+
+	case SchemeTopBackoff:
+		top := opts.set(opts.Percentage, calculateTargetReps(opts.Percentage, opts.Formula), models.SetTypeWorking)
+		backoff := opts.Percentage * (1 - backoffDrop)
+		return append([]models.SetValues{top},
+			repeatSet(opts.set(backoff, calculateTargetReps(backoff, opts.Formula), models.SetTypeWorking), count(3))...)
+
+	default:
+		return repeatSet(opts.set(opts.Percentage, calculateTargetReps(opts.Percentage, opts.Formula), models.SetTypeWorking), count(6))
+	}
+}
+
+// warmupSets - разгон к первому рабочему весу: от 40% до 80% веса, повторения от 5 до 2.
+// Подходы, которые после округления не легче рабочего или совпали с предыдущим
+// (например, пустой гриф), пропускаются.
+func warmupSets(workingWeight float64, opts schemeOptions) []models.SetValues {
+	n := opts.WarmupSets
+	sets := make([]models.SetValues, 0, n)
+	for i := 0; i < n; i++ {
+		ratio, reps := 0.6, 3
+		if n > 1 {
+			step := float64(i) / float64(n-1)
+			ratio = 0.4 + 0.4*step
+			reps = 5 - int(math.Round(3*step))
+		}
+		weight := opts.Round(workingWeight * ratio)
+		if weight <= 0 || weight >= workingWeight || (len(sets) > 0 && weight == sets[len(sets)-1].Weight) {
+			continue
+		}
+		sets = append(sets, models.SetValues{
+			Reps:       reps,
+			Weight:     weight,
+			Type:       models.SetTypeWarmup,
+			Percentage: round(weight / opts.OneRM * 100),
+		})
+	}
+	return sets
+}
+
+// set - подход на проценте 1ПМ с округлением веса до шага дисков
+func (o schemeOptions) set(percentage float64, reps int, setType string) models.SetValues {
+	return models.SetValues{
+		Reps:       reps,
+		Weight:     o.Round(o.OneRM * percentage / 100),
+		Type:       setType,
+		Percentage: round(percentage),
+	}
+}
+
+func repeatSet(set models.SetValues, n int) []models.SetValues {
+	sets := make([]models.SetValues, n)
+	for i := range sets {
+		sets[i] = set
+	}
+	return sets
+}
+
+// roundToIncrement округляет вес до ближайшего шага дисков
+func roundToIncrement(weight, increment float64) float64 {
+	if increment <= 0 {
+		return round(weight)
+	}
+	return round(math.Round(weight/increment) * increment)
+}
+
+// calculateTargetReps - сколько повторений делать на проценте 1ПМ: максимум повторений
+// по формуле минус запас targetRIR, но не меньше одного
+func calculateTargetReps(percentage float64, formula string) int {
+	if percentage <= 0 {
+		return 1
+	}
+	maxReps := 1
+	for reps := 2; reps <= maxRepsCap; reps++ {
+		// 1ПМ, рассчитанный по весу на проценте, не должен превышать 100%
+		if calculate1RM(percentage, float64(reps), formula) > 100+1e-9 {
+			break
+		}
+		maxReps = reps
+	}
+	if maxReps-targetRIR < 1 {
+		return 1
+	}
+	return maxReps - targetRIR
+}
+
+// schemeSets переводит схему в подходы журнала
+func schemeSets(sets []models.SetValues) []models.Set {
+	result := make([]models.Set, len(sets))
+	for i, s := range sets {
+		result[i] = models.Set{Weight: s.Weight, Reps: s.Reps, Type: s.Type}
+	}
+	return result
+}
+
+// schemeSummary - схема одной строкой для заметок упражнения программы, например
+// "531_week1: 60x5, 67.5x5, 77.5x5+"
+func schemeSummary(scheme string, sets []models.SetValues) string {
+	parts := make([]string, 0, len(sets))
+	for _, s := range sets {
+		if s.Type == models.SetTypeWarmup {
+			continue
+		}
+		part := fmt.Sprintf("%gx%d", s.Weight, s.Reps)
+		if s.Type == models.SetTypeAMRAP {
+			part += "+"
+		}
+		parts = append(parts, part)
+	}
+	return scheme + ": " + strings.Join(parts, ", ")
+}
@@ -1,14 +1,17 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
 
 	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/realtime"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Training history
@@ -18,6 +21,7 @@ func HandleGetTrainingHistory(c *gin.Context, db *gorm.DB) {
 	pageSize := c.DefaultQuery("pageSize", "20")
 	dateFrom := c.Query("dateFrom")
 	dateTo := c.Query("dateTo")
+	sessionType := c.Query("type")
 
 	pageInt, _ := strconv.Atoi(page)
 	pageSizeInt, _ := strconv.Atoi(pageSize)
@@ -33,14 +37,24 @@ func HandleGetTrainingHistory(c *gin.Context, db *gorm.DB) {
 	query := db.Where("profile_id = ?", profileID)
 
 	if dateFrom != "" {
-		if date, err := time.Parse("2006-01-02", dateFrom); err == nil {
-			query = query.Where("date >= ?", date)
+		date, err := parseDate("dateFrom", dateFrom)
+		if err != nil {
+			respondInvalid(c, err)
+			return
 		}
+		query = query.Where("date >= ?", date)
 	}
 	if dateTo != "" {
-		if date, err := time.Parse("2006-01-02", dateTo); err == nil {
-			query = query.Where("date <= ?", date)
+		date, err := parseDate("dateTo", dateTo)
+		if err != nil {
+			respondInvalid(c, err)
+			return
 		}
+		query = query.Where("date <= ?", date)
+	}
+
+	if sessionType != "" {
+		query = query.Where("type = ?", sessionType)
 	}
 
 	var totalCount int64
@@ -48,18 +62,22 @@ func HandleGetTrainingHistory(c *gin.Context, db *gorm.DB) {
 
 	var sessions []models.TrainingSession
 	if err := query.Order("date DESC").Offset(offset).Limit(pageSizeInt).Find(&sessions).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
 	var sessionsWithExercises []models.TrainingSessionWithExercises
 	for _, session := range sessions {
 		var exercises []models.TrainingSessionExercise
-		db.Where("training_session_id = ?", session.ID).Find(&exercises)
+		db.Where("training_session_id = ?", session.ID).Order("\"order\" ASC, id ASC").Find(&exercises)
+
+		var activities []models.CardioActivity
+		db.Where("training_session_id = ?", session.ID).Order("id ASC").Find(&activities)
 
 		sessionsWithExercises = append(sessionsWithExercises, models.TrainingSessionWithExercises{
 			TrainingSession: session,
 			Exercises:       exercises,
+			Activities:      activities,
 		})
 	}
 
@@ -80,45 +98,58 @@ func HandleCreateTrainingSession(c *gin.Context, db *gorm.DB) {
 
 	var req models.TrainingSessionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
 		return
 	}
 
 	var date time.Time
 	var err error
 	if req.Date != "" {
-		date, err = time.Parse("2006-01-02", req.Date)
+		date, err = parseDate("date", req.Date)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			respondInvalid(c, err)
 			return
 		}
 	} else {
 		date = time.Now()
 	}
 
-	if req.Energy < 1 || req.Energy > 10 {
+	// Диапазон 1-10 проверяет binding, здесь только значения по умолчанию для пропущенных шкал
+	if req.Energy == 0 {
 		req.Energy = 5
 	}
-	if req.Mood < 1 || req.Mood > 10 {
+	if req.Mood == 0 {
 		req.Mood = 5
 	}
-	if req.Soreness < 1 || req.Soreness > 10 {
+	if req.Soreness == 0 {
 		req.Soreness = 1
 	}
 
+	if req.Type == "" {
+		req.Type = models.SessionTypeStrength
+	}
+
 	pid, _ := strconv.ParseUint(profileID, 10, 64)
 	session := models.TrainingSession{
 		ProfileID: uint(pid),
+		Type:      req.Type,
 		Date:      date,
 		Duration:  req.Duration,
 		Notes:     req.Notes,
 		Energy:    req.Energy,
 		Mood:      req.Mood,
 		Soreness:  req.Soreness,
+		RPE:       req.RPE,
 	}
 
-	if err := db.Create(&session).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		return logChange(tx, sessionChange(session, models.SyncOpUpsert))
+	})
+	if err != nil {
+		respondInternal(c, err)
 		return
 	}
 
@@ -126,56 +157,118 @@ func HandleCreateTrainingSession(c *gin.Context, db *gorm.DB) {
 }
 
 func HandleUpdateTrainingSession(c *gin.Context, db *gorm.DB) {
+	updateTrainingSession(c, db, bindJSON)
+}
+
+// HandlePatchTrainingSession - частичное обновление тренировки (JSON merge patch)
+func HandlePatchTrainingSession(c *gin.Context, db *gorm.DB) {
+	updateTrainingSession(c, db, bindMergePatch)
+}
+
+func updateTrainingSession(c *gin.Context, db *gorm.DB, bind requestBinder) {
 	profileID := c.Param("id")
 	sessionID := c.Param("sessionId")
 
 	var session models.TrainingSession
 	if err := db.Where("id = ? AND profile_id = ?", sessionID, profileID).First(&session).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Training session not found"})
+		respondError(c, http.StatusNotFound, "Training session not found")
+		return
+	}
+	if !checkIfMatch(c, session.Version, session) {
 		return
 	}
 
+	current := sessionRequest(session)
 	var req models.TrainingSessionRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bind(c, current, &req); err != nil {
+		respondInvalid(c, err)
 		return
 	}
 
-	if req.Date != "" {
-		date, err := time.Parse("2006-01-02", req.Date)
+	// Дата в запросе без времени: если день не изменился, время начала тренировки сохраняется
+	if req.Date != "" && req.Date != current.Date {
+		date, err := parseDate("date", req.Date)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			respondInvalid(c, err)
 			return
 		}
 		session.Date = date
 	}
 
+	if req.Type != "" {
+		session.Type = req.Type
+	}
 	session.Duration = req.Duration
 	session.Notes = req.Notes
 	session.Energy = req.Energy
 	session.Mood = req.Mood
 	session.Soreness = req.Soreness
+	session.RPE = req.RPE
 	session.UpdatedAt = time.Now()
 
-	if err := db.Save(&session).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	session.Version++
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, &session, session.Version-1); err != nil {
+			return err
+		}
+		return logChange(tx, sessionChange(session, models.SyncOpUpsert))
+	})
+	if err != nil {
+		writeSaveError(c, db, &session, err)
 		return
 	}
 
+	sessionEvents.Publish(session.ID, realtime.EventSessionUpdated, session)
+	c.Header("ETag", versionETag(session.Version))
 	c.JSON(http.StatusOK, session)
 }
 
+// sessionRequest - текущее состояние тренировки в виде запроса на обновление
+func sessionRequest(session models.TrainingSession) models.TrainingSessionRequest {
+	return models.TrainingSessionRequest{
+		Type:     session.Type,
+		Date:     session.Date.Format("2006-01-02"),
+		Duration: session.Duration,
+		Notes:    session.Notes,
+		Energy:   session.Energy,
+		Mood:     session.Mood,
+		Soreness: session.Soreness,
+		RPE:      session.RPE,
+	}
+}
+
 func HandleDeleteTrainingSession(c *gin.Context, db *gorm.DB) {
 	profileID := c.Param("id")
 	sessionID := c.Param("sessionId")
 
-	db.Where("training_session_id = ?", sessionID).Delete(&models.TrainingSessionExercise{})
+	var session models.TrainingSession
+	if err := db.Where("id = ? AND profile_id = ?", sessionID, profileID).First(&session).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Training session not found")
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var exercises []models.TrainingSessionExercise
+		if err := tx.Where("training_session_id = ?", session.ID).Find(&exercises).Error; err != nil {
+			return err
+		}
+		if err := moveToTrash(tx, "session", session.ID); err != nil {
+			return err
+		}
 
-	if err := db.Where("id = ? AND profile_id = ?", sessionID, profileID).Delete(&models.TrainingSession{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		for _, e := range exercises {
+			if err := logChange(tx, exerciseChange(session.ProfileID, e, models.SyncOpDelete)); err != nil {
+				return err
+			}
+		}
+		return logChange(tx, sessionChange(session, models.SyncOpDelete))
+	})
+	if err != nil {
+		respondInternal(c, err)
 		return
 	}
 
+	sessionEvents.Publish(session.ID, realtime.EventSessionDeleted, gin.H{"id": session.ID})
 	c.Status(http.StatusNoContent)
 }
 
@@ -186,76 +279,150 @@ func HandleAddExerciseToSession(c *gin.Context, db *gorm.DB) {
 
 	var session models.TrainingSession
 	if err := db.Where("id = ? AND profile_id = ?", sessionID, profileID).First(&session).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Training session not found"})
+		respondError(c, http.StatusNotFound, "Training session not found")
 		return
 	}
 
-	var req struct {
-		Exercise string       `json:"exercise" binding:"required"`
-		Sets     []models.Set `json:"sets"`
-		Notes    string       `json:"notes"`
-	}
+	var req models.SessionExerciseRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
+		return
+	}
+
+	if err := validateSets(req.Sets, findExercise(db, req.Exercise)); err != nil {
+		respondInvalid(c, err)
 		return
 	}
 
-	sid, _ := strconv.ParseUint(sessionID, 10, 64)
 	exercise := models.TrainingSessionExercise{
-		TrainingSessionID: uint(sid),
+		TrainingSessionID: session.ID,
 		Exercise:          req.Exercise,
+		Order:             req.Order,
+		GroupID:           req.GroupID,
+		GroupType:         req.GroupType,
 		Sets:              req.Sets,
 		Notes:             req.Notes,
 	}
 
-	if err := db.Create(&exercise).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if exercise.Order == 0 {
+			if err := lockSession(tx, session.ProfileID, session.ID, &session); err != nil {
+				return err
+			}
+			order, err := nextSessionExerciseOrder(tx, session.ID)
+			if err != nil {
+				return err
+			}
+			exercise.Order = order
+		}
+		if err := tx.Create(&exercise).Error; err != nil {
+			return err
+		}
+		return logChange(tx, exerciseChange(session.ProfileID, exercise, models.SyncOpUpsert))
+	})
+	if err != nil {
+		respondInternal(c, err)
 		return
 	}
 
+	sessionEvents.Publish(session.ID, realtime.EventExerciseAdded, exercise)
 	c.JSON(http.StatusCreated, exercise)
 }
 
+// lockSession находит сессию профиля и блокирует ее строку до конца транзакции,
+// чтобы упражнения, добавленные одновременно, не получили один порядок
+func lockSession(tx *gorm.DB, profileID, sessionID uint, session *models.TrainingSession) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND profile_id = ?", sessionID, profileID).First(session).Error
+}
+
+// nextSessionExerciseOrder - порядок для нового упражнения в конце сессии
+func nextSessionExerciseOrder(db *gorm.DB, sessionID uint) (int, error) {
+	var maxOrder int
+	err := db.Model(&models.TrainingSessionExercise{}).Where("training_session_id = ?", sessionID).
+		Select("COALESCE(MAX(\"order\"), 0)").Scan(&maxOrder).Error
+	return maxOrder + 1, err
+}
+
 func HandleUpdateSessionExercise(c *gin.Context, db *gorm.DB) {
+	updateSessionExercise(c, db, bindJSON)
+}
+
+// HandlePatchSessionExercise - частичное обновление упражнения в тренировке (JSON merge patch)
+func HandlePatchSessionExercise(c *gin.Context, db *gorm.DB) {
+	updateSessionExercise(c, db, bindMergePatch)
+}
+
+func updateSessionExercise(c *gin.Context, db *gorm.DB, bind requestBinder) {
 	profileID := c.Param("id")
 	sessionID := c.Param("sessionId")
 	exerciseID := c.Param("exerciseId")
 
 	var session models.TrainingSession
 	if err := db.Where("id = ? AND profile_id = ?", sessionID, profileID).First(&session).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Training session not found"})
+		respondError(c, http.StatusNotFound, "Training session not found")
 		return
 	}
 
 	var exercise models.TrainingSessionExercise
 	if err := db.Where("id = ? AND training_session_id = ?", exerciseID, sessionID).First(&exercise).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Exercise not found"})
+		respondError(c, http.StatusNotFound, "Exercise not found")
+		return
+	}
+	if !checkIfMatch(c, exercise.Version, exercise) {
 		return
 	}
 
-	var req struct {
-		Exercise string       `json:"exercise"`
-		Sets     []models.Set `json:"sets"`
-		Notes    string       `json:"notes"`
+	var req models.SessionExerciseRequest
+	if err := bind(c, sessionExerciseRequest(exercise), &req); err != nil {
+		respondInvalid(c, err)
+		return
 	}
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+
+	if err := validateSets(req.Sets, findExercise(db, req.Exercise)); err != nil {
+		respondInvalid(c, err)
 		return
 	}
 
 	exercise.Exercise = req.Exercise
+	if req.Order > 0 {
+		exercise.Order = req.Order
+	}
+	exercise.GroupID = req.GroupID
+	exercise.GroupType = req.GroupType
 	exercise.Sets = req.Sets
 	exercise.Notes = req.Notes
 	exercise.UpdatedAt = time.Now()
 
-	if err := db.Save(&exercise).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	exercise.Version++
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := saveVersioned(tx, &exercise, exercise.Version-1); err != nil {
+			return err
+		}
+		return logChange(tx, exerciseChange(session.ProfileID, exercise, models.SyncOpUpsert))
+	})
+	if err != nil {
+		writeSaveError(c, db, &exercise, err)
 		return
 	}
 
+	sessionEvents.Publish(session.ID, realtime.EventExerciseUpdated, exercise)
+	c.Header("ETag", versionETag(exercise.Version))
 	c.JSON(http.StatusOK, exercise)
 }
 
+// sessionExerciseRequest - текущее состояние упражнения в виде запроса на обновление
+func sessionExerciseRequest(exercise models.TrainingSessionExercise) models.SessionExerciseRequest {
+	return models.SessionExerciseRequest{
+		Exercise:  exercise.Exercise,
+		Order:     exercise.Order,
+		GroupID:   exercise.GroupID,
+		GroupType: exercise.GroupType,
+		Sets:      exercise.Sets,
+		Notes:     exercise.Notes,
+	}
+}
+
 func HandleDeleteSessionExercise(c *gin.Context, db *gorm.DB) {
 	profileID := c.Param("id")
 	sessionID := c.Param("sessionId")
@@ -263,14 +430,112 @@ func HandleDeleteSessionExercise(c *gin.Context, db *gorm.DB) {
 
 	var session models.TrainingSession
 	if err := db.Where("id = ? AND profile_id = ?", sessionID, profileID).First(&session).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Training session not found"})
+		respondError(c, http.StatusNotFound, "Training session not found")
+		return
+	}
+
+	var exercise models.TrainingSessionExercise
+	if err := db.Where("id = ? AND training_session_id = ?", exerciseID, sessionID).First(&exercise).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Exercise not found")
 		return
 	}
 
-	if err := db.Where("id = ? AND training_session_id = ?", exerciseID, sessionID).Delete(&models.TrainingSessionExercise{}).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&exercise).Error; err != nil {
+			return err
+		}
+		return logChange(tx, exerciseChange(session.ProfileID, exercise, models.SyncOpDelete))
+	})
+	if err != nil {
+		respondInternal(c, err)
 		return
 	}
 
+	sessionEvents.Publish(session.ID, realtime.EventExerciseDeleted, gin.H{"id": exercise.ID})
 	c.Status(http.StatusNoContent)
 }
+
+// errItemNotFound - элемент запроса на сортировку не принадлежит родительской записи
+var errItemNotFound = errors.New("item not found")
+
+// reorderMiss объясняет, почему элемент сортировки не обновился:
+// запись есть, но с другой версией, или ее нет у родителя
+func reorderMiss(query *gorm.DB) error {
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errStaleVersion
+	}
+	return errItemNotFound
+}
+
+// HandleReorderSessionExercises - порядок и группировка упражнений в сессии (суперсеты, круги)
+func HandleReorderSessionExercises(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	sessionID := c.Param("sessionId")
+
+	var session models.TrainingSession
+	if err := db.Where("id = ? AND profile_id = ?", sessionID, profileID).First(&session).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Training session not found")
+		return
+	}
+
+	var req models.ReorderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	var exercises []models.TrainingSessionExercise
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, item := range req.Items {
+			query := tx.Model(&models.TrainingSessionExercise{}).Where("id = ? AND training_session_id = ?", item.ID, session.ID)
+			if item.Version > 0 {
+				query = query.Where("version = ?", item.Version)
+			}
+			result := query.Updates(map[string]interface{}{
+				"order":      item.Order,
+				"group_id":   item.GroupID,
+				"group_type": item.GroupType,
+				"version":    gorm.Expr("version + 1"),
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return reorderMiss(tx.Model(&models.TrainingSessionExercise{}).Where("id = ? AND training_session_id = ?", item.ID, session.ID))
+			}
+		}
+		if err := tx.Where("training_session_id = ?", session.ID).Order("\"order\" ASC, id ASC").Find(&exercises).Error; err != nil {
+			return err
+		}
+		for _, e := range exercises {
+			if err := logChange(tx, exerciseChange(session.ProfileID, e, models.SyncOpUpsert)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == errItemNotFound {
+		respondError(c, http.StatusBadRequest, "Exercise does not belong to this session")
+		return
+	}
+	if err != nil && err != errStaleVersion {
+		respondInternal(c, err)
+		return
+	}
+
+	if err == errStaleVersion {
+		if err := db.Where("training_session_id = ?", session.ID).Order("\"order\" ASC, id ASC").Find(&exercises).Error; err != nil {
+			respondInternal(c, err)
+			return
+		}
+		respondStale(c, exercises)
+		return
+	}
+
+	sessionEvents.Publish(session.ID, realtime.EventExercisesSorted, exercises)
+	c.JSON(http.StatusOK, exercises)
+}
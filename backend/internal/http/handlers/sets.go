@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+
+	"training-tracker/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// findExercise ищет упражнение в каталоге по названию.
+// Для упражнений вне каталога возвращается пустое описание с метриками по умолчанию.
+func findExercise(db *gorm.DB, name string) models.Exercise {
+	var exercise models.Exercise
+	if err := db.Where("name = ?", name).First(&exercise).Error; err != nil {
+		return models.Exercise{Name: name}
+	}
+	return exercise
+}
+
+var validSetTypes = map[string]bool{
+	"":                    true,
+	models.SetTypeWarmup:  true,
+	models.SetTypeWorking: true,
+	models.SetTypeDrop:    true,
+	models.SetTypeAMRAP:   true,
+	models.SetTypeCluster: true,
+	models.SetTypeFailure: true,
+}
+
+// tempoPattern - темп из 3-4 фаз, X означает взрывную фазу
+var tempoPattern = regexp.MustCompile(`^[0-9xX]{1,2}(-[0-9xX]{1,2}){2,3}$`)
+
+// validateSets проверяет, что подходы заполнены в соответствии с метриками упражнения
+func validateSets(sets []models.Set, exercise models.Exercise) error {
+	for i, set := range sets {
+		if err := validateSet(fmt.Sprintf("sets[%d]", i), set, exercise); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSet проверяет один подход; field - путь подхода в теле запроса для ошибки
+func validateSet(field string, set models.Set, exercise models.Exercise) error {
+	switch {
+	case set.Weight < 0:
+		return invalidField(field+".weight", "min", "must not be negative")
+	case set.Reps < 0:
+		return invalidField(field+".reps", "min", "must not be negative")
+	case set.Duration < 0:
+		return invalidField(field+".duration", "min", "must not be negative")
+	case set.Distance < 0:
+		return invalidField(field+".distance", "min", "must not be negative")
+	case set.Assistance < 0:
+		return invalidField(field+".assistance", "min", "must not be negative")
+	case set.HeartRate != 0 && (set.HeartRate < 30 || set.HeartRate > 250):
+		return invalidField(field+".heartRate", "range", "must be between 30 and 250")
+	case set.RPE != 0 && (set.RPE < 1 || set.RPE > 10 || math.Mod(set.RPE*2, 1) != 0):
+		return invalidField(field+".rpe", "range", "must be between 1 and 10 in steps of 0.5")
+	case set.RIR != nil && (*set.RIR < 0 || *set.RIR > 10):
+		return invalidField(field+".rir", "range", "must be between 0 and 10")
+	case set.Rest < 0:
+		return invalidField(field+".rest", "min", "must not be negative")
+	case !validSetTypes[set.Type]:
+		return invalidField(field+".type", "oneof", fmt.Sprintf("unknown set type %q", set.Type))
+	case set.Tempo != "" && !tempoPattern.MatchString(set.Tempo):
+		return invalidField(field+".tempo", "format", "must look like 3-1-1-0")
+	}
+
+	for _, metric := range exercise.TrackedMetrics() {
+		switch metric {
+		case models.MetricWeight:
+			if set.Weight <= 0 && !exercise.IsBodyweight {
+				return invalidField(field+".weight", "required", "is required for "+exercise.Name)
+			}
+		case models.MetricReps:
+			if set.Reps <= 0 {
+				return invalidField(field+".reps", "required", "is required for "+exercise.Name)
+			}
+		case models.MetricDuration:
+			if set.Duration <= 0 {
+				return invalidField(field+".duration", "required", "is required for "+exercise.Name)
+			}
+		case models.MetricDistance:
+			if set.Distance <= 0 {
+				return invalidField(field+".distance", "required", "is required for "+exercise.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// primaryMetric - метрика, по которой оценивается прогресс в упражнении
+func primaryMetric(exercise models.Exercise) string {
+	switch {
+	case exercise.Tracks(models.MetricWeight) || exercise.IsBodyweight:
+		return models.MetricWeight
+	case exercise.Tracks(models.MetricDistance):
+		return models.MetricDistance
+	case exercise.Tracks(models.MetricDuration):
+		return models.MetricDuration
+	default:
+		return models.MetricReps
+	}
+}
+
+// setPerformance - результат подхода по указанной метрике
+func setPerformance(s sessionSet, metric string) float64 {
+	switch metric {
+	case models.MetricDistance:
+		return s.Set.Distance
+	case models.MetricDuration:
+		return float64(s.Set.Duration)
+	case models.MetricReps:
+		return float64(s.Set.Reps)
+	default:
+		return s.Load
+	}
+}
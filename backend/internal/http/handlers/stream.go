@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// streamHeartbeat - интервал пустых сообщений, чтобы прокси не закрывали соединение
+const streamHeartbeat = 25 * time.Second
+
+// sessionEvents - изменения сессий для открытых SSE-подключений
+var sessionEvents = realtime.NewHub()
+
+// HandleStreamTrainingSession - Server-Sent Events по одной сессии.
+// Первым событием приходит snapshot с текущим состоянием, дальше - изменения.
+func HandleStreamTrainingSession(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	sessionID := c.Param("sessionId")
+
+	var session models.TrainingSession
+	if err := db.Where("id = ? AND profile_id = ?", sessionID, profileID).First(&session).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Training session not found")
+		return
+	}
+
+	// Подписываемся до чтения снимка, чтобы не потерять изменения между ними
+	events, unsubscribe := sessionEvents.Subscribe(session.ID)
+	defer unsubscribe()
+
+	var exercises []models.TrainingSessionExercise
+	db.Where("training_session_id = ?", session.ID).Order("\"order\" ASC, id ASC").Find(&exercises)
+	var activities []models.CardioActivity
+	db.Where("training_session_id = ?", session.ID).Order("id ASC").Find(&activities)
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	c.SSEvent(realtime.EventSnapshot, realtime.Event{
+		Type:      realtime.EventSnapshot,
+		SessionID: session.ID,
+		Data: models.TrainingSessionWithExercises{
+			TrainingSession: session,
+			Exercises:       exercises,
+			Activities:      activities,
+		},
+		At: time.Now(),
+	})
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case event, ok := <-events:
+			if !ok {
+				return false
+			}
+			c.SSEvent(event.Type, event)
+			return event.Type != realtime.EventSessionDeleted
+		case <-heartbeat.C:
+			c.SSEvent("ping", time.Now().Unix())
+			return true
+		}
+	})
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/strength"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Источники результатов для силовых очков
+const (
+	strengthSourcePR   = "pr"   // личные рекорды
+	strengthSourceE1RM = "e1rm" // текущий расчетный 1ПМ по журналу
+)
+
+// liftExercises - упражнения каталога, которые считаются соревновательными движениями
+var liftExercises = map[string][]string{
+	strength.LiftSquat:    {"Приседания со штангой"},
+	strength.LiftBench:    {"Жим штанги лежа"},
+	strength.LiftDeadlift: {"Становая тяга", "Становая тяга сумо"},
+}
+
+// liftResult - лучший 1ПМ в движении
+type liftResult struct {
+	exercise  string
+	oneRM     float64
+	estimated bool
+	date      time.Time
+}
+
+// HandleGetStrengthScores - очки Wilks, DOTS и IPF GL за сумму троеборья и уровни
+// по нормативам в каждом движении. Параметры: source (pr, e1rm), gender (если в профиле
+// не указан male/female), squat, bench, deadlift - свое упражнение вместо стандартного.
+func HandleGetStrengthScores(c *gin.Context, db *gorm.DB) {
+	var profile models.Profile
+	if err := db.First(&profile, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Profile not found")
+		return
+	}
+
+	gender := c.DefaultQuery("gender", profile.Gender)
+	if !strength.Supported(gender) {
+		respondError(c, http.StatusUnprocessableEntity, "gender must be male or female to compute strength scores")
+		return
+	}
+
+	source := c.DefaultQuery("source", strengthSourcePR)
+	if source != strengthSourcePR && source != strengthSourceE1RM {
+		respondInvalid(c, invalidField("source", "oneof", "must be one of: pr, e1rm"))
+		return
+	}
+
+	lifts := make(map[string][]string, len(liftExercises))
+	for lift, exercises := range liftExercises {
+		lifts[lift] = exercises
+		if exercise := c.Query(lift); exercise != "" {
+			lifts[lift] = []string{exercise}
+		}
+	}
+
+	var bodyWeights []models.BodyWeight
+	if err := db.Where("profile_id = ?", profile.ID).Find(&bodyWeights).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+	bw := newBodyWeightLookup(profile, bodyWeights)
+
+	var results map[string]liftResult
+	var err error
+	if source == strengthSourceE1RM {
+		results, err = liftsFromHistory(db, profile, lifts)
+	} else {
+		results, err = liftsFromRecords(db, profile.ID, lifts)
+	}
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	response := models.StrengthResponse{Gender: gender, Source: source, Lifts: []models.StrengthLift{}}
+	var total float64
+	var totalDate time.Time
+	for _, lift := range strength.Lifts {
+		result, ok := results[lift]
+		if !ok {
+			continue
+		}
+		bodyWeight := bw.nearest(result.date)
+		if bodyWeight <= 0 {
+			respondError(c, http.StatusUnprocessableEntity, "body weight is unknown: add a body weight entry or set the profile weight")
+			return
+		}
+
+		entry := models.StrengthLift{
+			Lift:       lift,
+			Exercise:   result.exercise,
+			OneRM:      round(result.oneRM),
+			Estimated:  result.estimated,
+			Date:       result.date.Format(dateLayout),
+			BodyWeight: bodyWeight,
+			Wilks:      strength.Wilks(result.oneRM, bodyWeight, gender),
+			DOTS:       strength.DOTS(result.oneRM, bodyWeight, gender),
+		}
+		if standing, ok := strength.Standard(lift, result.oneRM, bodyWeight, gender); ok {
+			entry.Ratio = standing.Ratio
+			entry.Level = standing.Level
+			entry.NextLevel = standing.NextLevel
+			entry.NextLevelWeight = standing.NextLevelWeight
+		}
+		if lift == strength.LiftBench {
+			entry.IPFPoints = strength.IPFGoodLiftBench(result.oneRM, bodyWeight, gender)
+		}
+		response.Lifts = append(response.Lifts, entry)
+
+		total += result.oneRM
+		if result.date.After(totalDate) {
+			totalDate = result.date
+		}
+	}
+
+	if len(response.Lifts) == len(strength.Lifts) {
+		bodyWeight := bw.nearest(totalDate)
+		response.Total = &models.StrengthTotal{
+			Total:      round(total),
+			Date:       totalDate.Format(dateLayout),
+			BodyWeight: bodyWeight,
+			Wilks:      strength.Wilks(total, bodyWeight, gender),
+			DOTS:       strength.DOTS(total, bodyWeight, gender),
+			IPFPoints:  strength.IPFGoodLift(total, bodyWeight, gender),
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// liftsFromRecords - лучший 1ПМ в каждом движении по личным рекордам. Рекорд на несколько
+// повторений пересчитывается в 1ПМ, рекорды с повторениями сверх предела не учитываются.
+func liftsFromRecords(db *gorm.DB, profileID uint, lifts map[string][]string) (map[string]liftResult, error) {
+	results := make(map[string]liftResult)
+	for lift, exercises := range lifts {
+		var records []models.PersonalRecord
+		if err := db.Where("profile_id = ? AND exercise IN ?", profileID, exercises).Find(&records).Error; err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			oneRM := record.Weight
+			if record.Reps > 1 {
+				oneRM = defaultOneRMOptions.estimate(sessionSet{Load: record.Weight, Set: models.Set{Reps: record.Reps}})
+			}
+			if oneRM > results[lift].oneRM {
+				results[lift] = liftResult{exercise: record.Exercise, oneRM: oneRM, estimated: record.Reps > 1, date: record.Date}
+			}
+		}
+	}
+	return results, nil
+}
+
+// liftsFromHistory - текущий расчетный 1ПМ в каждом движении по журналу тренировок
+func liftsFromHistory(db *gorm.DB, profile models.Profile, lifts map[string][]string) (map[string]liftResult, error) {
+	sets, err := loadProfileSets(db, profile)
+	if err != nil {
+		return nil, err
+	}
+	history := oneRMHistory(sets, defaultOneRMOptions)
+
+	results := make(map[string]liftResult)
+	for lift, exercises := range lifts {
+		for _, exercise := range exercises {
+			h, ok := history[exercise]
+			if !ok || h.Current <= results[lift].oneRM {
+				continue
+			}
+			date, err := time.Parse(dateLayout, h.CurrentDate)
+			if err != nil {
+				return nil, err
+			}
+			results[lift] = liftResult{exercise: exercise, oneRM: h.Current, estimated: true, date: date}
+		}
+	}
+	return results, nil
+}
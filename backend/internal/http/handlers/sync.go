@@ -0,0 +1,603 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Офлайн-синхронизация: клиент копит изменения без сети и отправляет их пачкой,
+// затем забирает ленту изменений с сервера начиная со своего курсора.
+
+const (
+	defaultSyncFeedLimit = 500
+	maxSyncFeedLimit     = 1000
+)
+
+// syncError - изменение не применено: конфликт версий или невалидные данные
+type syncError struct {
+	status  string
+	message string
+	current interface{}
+}
+
+func (e *syncError) Error() string { return e.message }
+
+func syncConflict(current interface{}) error {
+	return &syncError{status: models.SyncStatusConflict, message: "record was changed on the server", current: current}
+}
+
+func syncRejected(format string, args ...interface{}) error {
+	return &syncError{status: models.SyncStatusRejected, message: fmt.Sprintf(format, args...)}
+}
+
+// HandleSync - применение пачки офлайн-изменений.
+// Каждое изменение применяется в своей транзакции, конфликт одного не отменяет остальные.
+func HandleSync(c *gin.Context, db *gorm.DB) {
+	pid, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid profile ID")
+		return
+	}
+
+	var profile models.Profile
+	if err := db.First(&profile, pid).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Profile not found")
+		return
+	}
+
+	var req models.SyncRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	results := make([]models.SyncResult, 0, len(req.Changes))
+	for _, change := range req.Changes {
+		results = append(results, applySyncChange(db, profile.ID, change))
+	}
+
+	c.JSON(http.StatusOK, models.SyncResponse{
+		Results: results,
+		Cursor:  latestSyncCursor(db, profile.ID),
+	})
+}
+
+// HandleGetSyncChanges - лента изменений профиля после курсора.
+// Для каждой записи возвращается только последнее изменение с текущим состоянием.
+func HandleGetSyncChanges(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+
+	cursor, err := strconv.ParseUint(c.DefaultQuery("cursor", "0"), 10, 64)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSyncFeedLimit)))
+	if limit < 1 || limit > maxSyncFeedLimit {
+		limit = defaultSyncFeedLimit
+	}
+
+	var changes []models.SyncChange
+	if err := db.Where("profile_id = ? AND id > ?", profileID, cursor).Order("id ASC").Limit(limit + 1).Find(&changes).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	hasMore := len(changes) > limit
+	if hasMore {
+		changes = changes[:limit]
+	}
+
+	response := models.SyncFeedResponse{Changes: []models.SyncFeedItem{}, Cursor: uint(cursor), HasMore: hasMore}
+	if len(changes) == 0 {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+	response.Cursor = changes[len(changes)-1].ID
+
+	type entityKey struct {
+		entity string
+		id     uint
+	}
+	latest := make(map[entityKey]int)
+	ids := make(map[string][]uint)
+	for i, change := range changes {
+		key := entityKey{change.Entity, change.EntityID}
+		if _, seen := latest[key]; !seen {
+			ids[change.Entity] = append(ids[change.Entity], change.EntityID)
+		}
+		latest[key] = i
+	}
+
+	current := make(map[entityKey]interface{})
+	versions := make(map[entityKey]int)
+	var sessions []models.TrainingSession
+	if len(ids[models.SyncEntitySession]) > 0 {
+		db.Where("id IN ? AND profile_id = ?", ids[models.SyncEntitySession], profileID).Find(&sessions)
+	}
+	for _, s := range sessions {
+		key := entityKey{models.SyncEntitySession, s.ID}
+		current[key], versions[key] = s, s.Version
+	}
+	var exercises []models.TrainingSessionExercise
+	if len(ids[models.SyncEntitySessionExercise]) > 0 {
+		db.Where("id IN ?", ids[models.SyncEntitySessionExercise]).Find(&exercises)
+	}
+	for _, e := range exercises {
+		key := entityKey{models.SyncEntitySessionExercise, e.ID}
+		current[key], versions[key] = e, e.Version
+	}
+	var weights []models.BodyWeight
+	if len(ids[models.SyncEntityBodyWeight]) > 0 {
+		db.Where("id IN ? AND profile_id = ?", ids[models.SyncEntityBodyWeight], profileID).Find(&weights)
+	}
+	for _, w := range weights {
+		key := entityKey{models.SyncEntityBodyWeight, w.ID}
+		current[key], versions[key] = w, w.Version
+	}
+
+	for i, change := range changes {
+		key := entityKey{change.Entity, change.EntityID}
+		if latest[key] != i {
+			continue
+		}
+		item := models.SyncFeedItem{
+			Cursor:   change.ID,
+			Entity:   change.Entity,
+			ID:       change.EntityID,
+			ClientID: change.ClientID,
+			Op:       change.Op,
+			Version:  change.Version,
+		}
+		if data, ok := current[key]; ok && change.Op == models.SyncOpUpsert {
+			item.Data = data
+			item.Version = versions[key]
+		} else {
+			// Запись удалена позже, чем попала в журнал
+			item.Op = models.SyncOpDelete
+		}
+		response.Changes = append(response.Changes, item)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// applySyncChange применяет одно изменение и записывает его в журнал
+func applySyncChange(db *gorm.DB, profileID uint, req models.SyncChangeRequest) models.SyncResult {
+	result := models.SyncResult{ChangeID: req.ChangeID, Entity: req.Entity, ID: req.ID, ClientID: req.ClientID}
+
+	if prior, ok := findPriorChange(db, profileID, req.ChangeID); ok {
+		return duplicateResult(result, prior)
+	}
+	if req.ID == 0 && req.ClientID == "" {
+		result.Status = models.SyncStatusRejected
+		result.Error = "id or clientId is required"
+		return result
+	}
+
+	var publish func()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		var change *models.SyncChange
+		var err error
+		switch req.Entity {
+		case models.SyncEntitySession:
+			change, publish, err = syncSession(tx, profileID, req)
+		case models.SyncEntitySessionExercise:
+			change, publish, err = syncSessionExercise(tx, profileID, req)
+		case models.SyncEntityBodyWeight:
+			change, err = syncBodyWeight(tx, profileID, req)
+		}
+		if err != nil || change == nil {
+			return err
+		}
+		if req.ChangeID != "" {
+			changeID := req.ChangeID
+			change.ChangeID = &changeID
+		}
+		change.ProfileID = profileID
+		if err := tx.Create(change).Error; err != nil {
+			return err
+		}
+		result.ID = change.EntityID
+		result.Version = change.Version
+		return nil
+	})
+
+	var serr *syncError
+	switch {
+	case errors.As(err, &serr):
+		result.Status = serr.status
+		result.Error = serr.message
+		result.Current = serr.current
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		result = duplicateKeyResult(db, profileID, req, result)
+	case err != nil:
+		log.Printf("error: sync %s %s: %v", req.Entity, req.Op, err)
+		result.Status = models.SyncStatusRejected
+		result.Error = "internal error"
+	default:
+		result.Status = models.SyncStatusApplied
+		if publish != nil {
+			publish()
+		}
+	}
+	return result
+}
+
+// findPriorChange - изменение с тем же changeId, уже примененное в профиле
+func findPriorChange(db *gorm.DB, profileID uint, changeID string) (models.SyncChange, bool) {
+	var prior models.SyncChange
+	if changeID == "" {
+		return prior, false
+	}
+	err := db.Where("change_id = ? AND profile_id = ?", changeID, profileID).First(&prior).Error
+	return prior, err == nil
+}
+
+func duplicateResult(result models.SyncResult, prior models.SyncChange) models.SyncResult {
+	result.Status = models.SyncStatusDuplicate
+	result.ID = prior.EntityID
+	result.Version = prior.Version
+	return result
+}
+
+// duplicateKeyResult разбирает нарушение уникальности: тот же changeId, который
+// параллельный повтор успел записать первым, - дубликат; clientId записи из корзины
+// этого профиля - запись удалена на сервере; clientId уникален для всех профилей,
+// поэтому занятый в другом профиле отклоняется
+func duplicateKeyResult(db *gorm.DB, profileID uint, req models.SyncChangeRequest, result models.SyncResult) models.SyncResult {
+	if prior, ok := findPriorChange(db, profileID, req.ChangeID); ok {
+		return duplicateResult(result, prior)
+	}
+	owner, ok := clientIDOwner(db, req)
+	switch {
+	case ok && owner == profileID:
+		result.Status = models.SyncStatusConflict
+		result.Error = "record was deleted on the server"
+	case ok:
+		result.Status = models.SyncStatusRejected
+		result.Error = "clientId is already used by another record"
+	default:
+		result.Status = models.SyncStatusRejected
+		result.Error = "record conflicts with existing data"
+	}
+	return result
+}
+
+// clientIDOwner - профиль записи с clientId из запроса, включая записи в корзине
+func clientIDOwner(db *gorm.DB, req models.SyncChangeRequest) (uint, bool) {
+	if req.ClientID == "" {
+		return 0, false
+	}
+	db = db.Unscoped()
+	var query *gorm.DB
+	switch req.Entity {
+	case models.SyncEntitySession:
+		query = db.Model(&models.TrainingSession{}).Where("client_id = ?", req.ClientID)
+	case models.SyncEntitySessionExercise:
+		query = db.Model(&models.TrainingSession{}).Where("id = (?)",
+			db.Model(&models.TrainingSessionExercise{}).Select("training_session_id").Where("client_id = ?", req.ClientID))
+	case models.SyncEntityBodyWeight:
+		query = db.Model(&models.BodyWeight{}).Where("client_id = ?", req.ClientID)
+	default:
+		return 0, false
+	}
+	var owners []uint
+	if err := query.Pluck("profile_id", &owners).Error; err != nil || len(owners) == 0 {
+		return 0, false
+	}
+	return owners[0], true
+}
+
+func syncSession(tx *gorm.DB, profileID uint, req models.SyncChangeRequest) (*models.SyncChange, func(), error) {
+	var session models.TrainingSession
+	err := syncLookup(tx, req).Where("profile_id = ?", profileID).First(&session).Error
+	found := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+	if err := checkSyncVersion(found, session.Version, session, req); err != nil || (!found && req.Op == models.SyncOpDelete) {
+		return nil, nil, err
+	}
+
+	if req.Op == models.SyncOpDelete {
+		var exercises []models.TrainingSessionExercise
+		tx.Where("training_session_id = ?", session.ID).Find(&exercises)
+		if err := moveToTrash(tx, "session", session.ID); err != nil {
+			return nil, nil, err
+		}
+		for _, e := range exercises {
+			if err := logChange(tx, exerciseChange(profileID, e, models.SyncOpDelete)); err != nil {
+				return nil, nil, err
+			}
+		}
+		change := sessionChange(session, models.SyncOpDelete)
+		return &change, func() {
+			sessionEvents.Publish(session.ID, realtime.EventSessionDeleted, gin.H{"id": session.ID})
+		}, nil
+	}
+
+	var data models.TrainingSessionRequest
+	if err := decodeSyncData(req.Data, &data); err != nil {
+		return nil, nil, err
+	}
+	if data.Date != "" {
+		date, err := parseDate("date", data.Date)
+		if err != nil {
+			return nil, nil, syncRejected("%s", err)
+		}
+		session.Date = date
+	} else if !found {
+		session.Date = time.Now()
+	}
+	if data.Energy == 0 {
+		data.Energy = 5
+	}
+	if data.Mood == 0 {
+		data.Mood = 5
+	}
+	if data.Soreness == 0 {
+		data.Soreness = 1
+	}
+	if data.Type != "" {
+		session.Type = data.Type
+	} else if !found {
+		session.Type = models.SessionTypeStrength
+	}
+	session.Duration = data.Duration
+	session.Notes = data.Notes
+	session.Energy = data.Energy
+	session.Mood = data.Mood
+	session.Soreness = data.Soreness
+	session.RPE = data.RPE
+
+	if found {
+		session.Version++
+		session.UpdatedAt = time.Now()
+		err = tx.Save(&session).Error
+	} else {
+		session.ProfileID = profileID
+		session.ClientID = &req.ClientID
+		session.Version = 1
+		err = tx.Create(&session).Error
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	change := sessionChange(session, models.SyncOpUpsert)
+	return &change, func() {
+		sessionEvents.Publish(session.ID, realtime.EventSessionUpdated, session)
+	}, nil
+}
+
+func syncSessionExercise(tx *gorm.DB, profileID uint, req models.SyncChangeRequest) (*models.SyncChange, func(), error) {
+	var exercise models.TrainingSessionExercise
+	err := syncLookup(tx, req).
+		Where("training_session_id IN (?)", tx.Model(&models.TrainingSession{}).Select("id").Where("profile_id = ?", profileID)).
+		First(&exercise).Error
+	found := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+	if err := checkSyncVersion(found, exercise.Version, exercise, req); err != nil || (!found && req.Op == models.SyncOpDelete) {
+		return nil, nil, err
+	}
+
+	if req.Op == models.SyncOpDelete {
+		if err := tx.Delete(&exercise).Error; err != nil {
+			return nil, nil, err
+		}
+		change := exerciseChange(profileID, exercise, models.SyncOpDelete)
+		return &change, func() {
+			sessionEvents.Publish(exercise.TrainingSessionID, realtime.EventExerciseDeleted, gin.H{"id": exercise.ID})
+		}, nil
+	}
+
+	var data models.SyncSessionExerciseData
+	if err := decodeSyncData(req.Data, &data); err != nil {
+		return nil, nil, err
+	}
+	if err := validateSets(data.Sets, findExercise(tx, data.Exercise)); err != nil {
+		return nil, nil, syncRejected("%s", err.Error())
+	}
+
+	if !found {
+		if data.SessionID == 0 && data.SessionClientID == "" {
+			return nil, nil, syncRejected("sessionId or sessionClientId is required")
+		}
+		var session models.TrainingSession
+		query := tx.Where("profile_id = ?", profileID)
+		if data.SessionID != 0 {
+			query = query.Where("id = ?", data.SessionID)
+		} else {
+			query = query.Where("client_id = ?", data.SessionClientID)
+		}
+		if err := query.First(&session).Error; err != nil {
+			return nil, nil, syncRejected("training session not found")
+		}
+		exercise.TrainingSessionID = session.ID
+		exercise.ClientID = &req.ClientID
+	}
+
+	exercise.Exercise = data.Exercise
+	if data.Order > 0 {
+		exercise.Order = data.Order
+	} else if !found {
+		var maxOrder int
+		tx.Model(&models.TrainingSessionExercise{}).Where("training_session_id = ?", exercise.TrainingSessionID).
+			Select("COALESCE(MAX(\"order\"), 0)").Scan(&maxOrder)
+		exercise.Order = maxOrder + 1
+	}
+	exercise.GroupID = data.GroupID
+	exercise.GroupType = data.GroupType
+	exercise.Sets = data.Sets
+	exercise.Notes = data.Notes
+
+	event := realtime.EventExerciseUpdated
+	if found {
+		exercise.Version++
+		exercise.UpdatedAt = time.Now()
+		err = tx.Save(&exercise).Error
+	} else {
+		event = realtime.EventExerciseAdded
+		exercise.Version = 1
+		err = tx.Create(&exercise).Error
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	change := exerciseChange(profileID, exercise, models.SyncOpUpsert)
+	return &change, func() {
+		sessionEvents.Publish(exercise.TrainingSessionID, event, exercise)
+	}, nil
+}
+
+func syncBodyWeight(tx *gorm.DB, profileID uint, req models.SyncChangeRequest) (*models.SyncChange, error) {
+	var bodyWeight models.BodyWeight
+	err := syncLookup(tx, req).Where("profile_id = ?", profileID).First(&bodyWeight).Error
+	found := err == nil
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if err := checkSyncVersion(found, bodyWeight.Version, bodyWeight, req); err != nil || (!found && req.Op == models.SyncOpDelete) {
+		return nil, err
+	}
+
+	if req.Op == models.SyncOpDelete {
+		if err := tx.Delete(&bodyWeight).Error; err != nil {
+			return nil, err
+		}
+		change := bodyWeightChange(bodyWeight, models.SyncOpDelete)
+		return &change, nil
+	}
+
+	var data models.BodyWeightRequest
+	if err := decodeSyncData(req.Data, &data); err != nil {
+		return nil, err
+	}
+	if data.Date != "" {
+		date, err := parseDate("date", data.Date)
+		if err != nil {
+			return nil, syncRejected("%s", err)
+		}
+		bodyWeight.Date = date
+	} else if !found {
+		bodyWeight.Date = time.Now()
+	}
+	bodyWeight.Weight = data.Weight
+	bodyWeight.Notes = data.Notes
+
+	if found {
+		bodyWeight.Version++
+		bodyWeight.UpdatedAt = time.Now()
+		err = tx.Save(&bodyWeight).Error
+	} else {
+		bodyWeight.ProfileID = profileID
+		bodyWeight.ClientID = &req.ClientID
+		bodyWeight.Version = 1
+		err = tx.Create(&bodyWeight).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	change := bodyWeightChange(bodyWeight, models.SyncOpUpsert)
+	return &change, nil
+}
+
+// syncLookup ищет запись по серверному id или по clientId и блокирует ее до конца транзакции
+func syncLookup(tx *gorm.DB, req models.SyncChangeRequest) *gorm.DB {
+	query := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+	if req.ID != 0 {
+		return query.Where("id = ?", req.ID)
+	}
+	return query.Where("client_id = ?", req.ClientID)
+}
+
+// checkSyncVersion сравнивает версию клиента с серверной.
+// Удаление уже удаленной записи считается примененным.
+func checkSyncVersion(found bool, version int, current interface{}, req models.SyncChangeRequest) error {
+	switch {
+	case found && req.BaseVersion != version:
+		return syncConflict(current)
+	case !found && req.Op == models.SyncOpUpsert && (req.BaseVersion > 0 || req.ClientID == ""):
+		// Клиент редактировал запись, которую на сервере уже удалили
+		return syncConflict(nil)
+	}
+	return nil
+}
+
+func decodeSyncData(data json.RawMessage, dst interface{}) error {
+	if len(data) == 0 {
+		return syncRejected("data is required")
+	}
+	if err := json.Unmarshal(data, dst); err != nil {
+		return syncRejected("invalid data: %v", err)
+	}
+	if err := binding.Validator.ValidateStruct(dst); err != nil {
+		if fields := fieldErrors(err); len(fields) > 0 {
+			return syncRejected("%s", validationDetail(fields))
+		}
+		return syncRejected("%s", err.Error())
+	}
+	return nil
+}
+
+func latestSyncCursor(db *gorm.DB, profileID uint) uint {
+	var cursor uint
+	db.Model(&models.SyncChange{}).Where("profile_id = ?", profileID).Select("COALESCE(MAX(id), 0)").Scan(&cursor)
+	return cursor
+}
+
+// logChange добавляет запись в журнал изменений
+func logChange(db *gorm.DB, change models.SyncChange) error {
+	return db.Create(&change).Error
+}
+
+func sessionChange(session models.TrainingSession, op string) models.SyncChange {
+	return models.SyncChange{
+		ProfileID: session.ProfileID,
+		Entity:    models.SyncEntitySession,
+		EntityID:  session.ID,
+		ClientID:  session.ClientID,
+		Op:        op,
+		Version:   session.Version,
+	}
+}
+
+func exerciseChange(profileID uint, exercise models.TrainingSessionExercise, op string) models.SyncChange {
+	return models.SyncChange{
+		ProfileID: profileID,
+		Entity:    models.SyncEntitySessionExercise,
+		EntityID:  exercise.ID,
+		ClientID:  exercise.ClientID,
+		Op:        op,
+		Version:   exercise.Version,
+	}
+}
+
+func bodyWeightChange(bodyWeight models.BodyWeight, op string) models.SyncChange {
+	return models.SyncChange{
+		ProfileID: bodyWeight.ProfileID,
+		Entity:    models.SyncEntityBodyWeight,
+		EntityID:  bodyWeight.ID,
+		ClientID:  bodyWeight.ClientID,
+		Op:        op,
+		Version:   bodyWeight.Version,
+	}
+}
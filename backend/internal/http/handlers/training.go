@@ -1,7 +1,9 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"training-tracker/backend/internal/models"
 
@@ -21,7 +23,7 @@ func HandleListTrainings(c *gin.Context, db *gorm.DB) {
 	}
 
 	if err := query.Find(&trainings).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -31,12 +33,16 @@ func HandleListTrainings(c *gin.Context, db *gorm.DB) {
 func HandleCreateTraining(c *gin.Context, db *gorm.DB) {
 	var input models.Training
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
+		return
+	}
+	if !profileExists(db, input.ProfileID) {
+		respondError(c, http.StatusNotFound, "Profile not found")
 		return
 	}
 
 	if err := db.Create(&input).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -48,13 +54,20 @@ func HandleUpdateTraining(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
 
 	if err := db.First(&existing, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		respondError(c, http.StatusNotFound, "not found")
+		return
+	}
+	if !checkIfMatch(c, existing.Version, existing) {
 		return
 	}
 
 	var input models.Training
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
+		return
+	}
+	if !profileExists(db, existing.ProfileID) || !profileExists(db, input.ProfileID) {
+		respondError(c, http.StatusNotFound, "Profile not found")
 		return
 	}
 
@@ -111,18 +124,25 @@ func HandleUpdateTraining(c *gin.Context, db *gorm.DB) {
 	existing.Week4D6Reps = input.Week4D6Reps
 	existing.Week4D6Kg = input.Week4D6Kg
 
-	if err := db.Save(&existing).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	existing.Version++
+	if err := saveVersioned(db, &existing, existing.Version-1); err != nil {
+		writeSaveError(c, db, &existing, err)
 		return
 	}
 
+	c.Header("ETag", versionETag(existing.Version))
 	c.JSON(http.StatusOK, existing)
 }
 
 func HandleDeleteTraining(c *gin.Context, db *gorm.DB) {
 	id := c.Param("id")
-	if err := db.Delete(&models.Training{}, id).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	result := db.Delete(&models.Training{}, id)
+	if result.Error != nil {
+		respondInternal(c, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "not found")
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
@@ -130,24 +150,55 @@ func HandleDeleteTraining(c *gin.Context, db *gorm.DB) {
 
 // Exercise handlers
 
+// HandleListExercises - каталог упражнений. С profileId остаются только упражнения,
+// для которых есть оборудование в зале профиля: gymId или выбранном.
 func HandleListExercises(c *gin.Context, db *gorm.DB) {
+	var gym *models.Gym
+	if profileID, gymID := c.Query("profileId"), c.Query("gymId"); profileID != "" || gymID != "" {
+		pid, err := strconv.ParseUint(profileID, 10, 32)
+		if err != nil {
+			respondInvalid(c, invalidField("profileId", "required", "must be a profile ID to filter by gym"))
+			return
+		}
+		gym, err = findGym(db, uint(pid), gymID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				respondError(c, http.StatusNotFound, "Gym not found")
+				return
+			}
+			respondInternal(c, err)
+			return
+		}
+	}
+
 	var exercises []models.Exercise
 	if err := db.Order("is_custom ASC, category ASC, name ASC").Find(&exercises).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
+
+	if gym != nil {
+		available := make([]models.Exercise, 0, len(exercises))
+		for _, exercise := range exercises {
+			if gym.CanPerform(exercise) {
+				available = append(available, exercise)
+			}
+		}
+		exercises = available
+	}
+
 	c.JSON(http.StatusOK, exercises)
 }
 
 func HandleCreateExercise(c *gin.Context, db *gorm.DB) {
 	var input models.Exercise
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondInvalid(c, err)
 		return
 	}
 
 	if err := db.Create(&input).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
@@ -159,17 +210,17 @@ func HandleDeleteExercise(c *gin.Context, db *gorm.DB) {
 	var exercise models.Exercise
 
 	if err := db.First(&exercise, id).Error; err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		respondError(c, http.StatusNotFound, "not found")
 		return
 	}
 
 	if !exercise.IsCustom {
-		c.JSON(http.StatusForbidden, gin.H{"error": "cannot delete predefined exercises"})
+		respondError(c, http.StatusForbidden, "cannot delete predefined exercises")
 		return
 	}
 
 	if err := db.Delete(&exercise).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondInternal(c, err)
 		return
 	}
 
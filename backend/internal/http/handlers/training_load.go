@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"time"
+
+	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/recommendations"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Метрики дневной нагрузки
+const (
+	loadMetricSRPE   = "srpe"   // session RPE × длительность в минутах
+	loadMetricVolume = "volume" // вес × повторения
+)
+
+const (
+	acuteLoadDays   = 7
+	chronicLoadDays = 28
+	// defaultLoadDays - длина ряда в ответе без dateFrom
+	defaultLoadDays = 90
+	// monotonyCap - предел монотонности: при одинаковой нагрузке каждый день отклонение нулевое
+	monotonyCap = 10.0
+)
+
+// HandleGetTrainingLoad - дневная нагрузка профиля, ACWR, монотонность и напряжение.
+// Параметры: metric (srpe, volume; по умолчанию srpe, если у тренировок есть RPE),
+// dateFrom, dateTo (по умолчанию последние 90 дней по сегодня).
+func HandleGetTrainingLoad(c *gin.Context, db *gorm.DB) {
+	var profile models.Profile
+	if err := db.First(&profile, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Profile not found")
+		return
+	}
+
+	metric := c.Query("metric")
+	if metric != "" && metric != loadMetricSRPE && metric != loadMetricVolume {
+		respondInvalid(c, invalidField("metric", "oneof", "must be one of: srpe, volume"))
+		return
+	}
+
+	to := time.Now()
+	if value := c.Query("dateTo"); value != "" {
+		date, err := parseDate("dateTo", value)
+		if err != nil {
+			respondInvalid(c, err)
+			return
+		}
+		to = date
+	}
+	from := to.AddDate(0, 0, -(defaultLoadDays - 1))
+	if value := c.Query("dateFrom"); value != "" {
+		date, err := parseDate("dateFrom", value)
+		if err != nil {
+			respondInvalid(c, err)
+			return
+		}
+		from = date
+	}
+	if from.After(to) {
+		respondInvalid(c, invalidField("dateFrom", "ltefield", "must not be after dateTo"))
+		return
+	}
+
+	var exercises []models.Exercise
+	if err := db.Find(&exercises).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+	exerciseMap := make(map[string]models.Exercise)
+	for _, ex := range exercises {
+		exerciseMap[ex.Name] = ex
+	}
+
+	sessions, err := loadSessionsWithExercises(db, c.Param("id"))
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+	var bodyWeights []models.BodyWeight
+	if err := db.Where("profile_id = ?", profile.ID).Find(&bodyWeights).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+	sets := collectSessionSets(sessions, exerciseMap, newBodyWeightLookup(profile, bodyWeights))
+
+	response := calculateTrainingLoad(sessions, sets, metric, from, to)
+	response.Warnings = recommendations.Evaluate(recommendations.Input{Load: response.Current},
+		recommendationOptions(c, profile, recommendations.CategoryLoad))
+	c.JSON(http.StatusOK, response)
+}
+
+// dailyLoad - нагрузка за один день
+type dailyLoad struct {
+	sessions int
+	volume   float64
+	session  float64
+}
+
+// calculateTrainingLoad строит ряд нагрузки с первой тренировки по to, а в ответ
+// отдает дни с from. Скользящие окна считаются по календарным дням: дни отдыха
+// входят в них с нулевой нагрузкой. Пустой metric - srpe, если хотя бы у одной
+// тренировки есть session RPE, иначе volume.
+func calculateTrainingLoad(sessions []models.TrainingSessionWithExercises, sets []sessionSet, metric string, from, to time.Time) models.TrainingLoadResponse {
+	response := models.TrainingLoadResponse{Days: []models.TrainingLoadDay{}, Warnings: []models.Recommendation{}}
+
+	setsBySession := make(map[uint][]sessionSet)
+	byDay := make(map[string]*dailyLoad)
+	for _, s := range sets {
+		setsBySession[s.SessionID] = append(setsBySession[s.SessionID], s)
+	}
+	var start time.Time
+	var hasSessionLoad bool
+	for _, s := range sessions {
+		day := calendarDay(s.Date)
+		if start.IsZero() || day.Before(start) {
+			start = day
+		}
+		key := day.Format(dateLayout)
+		if byDay[key] == nil {
+			byDay[key] = &dailyLoad{}
+		}
+		load := byDay[key]
+		load.sessions++
+		for _, set := range setsBySession[s.ID] {
+			load.volume += float64(set.Set.Reps) * set.Load
+		}
+		if sessionLoad := sessionRPE(s.TrainingSession, setsBySession[s.ID]) * sessionMinutes(s); sessionLoad > 0 {
+			load.session += sessionLoad
+			hasSessionLoad = true
+		}
+	}
+
+	switch {
+	case metric != "":
+		response.Metric = metric
+	case hasSessionLoad:
+		response.Metric = loadMetricSRPE
+	default:
+		response.Metric = loadMetricVolume
+	}
+	if start.IsZero() {
+		return response
+	}
+
+	from, to = calendarDay(from), calendarDay(to)
+	var values []float64
+	for day := start; !day.After(to); day = day.AddDate(0, 0, 1) {
+		key := day.Format(dateLayout)
+		point := models.TrainingLoadDay{Date: key}
+		if load := byDay[key]; load != nil {
+			point.Sessions = load.sessions
+			point.VolumeLoad = round(load.volume)
+			point.SessionLoad = round(load.session)
+			point.Load = point.SessionLoad
+			if response.Metric == loadMetricVolume {
+				point.Load = point.VolumeLoad
+			}
+		}
+		values = append(values, point.Load)
+		fillLoadWindows(&point, values)
+
+		if !day.Before(from) {
+			response.Days = append(response.Days, point)
+		}
+	}
+
+	if len(response.Days) > 0 {
+		current := response.Days[len(response.Days)-1]
+		response.Current = &current
+	}
+	return response
+}
+
+// fillLoadWindows считает показатели дня по нагрузке всех дней до него включительно
+func fillLoadWindows(point *models.TrainingLoadDay, values []float64) {
+	acute := lastDays(values, acuteLoadDays)
+	point.Acute = round(sum(acute) / acuteLoadDays)
+	point.Chronic = round(sum(lastDays(values, chronicLoadDays)) / chronicLoadDays)
+
+	if len(values) >= chronicLoadDays && point.Chronic > 0 {
+		acwr := round(point.Acute / point.Chronic)
+		point.ACWR = &acwr
+	}
+
+	if len(acute) == acuteLoadDays {
+		mean := sum(acute) / acuteLoadDays
+		var variance float64
+		for _, v := range acute {
+			variance += (v - mean) * (v - mean)
+		}
+		// Неделя отдыха - монотонность не определена
+		if mean > 0 {
+			monotony := monotonyCap
+			if sd := math.Sqrt(variance / acuteLoadDays); sd > 0 {
+				monotony = round(math.Min(mean/sd, monotonyCap))
+			}
+			point.Monotony = &monotony
+			point.Strain = round(sum(acute) * monotony)
+		}
+	}
+}
+
+// sessionRPE - RPE тренировки: указанный атлетом или средний RPE рабочих подходов
+func sessionRPE(session models.TrainingSession, sets []sessionSet) float64 {
+	if session.RPE > 0 {
+		return session.RPE
+	}
+	var total float64
+	var count int
+	for _, s := range sets {
+		if s.Set.RPE > 0 {
+			total += s.Set.RPE
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// sessionMinutes - длительность тренировки в минутах; без нее - суммарное время кардио
+func sessionMinutes(session models.TrainingSessionWithExercises) float64 {
+	if session.Duration > 0 {
+		return float64(session.Duration)
+	}
+	var seconds int
+	for _, a := range session.Activities {
+		seconds += a.Duration
+	}
+	return float64(seconds) / 60
+}
+
+// calendarDay - полночь дня даты в UTC, чтобы шаг в сутки не зависел от часового пояса
+func calendarDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func lastDays(values []float64, n int) []float64 {
+	if len(values) > n {
+		return values[len(values)-n:]
+	}
+	return values
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
@@ -0,0 +1,421 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"training-tracker/backend/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// Корзина: удаление только заполняет deleted_at. Дочерние записи получают ту же
+// отметку времени, что и родитель, поэтому восстановление родителя возвращает
+// ровно то, что было удалено вместе с ним. Записи старше TrashRetention удаляются насовсем.
+
+// TrashRetention - сколько запись хранится в корзине до окончательного удаления
+var TrashRetention = 30 * 24 * time.Hour
+
+// trashChild - дочерняя таблица, которая удаляется и восстанавливается вместе с родителем
+type trashChild struct {
+	table  string
+	column string
+}
+
+// trashKind - тип записи в корзине
+type trashKind struct {
+	name     string
+	table    string
+	title    string // SQL-выражение для названия записи в списке
+	parent   string // родительская таблица; пусто, если запись принадлежит профилю напрямую
+	column   string // ссылка на родителя или profile_id
+	model    func() interface{}
+	children []trashChild
+}
+
+// trashKinds перечислены от листьев к корню - в этом порядке работает очистка
+var trashKinds = []trashKind{
+	{
+		name: "session_exercise", table: "training_session_exercises", title: "exercise",
+		parent: "training_sessions", column: "training_session_id",
+		model: func() interface{} { return &models.TrainingSessionExercise{} },
+	},
+	{
+		name: "cardio_activity", table: "cardio_activities", title: "activity",
+		parent: "training_sessions", column: "training_session_id",
+		model: func() interface{} { return &models.CardioActivity{} },
+	},
+	{
+		name: "program_exercise", table: "program_exercises", title: "exercise",
+		parent: "training_programs", column: "program_id",
+		model: func() interface{} { return &models.ProgramExercise{} },
+	},
+	{
+		name: "program_session", table: "program_sessions", title: "to_char(date, 'YYYY-MM-DD')",
+		parent: "training_programs", column: "program_id",
+		model: func() interface{} { return &models.ProgramSession{} },
+	},
+	{
+		name: "session", table: "training_sessions", title: "type || ' ' || to_char(date, 'YYYY-MM-DD')",
+		column: "profile_id",
+		model:  func() interface{} { return &models.TrainingSession{} },
+		children: []trashChild{
+			{table: "training_session_exercises", column: "training_session_id"},
+			{table: "cardio_activities", column: "training_session_id"},
+		},
+	},
+	{
+		name: "program", table: "training_programs", title: "name",
+		column: "profile_id",
+		model:  func() interface{} { return &models.TrainingProgram{} },
+		children: []trashChild{
+			{table: "program_exercises", column: "program_id"},
+			{table: "program_sessions", column: "program_id"},
+		},
+	},
+	{
+		name: "body_weight", table: "body_weights", title: "weight::text || ' kg ' || to_char(date, 'YYYY-MM-DD')",
+		column: "profile_id",
+		model:  func() interface{} { return &models.BodyWeight{} },
+	},
+	{
+		name: "goal", table: "goals", title: "title",
+		column: "profile_id",
+		model:  func() interface{} { return &models.Goal{} },
+	},
+	{
+		name: "personal_record", table: "personal_records", title: "exercise",
+		column: "profile_id",
+		model:  func() interface{} { return &models.PersonalRecord{} },
+	},
+	{
+		name: "gym", table: "gyms", title: "name",
+		column: "profile_id",
+		model:  func() interface{} { return &models.Gym{} },
+	},
+	{
+		name: "readiness", table: "readiness_check_ins", title: "to_char(date, 'YYYY-MM-DD')",
+		column: "profile_id",
+		model:  func() interface{} { return &models.ReadinessCheckIn{} },
+	},
+}
+
+func findTrashKind(name string) (trashKind, bool) {
+	for _, k := range trashKinds {
+		if k.name == name {
+			return k, true
+		}
+	}
+	return trashKind{}, false
+}
+
+// ownedBy ограничивает выборку записями профиля
+func findTrashTable(table string) (trashKind, bool) {
+	for _, k := range trashKinds {
+		if k.table == table {
+			return k, true
+		}
+	}
+	return trashKind{}, false
+}
+
+func (k trashKind) ownedBy(query *gorm.DB, profileID uint) *gorm.DB {
+	if k.parent == "" {
+		return query.Where(k.table+"."+k.column+" = ?", profileID)
+	}
+	return query.Where(fmt.Sprintf("%s.%s IN (SELECT id FROM %s WHERE profile_id = ?)", k.table, k.column, k.parent), profileID)
+}
+
+// moveToTrash помечает запись и ее дочерние записи удаленными с одной отметкой времени
+func moveToTrash(tx *gorm.DB, name string, id uint) error {
+	kind, ok := findTrashKind(name)
+	if !ok {
+		return fmt.Errorf("unknown trash item type %q", name)
+	}
+	now := time.Now()
+	if err := kind.trashChildren(tx, id, now); err != nil {
+		return err
+	}
+	result := tx.Table(kind.table).Where("id = ? AND deleted_at IS NULL", id).Update("deleted_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// trashChildren помечает живые дочерние записи отметкой родителя stamp
+func (k trashKind) trashChildren(tx *gorm.DB, id uint, stamp time.Time) error {
+	for _, child := range k.children {
+		if err := tx.Table(child.table).Where(child.column+" = ? AND deleted_at IS NULL", id).Update("deleted_at", stamp).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreChildren возвращает дочерние записи, удаленные вместе с родителем с отметкой stamp
+func (k trashKind) restoreChildren(tx *gorm.DB, id uint, stamp time.Time) error {
+	for _, child := range k.children {
+		if err := tx.Table(child.table).Where(child.column+" = ? AND deleted_at = ?", id, stamp).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HandleGetTrash - удаленные записи профиля, новые сверху.
+// Дочерние записи, удаленные вместе с родителем, не показываются: они вернутся с ним.
+func HandleGetTrash(c *gin.Context, db *gorm.DB) {
+	pid, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid profile ID")
+		return
+	}
+
+	items := make([]models.TrashItem, 0)
+	for _, k := range trashKinds {
+		query := db.Table(k.table).
+			Select(fmt.Sprintf("%s.id, %s AS title, %s.deleted_at", k.table, k.title, k.table)).
+			Where(k.table + ".deleted_at IS NOT NULL")
+		query = k.ownedBy(query, uint(pid))
+		if k.parent != "" {
+			query = query.Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s p WHERE p.id = %s.%s AND p.deleted_at = %s.deleted_at)",
+				k.parent, k.table, k.column, k.table))
+		}
+
+		var found []models.TrashItem
+		if err := query.Scan(&found).Error; err != nil {
+			respondInternal(c, err)
+			return
+		}
+		for _, item := range found {
+			item.Type = k.name
+			item.PurgeAt = item.DeletedAt.Add(TrashRetention)
+			items = append(items, item)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+	c.JSON(http.StatusOK, models.TrashResponse{Items: items, Retention: int(TrashRetention.Hours() / 24)})
+}
+
+// HandleRestoreTrashItem возвращает запись из корзины вместе с удаленными с ней дочерними.
+// Запись, чей родитель тоже в корзине, восстановить нельзя: сначала нужно вернуть родителя.
+func HandleRestoreTrashItem(c *gin.Context, db *gorm.DB) {
+	pid, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid profile ID")
+		return
+	}
+	kind, ok := findTrashKind(c.Param("type"))
+	if !ok {
+		respondError(c, http.StatusBadRequest, "Unknown trash item type")
+		return
+	}
+	itemID, err := strconv.ParseUint(c.Param("itemId"), 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid item ID")
+		return
+	}
+
+	var deleted struct {
+		ParentID  uint
+		DeletedAt time.Time
+	}
+	query := db.Table(kind.table).
+		Select(fmt.Sprintf("%s.%s AS parent_id, %s.deleted_at", kind.table, kind.column, kind.table)).
+		Where(kind.table+".id = ? AND "+kind.table+".deleted_at IS NOT NULL", itemID)
+	result := kind.ownedBy(query, uint(pid)).Scan(&deleted)
+	if result.Error != nil {
+		respondInternal(c, result.Error)
+		return
+	}
+	if result.RowsAffected == 0 {
+		respondError(c, http.StatusNotFound, "Item not found in trash")
+		return
+	}
+
+	if kind.parent != "" {
+		var parentDeleted int64
+		db.Table(kind.parent).Where("id = ? AND deleted_at IS NOT NULL", deleted.ParentID).Count(&parentDeleted)
+		if parentDeleted > 0 {
+			respondError(c, http.StatusConflict, "Parent record is in the trash, restore it first")
+			return
+		}
+	}
+
+	restored := kind.model()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().First(restored, itemID).Error; err != nil {
+			return err
+		}
+		if err := beforeRestore(tx, restored); err != nil {
+			return err
+		}
+		if err := kind.restoreChildren(tx, uint(itemID), deleted.DeletedAt); err != nil {
+			return err
+		}
+		if err := tx.Table(kind.table).Where("id = ?", itemID).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.First(restored, itemID).Error; err != nil {
+			return err
+		}
+		return afterRestore(tx, restored)
+	})
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		respondError(c, http.StatusConflict, restoreConflict(kind.table))
+		return
+	}
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, restored)
+}
+
+// restoreConflict объясняет, почему запись таблицы table нельзя вернуть: ее место
+// уже заняла другая активная тренировка или отметка самочувствия за тот же день
+func restoreConflict(table string) string {
+	switch table {
+	case "training_sessions":
+		return "Cannot restore an unfinished workout: " + errActiveWorkoutExists.Error()
+	case "readiness_check_ins":
+		return "Cannot restore the check-in: " + errCheckInExists.Error()
+	}
+	return "Cannot restore: a conflicting record already exists"
+}
+
+// beforeRestore освобождает место для записи, которая вернется из корзины:
+// выбранным может быть только один зал профиля (уникальный индекс idx_gyms_profile_selected)
+func beforeRestore(tx *gorm.DB, record interface{}) error {
+	if gym, ok := record.(*models.Gym); ok && gym.IsSelected {
+		return deselectGyms(tx, gym.ProfileID, gym.ID)
+	}
+	return nil
+}
+
+// afterRestore записывает восстановленные записи в ленту синхронизации
+// и следит, чтобы активной оставалась одна программа
+func afterRestore(tx *gorm.DB, restored interface{}) error {
+	switch record := restored.(type) {
+	case *models.TrainingSession:
+		var exercises []models.TrainingSessionExercise
+		if err := tx.Where("training_session_id = ?", record.ID).Find(&exercises).Error; err != nil {
+			return err
+		}
+		if err := logChange(tx, sessionChange(*record, models.SyncOpUpsert)); err != nil {
+			return err
+		}
+		for _, e := range exercises {
+			if err := logChange(tx, exerciseChange(record.ProfileID, e, models.SyncOpUpsert)); err != nil {
+				return err
+			}
+		}
+	case *models.TrainingSessionExercise:
+		var session models.TrainingSession
+		if err := tx.First(&session, record.TrainingSessionID).Error; err != nil {
+			return err
+		}
+		return logChange(tx, exerciseChange(session.ProfileID, *record, models.SyncOpUpsert))
+	case *models.BodyWeight:
+		return logChange(tx, bodyWeightChange(*record, models.SyncOpUpsert))
+	case *models.TrainingProgram:
+		if record.IsActive {
+			return deactivatePrograms(tx, record.ProfileID, record.ID)
+		}
+	}
+	return nil
+}
+
+// HandleGetDeletedProfiles - профили в корзине
+func HandleGetDeletedProfiles(c *gin.Context, db *gorm.DB) {
+	var profiles []models.Profile
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&profiles).Error; err != nil {
+		respondInternal(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, profiles)
+}
+
+// HandleRestoreProfile возвращает профиль и все данные, удаленные вместе с ним
+func HandleRestoreProfile(c *gin.Context, db *gorm.DB) {
+	var profile models.Profile
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").First(&profile, c.Param("id")).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Profile not found in trash")
+		return
+	}
+
+	stamp := profile.DeletedAt.Time
+	err := db.Transaction(func(tx *gorm.DB) error {
+		unscoped := tx.Unscoped().Session(&gorm.Session{})
+		for _, step := range profileDataSteps(unscoped, profile.ID) {
+			if err := unscoped.Model(step.model).Where(step.where, step.arg).Where("deleted_at = ?", stamp).Update("deleted_at", nil).Error; err != nil {
+				return err
+			}
+		}
+		if err := unscoped.Model(&profile).Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return logProfileChanges(tx, profile.ID, models.SyncOpUpsert)
+	})
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	profile.DeletedAt = gorm.DeletedAt{}
+	c.JSON(http.StatusOK, profile)
+}
+
+// PurgeTrash окончательно удаляет записи, пролежавшие в корзине дольше retention.
+// Очистка намеренно идет мимо журнала изменений (db.Exec не вызывает колбэки audit):
+// удаление пользователем уже записано, когда запись попала в корзину, а очистка -
+// обслуживание по сроку хранения, и она видна в логе сервера.
+func PurgeTrash(db *gorm.DB, retention time.Duration) error {
+	cutoff := time.Now().Add(-retention)
+
+	var profiles []models.Profile
+	if err := db.Unscoped().Where("deleted_at < ?", cutoff).Find(&profiles).Error; err != nil {
+		return err
+	}
+	for _, profile := range profiles {
+		if err := db.Transaction(func(tx *gorm.DB) error { return purgeProfileData(tx, profile.ID) }); err != nil {
+			return err
+		}
+		log.Printf("trash: purged profile %d", profile.ID)
+	}
+
+	for _, k := range trashKinds {
+		result := db.Exec(fmt.Sprintf("DELETE FROM %s WHERE deleted_at < ?", k.table), cutoff)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("trash: purged %d rows from %s", result.RowsAffected, k.table)
+		}
+	}
+	return nil
+}
+
+// RunTrashPurge - фоновая очистка корзины раз в interval
+func RunTrashPurge(db *gorm.DB, interval time.Duration) {
+	for {
+		if err := PurgeTrash(db, TrashRetention); err != nil {
+			log.Printf("warn: trash purge failed: %v", err)
+		}
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"training-tracker/backend/internal/models"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Общие правила проверки запросов: даты, диапазоны и перечисления описываются
+// тегами binding в DTO, а ошибки приводятся к списку models.FieldError.
+
+const dateLayout = "2006-01-02"
+
+// fieldError - ошибка поля, найденная проверкой в коде обработчика
+type fieldError struct {
+	models.FieldError
+}
+
+func (e *fieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+func invalidField(field, code, message string) error {
+	return &fieldError{models.FieldError{Field: field, Code: code, Message: message}}
+}
+
+// RegisterValidators настраивает валидатор gin: в ошибках используются имена полей из JSON
+func RegisterValidators() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+}
+
+// parseDate разбирает дату запроса в формате YYYY-MM-DD
+func parseDate(field, value string) (time.Time, error) {
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, invalidField(field, "datetime", "must be a date in YYYY-MM-DD format")
+	}
+	return date, nil
+}
+
+// checkDateOrder - дата окончания не раньше даты начала
+func checkDateOrder(start, end time.Time) error {
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return invalidField("endDate", "gtefield", "must not be before startDate")
+	}
+	return nil
+}
+
+// fieldErrors достает ошибки по полям из ошибок binding, JSON и собственных проверок
+func fieldErrors(err error) []models.FieldError {
+	var fe *fieldError
+	if errors.As(err, &fe) {
+		return []models.FieldError{fe.FieldError}
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []models.FieldError{{Field: typeErr.Field, Code: "type", Message: "must be a " + typeErr.Type.String()}}
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+	fields := make([]models.FieldError, 0, len(verrs))
+	for _, v := range verrs {
+		fields = append(fields, models.FieldError{
+			Field:   validationPath(v),
+			Code:    v.Tag(),
+			Message: validationMessage(v),
+		})
+	}
+	return fields
+}
+
+// validationPath - путь поля без имени корневой структуры и встроенных DTO
+func validationPath(v validator.FieldError) string {
+	parts := strings.Split(v.Namespace(), ".")
+	path := make([]string, 0, len(parts))
+	for _, part := range parts[1:] {
+		if strings.HasSuffix(part, "Request") {
+			continue
+		}
+		path = append(path, part)
+	}
+	return strings.Join(path, ".")
+}
+
+func validationMessage(v validator.FieldError) string {
+	switch v.Tag() {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.String {
+			return "must have at least " + v.Param() + " " + lengthUnit(v)
+		}
+		return "must be at least " + v.Param()
+	case "max", "lte":
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.String {
+			return "must have at most " + v.Param() + " " + lengthUnit(v)
+		}
+		return "must be at most " + v.Param()
+	case "gt":
+		return "must be greater than " + v.Param()
+	case "lt":
+		return "must be less than " + v.Param()
+	case "oneof":
+		return "must be one of: " + strings.ReplaceAll(v.Param(), " ", ", ")
+	case "datetime":
+		return "must be a date in YYYY-MM-DD format"
+	case "uuid":
+		return "must be a UUID"
+	case "required_with":
+		return "is required when " + paramField(v.Param()) + " is set"
+	case "required_without":
+		return "is required when " + paramField(v.Param()) + " is not set"
+	case "gtefield":
+		return "must be at least " + paramField(v.Param())
+	case "excluded_with":
+		return "must not be set together with " + paramField(v.Param())
+	default:
+		return "is invalid"
+	}
+}
+
+// paramField - имя поля из параметра правила (SessionID) в виде JSON (sessionId)
+func paramField(name string) string {
+	if name == "" {
+		return name
+	}
+	if strings.HasSuffix(name, "ID") {
+		name = strings.TrimSuffix(name, "ID") + "Id"
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+func lengthUnit(v validator.FieldError) string {
+	if v.Kind() == reflect.String {
+		return "characters"
+	}
+	return "items"
+}
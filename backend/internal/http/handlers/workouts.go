@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Live workout: старт, подходы по мере выполнения, пауза и завершение
+
+var errActiveWorkoutExists = errors.New("profile already has an active workout")
+
+// HandleStartWorkout - создание тренировки в статусе in_progress.
+// У профиля может быть только одна незавершенная тренировка.
+func HandleStartWorkout(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	pid, err := strconv.ParseUint(profileID, 10, 32)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, "Invalid profile ID")
+		return
+	}
+
+	var req models.StartWorkoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondInvalid(c, err)
+			return
+		}
+	}
+	if req.Type == "" {
+		req.Type = models.SessionTypeStrength
+	}
+
+	now := time.Now()
+	session := models.TrainingSession{
+		ProfileID: uint(pid),
+		Type:      req.Type,
+		Date:      now,
+		Notes:     req.Notes,
+		Energy:    5,
+		Mood:      5,
+		Soreness:  1,
+		Status:    models.SessionStatusInProgress,
+		StartedAt: &now,
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var active int64
+		if err := tx.Model(&models.TrainingSession{}).
+			Where("profile_id = ? AND status IN ?", pid, activeStatuses()).
+			Count(&active).Error; err != nil {
+			return err
+		}
+		if active > 0 {
+			return errActiveWorkoutExists
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+		return logChange(tx, sessionChange(session, models.SyncOpUpsert))
+	})
+	if err != nil {
+		// Уникальный частичный индекс тоже защищает от гонки двух одновременных стартов
+		if errors.Is(err, errActiveWorkoutExists) || errors.Is(err, gorm.ErrDuplicatedKey) {
+			respondError(c, http.StatusConflict, errActiveWorkoutExists.Error())
+			return
+		}
+		respondInternal(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, session)
+}
+
+func HandleGetActiveWorkout(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+
+	var session models.TrainingSession
+	if err := db.Where("profile_id = ? AND status IN ?", profileID, activeStatuses()).First(&session).Error; err != nil {
+		respondError(c, http.StatusNotFound, "No active workout")
+		return
+	}
+
+	var exercises []models.TrainingSessionExercise
+	db.Where("training_session_id = ?", session.ID).Order("\"order\" ASC, id ASC").Find(&exercises)
+
+	var activities []models.CardioActivity
+	db.Where("training_session_id = ?", session.ID).Order("id ASC").Find(&activities)
+
+	c.JSON(http.StatusOK, models.TrainingSessionWithExercises{
+		TrainingSession: session,
+		Exercises:       exercises,
+		Activities:      activities,
+	})
+}
+
+// HandleLogWorkoutSet - добавление выполненного подхода с отметкой времени
+func HandleLogWorkoutSet(c *gin.Context, db *gorm.DB) {
+	profileID := c.Param("id")
+	sessionID := c.Param("sessionId")
+
+	var session models.TrainingSession
+	if err := db.Where("id = ? AND profile_id = ?", sessionID, profileID).First(&session).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Training session not found")
+		return
+	}
+	if session.Status != models.SessionStatusInProgress {
+		respondError(c, http.StatusConflict, "Workout is not in progress")
+		return
+	}
+
+	var req models.WorkoutSetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	if req.Set.CompletedAt == nil {
+		now := time.Now()
+		req.Set.CompletedAt = &now
+	}
+	if err := validateSet("set", req.Set, findExercise(db, req.Exercise)); err != nil {
+		respondInvalid(c, err)
+		return
+	}
+
+	var exercise models.TrainingSessionExercise
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Блокируем строку, чтобы подходы с нескольких устройств не затирали друг друга
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("training_session_id = ? AND exercise = ?", session.ID, req.Exercise).
+			Order("\"order\" DESC").First(&exercise).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			var maxOrder int
+			tx.Model(&models.TrainingSessionExercise{}).Where("training_session_id = ?", session.ID).
+				Select("COALESCE(MAX(\"order\"), 0)").Scan(&maxOrder)
+			exercise = models.TrainingSessionExercise{
+				TrainingSessionID: session.ID,
+				Exercise:          req.Exercise,
+				Order:             maxOrder + 1,
+				GroupID:           req.GroupID,
+				GroupType:         req.GroupType,
+				Sets:              []models.Set{req.Set},
+			}
+			err = tx.Create(&exercise).Error
+		} else if err == nil {
+			exercise.Sets = append(exercise.Sets, req.Set)
+			exercise.Version++
+			err = tx.Save(&exercise).Error
+		}
+		if err != nil {
+			return err
+		}
+		return logChange(tx, exerciseChange(session.ProfileID, exercise, models.SyncOpUpsert))
+	})
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	sessionEvents.Publish(session.ID, realtime.EventSetAdded, exercise)
+	c.JSON(http.StatusCreated, exercise)
+}
+
+func HandlePauseWorkout(c *gin.Context, db *gorm.DB) {
+	session, ok := loadWorkout(c, db)
+	if !ok {
+		return
+	}
+	if session.Status != models.SessionStatusInProgress {
+		respondError(c, http.StatusConflict, "Workout is not in progress")
+		return
+	}
+
+	session.Pauses = append(session.Pauses, models.WorkoutPause{Start: time.Now()})
+	session.Status = models.SessionStatusPaused
+	saveWorkout(c, db, &session)
+}
+
+func HandleResumeWorkout(c *gin.Context, db *gorm.DB) {
+	session, ok := loadWorkout(c, db)
+	if !ok {
+		return
+	}
+	if session.Status != models.SessionStatusPaused {
+		respondError(c, http.StatusConflict, "Workout is not paused")
+		return
+	}
+
+	closePause(&session, time.Now())
+	session.Status = models.SessionStatusInProgress
+	saveWorkout(c, db, &session)
+}
+
+// HandleFinishWorkout - завершение тренировки, расчет длительности и суммарного отдыха
+func HandleFinishWorkout(c *gin.Context, db *gorm.DB) {
+	session, ok := loadWorkout(c, db)
+	if !ok {
+		return
+	}
+	if !session.IsActive() {
+		respondError(c, http.StatusConflict, "Workout is already finished")
+		return
+	}
+
+	var req models.FinishWorkoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondInvalid(c, err)
+			return
+		}
+	}
+
+	now := time.Now()
+	closePause(&session, now)
+
+	var exercises []models.TrainingSessionExercise
+	db.Where("training_session_id = ?", session.ID).Order("\"order\" ASC, id ASC").Find(&exercises)
+	session.RestSeconds = fillRestTimes(exercises, session.Pauses)
+
+	started := session.Date
+	if session.StartedAt != nil {
+		started = *session.StartedAt
+	}
+	active := now.Sub(started) - pausedDuration(session.Pauses)
+	session.Duration = int(math.Round(active.Minutes()))
+	session.FinishedAt = &now
+	session.Status = models.SessionStatusCompleted
+	session.Version++
+
+	if req.Notes != "" {
+		session.Notes = req.Notes
+	}
+	if req.Energy >= 1 && req.Energy <= 10 {
+		session.Energy = req.Energy
+	}
+	if req.Mood >= 1 && req.Mood <= 10 {
+		session.Mood = req.Mood
+	}
+	if req.Soreness >= 1 && req.Soreness <= 10 {
+		session.Soreness = req.Soreness
+	}
+	if req.RPE >= 1 && req.RPE <= 10 {
+		session.RPE = req.RPE
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for i := range exercises {
+			exercises[i].Version++
+			if err := tx.Save(&exercises[i]).Error; err != nil {
+				return err
+			}
+			if err := logChange(tx, exerciseChange(session.ProfileID, exercises[i], models.SyncOpUpsert)); err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(&session).Error; err != nil {
+			return err
+		}
+		return logChange(tx, sessionChange(session, models.SyncOpUpsert))
+	})
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+
+	sessionEvents.Publish(session.ID, realtime.EventSessionUpdated, session)
+	c.JSON(http.StatusOK, models.TrainingSessionWithExercises{
+		TrainingSession: session,
+		Exercises:       exercises,
+	})
+}
+
+func activeStatuses() []string {
+	return []string{models.SessionStatusInProgress, models.SessionStatusPaused}
+}
+
+func loadWorkout(c *gin.Context, db *gorm.DB) (models.TrainingSession, bool) {
+	var session models.TrainingSession
+	if err := db.Where("id = ? AND profile_id = ?", c.Param("sessionId"), c.Param("id")).First(&session).Error; err != nil {
+		respondError(c, http.StatusNotFound, "Training session not found")
+		return session, false
+	}
+	return session, true
+}
+
+func saveWorkout(c *gin.Context, db *gorm.DB, session *models.TrainingSession) {
+	session.Version++
+	session.UpdatedAt = time.Now()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(session).Error; err != nil {
+			return err
+		}
+		return logChange(tx, sessionChange(*session, models.SyncOpUpsert))
+	})
+	if err != nil {
+		respondInternal(c, err)
+		return
+	}
+	sessionEvents.Publish(session.ID, realtime.EventSessionUpdated, session)
+	c.JSON(http.StatusOK, session)
+}
+
+// closePause завершает открытую паузу, если она есть
+func closePause(session *models.TrainingSession, at time.Time) {
+	if n := len(session.Pauses); n > 0 && session.Pauses[n-1].End == nil {
+		session.Pauses[n-1].End = &at
+	}
+}
+
+func pausedDuration(pauses []models.WorkoutPause) time.Duration {
+	var total time.Duration
+	for _, p := range pauses {
+		if p.End != nil {
+			total += p.End.Sub(p.Start)
+		}
+	}
+	return total
+}
+
+// pausedBetween - сколько времени из интервала пришлось на паузы
+func pausedBetween(pauses []models.WorkoutPause, from, to time.Time) time.Duration {
+	var total time.Duration
+	for _, p := range pauses {
+		if p.End == nil {
+			continue
+		}
+		start, end := p.Start, *p.End
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
+
+// fillRestTimes проставляет отдых подходам без явного Rest по интервалу до следующего подхода
+// (без учета пауз) и возвращает суммарный отдых тренировки в секундах
+func fillRestTimes(exercises []models.TrainingSessionExercise, pauses []models.WorkoutPause) int {
+	type timedSet struct {
+		exercise, set int
+		at            time.Time
+	}
+	var timeline []timedSet
+	for i, ex := range exercises {
+		for j, set := range ex.Sets {
+			if set.CompletedAt != nil {
+				timeline = append(timeline, timedSet{i, j, *set.CompletedAt})
+			}
+		}
+	}
+	sort.Slice(timeline, func(a, b int) bool { return timeline[a].at.Before(timeline[b].at) })
+
+	total := 0
+	for k := 0; k+1 < len(timeline); k++ {
+		cur, next := timeline[k], timeline[k+1]
+		set := &exercises[cur.exercise].Sets[cur.set]
+		if set.Rest == 0 {
+			gap := next.at.Sub(cur.at) - pausedBetween(pauses, cur.at, next.at)
+			set.Rest = int(gap.Seconds())
+		}
+		total += set.Rest
+	}
+	return total
+}
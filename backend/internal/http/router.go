@@ -1,6 +1,7 @@
 package http
 
 import (
+	"training-tracker/backend/internal/audit"
 	"training-tracker/backend/internal/config"
 	"training-tracker/backend/internal/http/handlers"
 
@@ -11,93 +12,169 @@ import (
 
 // SetupRouter configures and returns the Gin router with all routes
 func SetupRouter(db *gorm.DB) *gin.Engine {
+	handlers.RegisterValidators()
+
 	router := gin.Default()
 
 	corsOrigin := config.GetEnv("CORS_ORIGIN", "*")
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{corsOrigin},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 	}))
 
+	router.Use(audit.Middleware())
+
 	api := router.Group("/api")
 	{
 		// Legacy training routes
 		trainings := api.Group("/trainings")
 		{
-			trainings.GET("", func(c *gin.Context) { handlers.HandleListTrainings(c, db) })
-			trainings.POST("", func(c *gin.Context) { handlers.HandleCreateTraining(c, db) })
-			trainings.PUT(":id", func(c *gin.Context) { handlers.HandleUpdateTraining(c, db) })
-			trainings.DELETE(":id", func(c *gin.Context) { handlers.HandleDeleteTraining(c, db) })
+			trainings.GET("", func(c *gin.Context) { handlers.HandleListTrainings(c, requestDB(c, db)) })
+			trainings.POST("", func(c *gin.Context) { handlers.HandleCreateTraining(c, requestDB(c, db)) })
+			trainings.PUT(":id", func(c *gin.Context) { handlers.HandleUpdateTraining(c, requestDB(c, db)) })
+			trainings.DELETE(":id", func(c *gin.Context) { handlers.HandleDeleteTraining(c, requestDB(c, db)) })
 		}
 
 		// Exercise routes
 		exercises := api.Group("/exercises")
 		{
-			exercises.GET("", func(c *gin.Context) { handlers.HandleListExercises(c, db) })
-			exercises.POST("", func(c *gin.Context) { handlers.HandleCreateExercise(c, db) })
-			exercises.DELETE(":id", func(c *gin.Context) { handlers.HandleDeleteExercise(c, db) })
+			exercises.GET("", func(c *gin.Context) { handlers.HandleListExercises(c, requestDB(c, db)) })
+			exercises.POST("", func(c *gin.Context) { handlers.HandleCreateExercise(c, requestDB(c, db)) })
+			exercises.DELETE(":id", func(c *gin.Context) { handlers.HandleDeleteExercise(c, requestDB(c, db)) })
 		}
 
 		// Profile routes
-		profiles := api.Group("/profiles")
+		profiles := api.Group("/profiles", handlers.RequireActiveProfile(db))
 		{
-			profiles.GET("", func(c *gin.Context) { handlers.HandleListProfiles(c, db) })
-			profiles.POST("", func(c *gin.Context) { handlers.HandleCreateProfile(c, db) })
-			profiles.PUT(":id", func(c *gin.Context) { handlers.HandleUpdateProfile(c, db) })
-			profiles.DELETE(":id", func(c *gin.Context) { handlers.HandleDeleteProfile(c, db) })
-			profiles.GET(":id/analytics", func(c *gin.Context) { handlers.HandleGetAnalytics(c, db) })
-			profiles.GET(":id/progress-charts", func(c *gin.Context) { handlers.HandleGetProgressCharts(c, db) })
-			profiles.GET(":id/exercises", func(c *gin.Context) { handlers.HandleGetProfileExercises(c, db) })
+			profiles.GET("", func(c *gin.Context) { handlers.HandleListProfiles(c, requestDB(c, db)) })
+			profiles.GET("trash", func(c *gin.Context) { handlers.HandleGetDeletedProfiles(c, requestDB(c, db)) })
+			profiles.POST("", func(c *gin.Context) { handlers.HandleCreateProfile(c, requestDB(c, db)) })
+			profiles.PUT(":id", func(c *gin.Context) { handlers.HandleUpdateProfile(c, requestDB(c, db)) })
+			profiles.PATCH(":id", func(c *gin.Context) { handlers.HandlePatchProfile(c, requestDB(c, db)) })
+			profiles.DELETE(":id", func(c *gin.Context) { handlers.HandleDeleteProfile(c, requestDB(c, db)) })
+			profiles.POST(":id/restore", func(c *gin.Context) { handlers.HandleRestoreProfile(c, requestDB(c, db)) })
+			profiles.GET(":id/analytics", func(c *gin.Context) { handlers.HandleGetAnalytics(c, requestDB(c, db)) })
+			profiles.GET(":id/recommendation-rules", func(c *gin.Context) { handlers.HandleGetRecommendationRules(c, requestDB(c, db)) })
+			profiles.PUT(":id/recommendation-rules/:ruleId", func(c *gin.Context) { handlers.HandleSetRecommendationRule(c, requestDB(c, db)) })
+			profiles.GET(":id/progression", func(c *gin.Context) { handlers.HandleGetProgression(c, requestDB(c, db)) })
+			profiles.GET(":id/analytics/readiness", func(c *gin.Context) { handlers.HandleGetReadinessAnalysis(c, requestDB(c, db)) })
+			profiles.GET(":id/progress-charts", func(c *gin.Context) { handlers.HandleGetProgressCharts(c, requestDB(c, db)) })
+			profiles.GET(":id/exercises", func(c *gin.Context) { handlers.HandleGetProfileExercises(c, requestDB(c, db)) })
+			profiles.GET(":id/e1rm-history", func(c *gin.Context) { handlers.HandleGetOneRMHistory(c, requestDB(c, db)) })
+			profiles.GET(":id/plates", func(c *gin.Context) { handlers.HandleGetPlateLoad(c, requestDB(c, db)) })
+			profiles.GET(":id/strength", func(c *gin.Context) { handlers.HandleGetStrengthScores(c, requestDB(c, db)) })
+			profiles.GET(":id/training-load", func(c *gin.Context) { handlers.HandleGetTrainingLoad(c, requestDB(c, db)) })
+
+			// Gyms and equipment
+			profiles.GET(":id/gyms", func(c *gin.Context) { handlers.HandleGetGyms(c, requestDB(c, db)) })
+			profiles.POST(":id/gyms", func(c *gin.Context) { handlers.HandleCreateGym(c, requestDB(c, db)) })
+			profiles.PUT(":id/gyms/:gymId", func(c *gin.Context) { handlers.HandleUpdateGym(c, requestDB(c, db)) })
+			profiles.PATCH(":id/gyms/:gymId", func(c *gin.Context) { handlers.HandlePatchGym(c, requestDB(c, db)) })
+			profiles.POST(":id/gyms/:gymId/select", func(c *gin.Context) { handlers.HandleSelectGym(c, requestDB(c, db)) })
+			profiles.DELETE(":id/gyms/:gymId", func(c *gin.Context) { handlers.HandleDeleteGym(c, requestDB(c, db)) })
+			profiles.GET(":id/readiness", func(c *gin.Context) { handlers.HandleGetReadinessCheckIns(c, requestDB(c, db)) })
+			profiles.POST(":id/readiness", func(c *gin.Context) { handlers.HandleAddReadinessCheckIn(c, requestDB(c, db)) })
+			profiles.PUT(":id/readiness/:checkInId", func(c *gin.Context) { handlers.HandleUpdateReadinessCheckIn(c, requestDB(c, db)) })
+			profiles.PATCH(":id/readiness/:checkInId", func(c *gin.Context) { handlers.HandlePatchReadinessCheckIn(c, requestDB(c, db)) })
+			profiles.DELETE(":id/readiness/:checkInId", func(c *gin.Context) { handlers.HandleDeleteReadinessCheckIn(c, requestDB(c, db)) })
 
 			// Body Weight tracking
-			profiles.GET(":id/body-weight", func(c *gin.Context) { handlers.HandleGetBodyWeight(c, db) })
-			profiles.POST(":id/body-weight", func(c *gin.Context) { handlers.HandleAddBodyWeight(c, db) })
-			profiles.PUT(":id/body-weight/:weightId", func(c *gin.Context) { handlers.HandleUpdateBodyWeight(c, db) })
-			profiles.DELETE(":id/body-weight/:weightId", func(c *gin.Context) { handlers.HandleDeleteBodyWeight(c, db) })
+			profiles.GET(":id/body-weight", func(c *gin.Context) { handlers.HandleGetBodyWeight(c, requestDB(c, db)) })
+			profiles.POST(":id/body-weight", func(c *gin.Context) { handlers.HandleAddBodyWeight(c, requestDB(c, db)) })
+			profiles.PUT(":id/body-weight/:weightId", func(c *gin.Context) { handlers.HandleUpdateBodyWeight(c, requestDB(c, db)) })
+			profiles.PATCH(":id/body-weight/:weightId", func(c *gin.Context) { handlers.HandlePatchBodyWeight(c, requestDB(c, db)) })
+			profiles.DELETE(":id/body-weight/:weightId", func(c *gin.Context) { handlers.HandleDeleteBodyWeight(c, requestDB(c, db)) })
 
 			// Personal Records
-			profiles.GET(":id/personal-records", func(c *gin.Context) { handlers.HandleGetPersonalRecords(c, db) })
-			profiles.POST(":id/personal-records", func(c *gin.Context) { handlers.HandleAddPersonalRecord(c, db) })
-			profiles.DELETE(":id/personal-records/:recordId", func(c *gin.Context) { handlers.HandleDeletePersonalRecord(c, db) })
+			profiles.GET(":id/personal-records", func(c *gin.Context) { handlers.HandleGetPersonalRecords(c, requestDB(c, db)) })
+			profiles.POST(":id/personal-records", func(c *gin.Context) { handlers.HandleAddPersonalRecord(c, requestDB(c, db)) })
+			profiles.DELETE(":id/personal-records/:recordId", func(c *gin.Context) { handlers.HandleDeletePersonalRecord(c, requestDB(c, db)) })
 
 			// Goals
-			profiles.GET(":id/goals", func(c *gin.Context) { handlers.HandleGetGoals(c, db) })
-			profiles.POST(":id/goals", func(c *gin.Context) { handlers.HandleCreateGoal(c, db) })
-			profiles.PUT(":id/goals/:goalId", func(c *gin.Context) { handlers.HandleUpdateGoal(c, db) })
-			profiles.PUT(":id/goals/:goalId/progress", func(c *gin.Context) { handlers.HandleUpdateGoalProgress(c, db) })
-			profiles.DELETE(":id/goals/:goalId", func(c *gin.Context) { handlers.HandleDeleteGoal(c, db) })
+			profiles.GET(":id/goals", func(c *gin.Context) { handlers.HandleGetGoals(c, requestDB(c, db)) })
+			profiles.POST(":id/goals", func(c *gin.Context) { handlers.HandleCreateGoal(c, requestDB(c, db)) })
+			profiles.PUT(":id/goals/:goalId", func(c *gin.Context) { handlers.HandleUpdateGoal(c, requestDB(c, db)) })
+			profiles.PATCH(":id/goals/:goalId", func(c *gin.Context) { handlers.HandlePatchGoal(c, requestDB(c, db)) })
+			profiles.PUT(":id/goals/:goalId/progress", func(c *gin.Context) { handlers.HandleUpdateGoalProgress(c, requestDB(c, db)) })
+			profiles.DELETE(":id/goals/:goalId", func(c *gin.Context) { handlers.HandleDeleteGoal(c, requestDB(c, db)) })
 
 			// Training History
-			profiles.GET(":id/training-history", func(c *gin.Context) { handlers.HandleGetTrainingHistory(c, db) })
-			profiles.POST(":id/training-sessions", func(c *gin.Context) { handlers.HandleCreateTrainingSession(c, db) })
-			profiles.PUT(":id/training-sessions/:sessionId", func(c *gin.Context) { handlers.HandleUpdateTrainingSession(c, db) })
-			profiles.DELETE(":id/training-sessions/:sessionId", func(c *gin.Context) { handlers.HandleDeleteTrainingSession(c, db) })
-			profiles.POST(":id/training-sessions/:sessionId/exercises", func(c *gin.Context) { handlers.HandleAddExerciseToSession(c, db) })
-			profiles.PUT(":id/training-sessions/:sessionId/exercises/:exerciseId", func(c *gin.Context) { handlers.HandleUpdateSessionExercise(c, db) })
-			profiles.DELETE(":id/training-sessions/:sessionId/exercises/:exerciseId", func(c *gin.Context) { handlers.HandleDeleteSessionExercise(c, db) })
+			profiles.GET(":id/training-history", func(c *gin.Context) { handlers.HandleGetTrainingHistory(c, requestDB(c, db)) })
+			profiles.POST(":id/training-sessions", func(c *gin.Context) { handlers.HandleCreateTrainingSession(c, requestDB(c, db)) })
+			profiles.PUT(":id/training-sessions/:sessionId", func(c *gin.Context) { handlers.HandleUpdateTrainingSession(c, requestDB(c, db)) })
+			profiles.PATCH(":id/training-sessions/:sessionId", func(c *gin.Context) { handlers.HandlePatchTrainingSession(c, requestDB(c, db)) })
+			profiles.GET(":id/training-sessions/:sessionId/stream", func(c *gin.Context) { handlers.HandleStreamTrainingSession(c, requestDB(c, db)) })
+			profiles.DELETE(":id/training-sessions/:sessionId", func(c *gin.Context) { handlers.HandleDeleteTrainingSession(c, requestDB(c, db)) })
+			profiles.POST(":id/training-sessions/:sessionId/exercises", func(c *gin.Context) { handlers.HandleAddExerciseToSession(c, requestDB(c, db)) })
+			profiles.PUT(":id/training-sessions/:sessionId/exercises/:exerciseId", func(c *gin.Context) { handlers.HandleUpdateSessionExercise(c, requestDB(c, db)) })
+			profiles.PATCH(":id/training-sessions/:sessionId/exercises/:exerciseId", func(c *gin.Context) { handlers.HandlePatchSessionExercise(c, requestDB(c, db)) })
+			profiles.DELETE(":id/training-sessions/:sessionId/exercises/:exerciseId", func(c *gin.Context) { handlers.HandleDeleteSessionExercise(c, requestDB(c, db)) })
+			profiles.PUT(":id/training-sessions/:sessionId/exercise-order", func(c *gin.Context) { handlers.HandleReorderSessionExercises(c, requestDB(c, db)) })
+			profiles.POST(":id/training-sessions/:sessionId/activities", func(c *gin.Context) { handlers.HandleAddCardioActivity(c, requestDB(c, db)) })
+			profiles.PUT(":id/training-sessions/:sessionId/activities/:activityId", func(c *gin.Context) { handlers.HandleUpdateCardioActivity(c, requestDB(c, db)) })
+			profiles.DELETE(":id/training-sessions/:sessionId/activities/:activityId", func(c *gin.Context) { handlers.HandleDeleteCardioActivity(c, requestDB(c, db)) })
+			profiles.POST(":id/activity-imports", func(c *gin.Context) { handlers.HandleImportActivity(c, requestDB(c, db)) })
+
+			// Live workout
+			profiles.POST(":id/workouts/start", func(c *gin.Context) { handlers.HandleStartWorkout(c, requestDB(c, db)) })
+			profiles.GET(":id/workouts/active", func(c *gin.Context) { handlers.HandleGetActiveWorkout(c, requestDB(c, db)) })
+			profiles.POST(":id/workouts/:sessionId/sets", func(c *gin.Context) { handlers.HandleLogWorkoutSet(c, requestDB(c, db)) })
+			profiles.POST(":id/workouts/:sessionId/pause", func(c *gin.Context) { handlers.HandlePauseWorkout(c, requestDB(c, db)) })
+			profiles.POST(":id/workouts/:sessionId/resume", func(c *gin.Context) { handlers.HandleResumeWorkout(c, requestDB(c, db)) })
+			profiles.POST(":id/workouts/:sessionId/finish", func(c *gin.Context) { handlers.HandleFinishWorkout(c, requestDB(c, db)) })
+
+			// Trash
+			profiles.GET(":id/trash", func(c *gin.Context) { handlers.HandleGetTrash(c, requestDB(c, db)) })
+			profiles.POST(":id/trash/:type/:itemId/restore", func(c *gin.Context) { handlers.HandleRestoreTrashItem(c, requestDB(c, db)) })
+
+			// Change history
+			profiles.GET(":id/audit", func(c *gin.Context) { handlers.HandleGetProfileAudit(c, requestDB(c, db)) })
+			profiles.POST(":id/audit/:auditId/revert", func(c *gin.Context) { handlers.HandleRevertAudit(c, requestDB(c, db)) })
+
+			// Offline sync
+			profiles.POST(":id/sync", func(c *gin.Context) { handlers.HandleSync(c, requestDB(c, db)) })
+			profiles.GET(":id/sync/changes", func(c *gin.Context) { handlers.HandleGetSyncChanges(c, requestDB(c, db)) })
 
 			// Training Programs
-			profiles.GET(":id/programs", func(c *gin.Context) { handlers.HandleGetPrograms(c, db) })
-			profiles.POST(":id/programs", func(c *gin.Context) { handlers.HandleCreateProgram(c, db) })
-			profiles.PUT(":id/programs/:programId", func(c *gin.Context) { handlers.HandleUpdateProgram(c, db) })
-			profiles.DELETE(":id/programs/:programId", func(c *gin.Context) { handlers.HandleDeleteProgram(c, db) })
-			profiles.GET(":id/programs/:programId/exercises", func(c *gin.Context) { handlers.HandleGetProgramExercises(c, db) })
-			profiles.POST(":id/programs/:programId/exercises", func(c *gin.Context) { handlers.HandleCreateProgramExercise(c, db) })
-			profiles.PUT(":id/programs/:programId/exercises/:exerciseId", func(c *gin.Context) { handlers.HandleUpdateProgramExercise(c, db) })
-			profiles.DELETE(":id/programs/:programId/exercises/:exerciseId", func(c *gin.Context) { handlers.HandleDeleteProgramExercise(c, db) })
-			profiles.GET(":id/programs/:programId/plan-days", func(c *gin.Context) { handlers.HandleGetProgramPlanDays(c, db) })
-			profiles.GET(":id/programs/:programId/sessions", func(c *gin.Context) { handlers.HandleGetProgramSessions(c, db) })
-			profiles.POST(":id/programs/:programId/sessions", func(c *gin.Context) { handlers.HandleCreateProgramSession(c, db) })
-			profiles.PUT(":id/programs/:programId/sessions/:sessionId", func(c *gin.Context) { handlers.HandleUpdateProgramSession(c, db) })
-			profiles.DELETE(":id/programs/:programId/sessions/:sessionId", func(c *gin.Context) { handlers.HandleDeleteProgramSession(c, db) })
+			profiles.GET(":id/programs", func(c *gin.Context) { handlers.HandleGetPrograms(c, requestDB(c, db)) })
+			profiles.POST(":id/programs", func(c *gin.Context) { handlers.HandleCreateProgram(c, requestDB(c, db)) })
+			profiles.PUT(":id/programs/:programId", func(c *gin.Context) { handlers.HandleUpdateProgram(c, requestDB(c, db)) })
+			profiles.PATCH(":id/programs/:programId", func(c *gin.Context) { handlers.HandlePatchProgram(c, requestDB(c, db)) })
+			profiles.DELETE(":id/programs/:programId", func(c *gin.Context) { handlers.HandleDeleteProgram(c, requestDB(c, db)) })
+			profiles.GET(":id/programs/:programId/exercises", func(c *gin.Context) { handlers.HandleGetProgramExercises(c, requestDB(c, db)) })
+			profiles.POST(":id/programs/:programId/exercises", func(c *gin.Context) { handlers.HandleCreateProgramExercise(c, requestDB(c, db)) })
+			profiles.PUT(":id/programs/:programId/exercises/:exerciseId", func(c *gin.Context) { handlers.HandleUpdateProgramExercise(c, requestDB(c, db)) })
+			profiles.DELETE(":id/programs/:programId/exercises/:exerciseId", func(c *gin.Context) { handlers.HandleDeleteProgramExercise(c, requestDB(c, db)) })
+			profiles.PUT(":id/programs/:programId/exercise-order", func(c *gin.Context) { handlers.HandleReorderProgramExercises(c, requestDB(c, db)) })
+			profiles.GET(":id/programs/:programId/plan-days", func(c *gin.Context) { handlers.HandleGetProgramPlanDays(c, requestDB(c, db)) })
+			profiles.GET(":id/programs/:programId/sessions", func(c *gin.Context) { handlers.HandleGetProgramSessions(c, requestDB(c, db)) })
+			profiles.POST(":id/programs/:programId/sessions", func(c *gin.Context) { handlers.HandleCreateProgramSession(c, requestDB(c, db)) })
+			profiles.PUT(":id/programs/:programId/sessions/:sessionId", func(c *gin.Context) { handlers.HandleUpdateProgramSession(c, requestDB(c, db)) })
+			profiles.DELETE(":id/programs/:programId/sessions/:sessionId", func(c *gin.Context) { handlers.HandleDeleteProgramSession(c, requestDB(c, db)) })
+		}
+
+		// Change history of records outside a single profile
+		api.GET("/audit/:entity/:entityId", func(c *gin.Context) { handlers.HandleGetEntityAudit(c, requestDB(c, db)) })
+
+		// Data integrity
+		maintenance := api.Group("/maintenance")
+		{
+			maintenance.GET("/orphans", func(c *gin.Context) { handlers.HandleGetOrphans(c, requestDB(c, db)) })
+			maintenance.DELETE("/orphans", func(c *gin.Context) { handlers.HandleDeleteOrphans(c, requestDB(c, db)) })
 		}
 
 		// OneRM calculation endpoint
-		api.POST("/calculate-1rm", func(c *gin.Context) { handlers.HandleCalculate1RM(c) })
+		api.POST("/calculate-1rm", func(c *gin.Context) { handlers.HandleCalculate1RM(c, requestDB(c, db)) })
 	}
 
 	return router
 }
+
+// requestDB привязывает запросы к базе к контексту HTTP-запроса,
+// чтобы журнал изменений знал автора
+func requestDB(c *gin.Context, db *gorm.DB) *gorm.DB {
+	return db.WithContext(c.Request.Context())
+}
@@ -0,0 +1,156 @@
+// Package integrity следит за ссылочной целостностью: внешние ключи с ON DELETE
+// и поиск записей, чей родитель уже удален (сироты, оставшиеся до появления ключей).
+package integrity
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"training-tracker/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Relation - ссылка дочерней таблицы на родительскую
+type Relation struct {
+	Table    string // дочерняя таблица
+	Column   string // колонка со ссылкой
+	Parent   string // родительская таблица, ссылка идет на ее id
+	OnDelete string // CASCADE, SET NULL, RESTRICT
+}
+
+// Relations перечислены от корня к листьям: удаление сирот в этом порядке
+// за один проход убирает и записи, осиротевшие на предыдущем шаге.
+var Relations = []Relation{
+	{Table: "body_weights", Column: "profile_id", Parent: "profiles", OnDelete: "CASCADE"},
+	{Table: "personal_records", Column: "profile_id", Parent: "profiles", OnDelete: "CASCADE"},
+	{Table: "goals", Column: "profile_id", Parent: "profiles", OnDelete: "CASCADE"},
+	{Table: "trainings", Column: "profile_id", Parent: "profiles", OnDelete: "CASCADE"},
+	{Table: "sync_changes", Column: "profile_id", Parent: "profiles", OnDelete: "CASCADE"},
+	{Table: "training_sessions", Column: "profile_id", Parent: "profiles", OnDelete: "CASCADE"},
+	{Table: "training_session_exercises", Column: "training_session_id", Parent: "training_sessions", OnDelete: "CASCADE"},
+	{Table: "cardio_activities", Column: "training_session_id", Parent: "training_sessions", OnDelete: "CASCADE"},
+	{Table: "training_programs", Column: "profile_id", Parent: "profiles", OnDelete: "CASCADE"},
+	{Table: "program_exercises", Column: "program_id", Parent: "training_programs", OnDelete: "CASCADE"},
+	{Table: "program_sessions", Column: "program_id", Parent: "training_programs", OnDelete: "CASCADE"},
+	{Table: "gyms", Column: "profile_id", Parent: "profiles", OnDelete: "CASCADE"},
+	{Table: "readiness_check_ins", Column: "profile_id", Parent: "profiles", OnDelete: "CASCADE"},
+}
+
+// ConstraintName - имя внешнего ключа в базе
+func (r Relation) ConstraintName() string {
+	return fmt.Sprintf("fk_%s_%s", r.Table, r.Column)
+}
+
+// orphansQuery - выборка дочерних записей без родителя
+func (r Relation) orphansQuery(db *gorm.DB) *gorm.DB {
+	return db.Table(r.Table + " AS c").
+		Where(fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s p WHERE p.id = c.%s)", r.Parent, r.Column))
+}
+
+// EnsureForeignKeys создает недостающие внешние ключи.
+// Ключ не создается, пока в таблице есть сироты: их нужно сначала удалить (DeleteOrphans).
+func EnsureForeignKeys(db *gorm.DB) {
+	for _, r := range Relations {
+		if !db.Migrator().HasTable(r.Table) || !db.Migrator().HasTable(r.Parent) {
+			continue
+		}
+
+		var exists bool
+		if err := db.Raw("SELECT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = ?)", r.ConstraintName()).Scan(&exists).Error; err != nil {
+			log.Printf("warn: failed to check foreign key %s: %v", r.ConstraintName(), err)
+			continue
+		}
+		if exists {
+			continue
+		}
+
+		var orphans int64
+		if err := r.orphansQuery(db).Count(&orphans).Error; err != nil {
+			log.Printf("warn: failed to count orphans in %s: %v", r.Table, err)
+			continue
+		}
+		if orphans > 0 {
+			log.Printf("warn: foreign key %s not created: %d orphaned rows in %s", r.ConstraintName(), orphans, r.Table)
+			continue
+		}
+
+		sql := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s(id) ON DELETE %s",
+			r.Table, r.ConstraintName(), r.Column, r.Parent, r.OnDelete)
+		if err := db.Exec(sql).Error; err != nil {
+			log.Printf("warn: failed to create foreign key %s: %v", r.ConstraintName(), err)
+		}
+	}
+}
+
+// FindOrphans считает сирот по каждой связи. В отчет попадают только связи, где они есть.
+func FindOrphans(db *gorm.DB) ([]models.OrphanReport, error) {
+	reports := make([]models.OrphanReport, 0)
+	for _, r := range Relations {
+		if !db.Migrator().HasTable(r.Table) {
+			continue
+		}
+		var ids []uint
+		if err := r.orphansQuery(db).Order("c.id ASC").Pluck("c.id", &ids).Error; err != nil {
+			return nil, err
+		}
+		if len(ids) > 0 {
+			reports = append(reports, orphanReport(r, ids))
+		}
+	}
+	return reports, nil
+}
+
+// DeleteOrphans удаляет сирот в одной транзакции и возвращает, что было удалено
+func DeleteOrphans(db *gorm.DB) ([]models.OrphanReport, error) {
+	reports := make([]models.OrphanReport, 0)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for _, r := range Relations {
+			if !tx.Migrator().HasTable(r.Table) {
+				continue
+			}
+			var ids []uint
+			if err := r.orphansQuery(tx).Order("c.id ASC").Pluck("c.id", &ids).Error; err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				continue
+			}
+			if err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE id IN ?", r.Table), ids).Error; err != nil {
+				return err
+			}
+			reports = append(reports, orphanReport(r, ids))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// RunOrphanCheck - фоновая задача: сразу и затем раз в interval пишет в лог найденных сирот.
+// Сама задача ничего не удаляет, очистка запускается вручную через API.
+func RunOrphanCheck(db *gorm.DB, interval time.Duration) {
+	for {
+		reports, err := FindOrphans(db)
+		if err != nil {
+			log.Printf("warn: orphan check failed: %v", err)
+		}
+		for _, r := range reports {
+			log.Printf("warn: %d orphaned rows in %s (%s -> %s)", r.Count, r.Table, r.Column, r.Parent)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func orphanReport(r Relation, ids []uint) models.OrphanReport {
+	return models.OrphanReport{
+		Table:  r.Table,
+		Column: r.Column,
+		Parent: r.Parent,
+		Count:  len(ids),
+		IDs:    ids,
+	}
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Действия в журнале изменений
+const (
+	AuditActionCreate  = "create"
+	AuditActionUpdate  = "update"
+	AuditActionDelete  = "delete"  // в том числе перенос в корзину
+	AuditActionRestore = "restore" // возврат из корзины
+)
+
+// AuditLog - запись журнала изменений. Журнал только дополняется: откат изменения
+// пишется новой записью с RevertOf.
+type AuditLog struct {
+	ID        uint            `json:"id" gorm:"primaryKey"`
+	ProfileID *uint           `json:"profileId" gorm:"index"`                             // пусто для общих данных, например каталога упражнений
+	Entity    string          `json:"entity" gorm:"not null;index:idx_audit_logs_entity"` // имя таблицы
+	EntityID  uint            `json:"entityId" gorm:"not null;index:idx_audit_logs_entity"`
+	Action    string          `json:"action" gorm:"not null"`
+	Before    json.RawMessage `json:"before,omitempty" gorm:"serializer:json"` // состояние до изменения, пусто для create
+	After     json.RawMessage `json:"after,omitempty" gorm:"serializer:json"`  // состояние после, пусто для окончательного удаления
+	Actor     string          `json:"actor"`
+	Source    string          `json:"source"` // метод и путь запроса или фоновая задача
+	RevertOf  *uint           `json:"revertOf,omitempty"`
+	CreatedAt time.Time       `json:"createdAt" gorm:"index"`
+}
@@ -1,13 +1,20 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type BodyWeight struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	ProfileID uint      `json:"profileId" gorm:"not null;index"`
-	Date      time.Time `json:"date"`
-	Weight    float64   `json:"weight"`
-	Notes     string    `json:"notes"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	ProfileID uint           `json:"profileId" gorm:"not null;index"`
+	Date      time.Time      `json:"date"`
+	Weight    float64        `json:"weight"`
+	Notes     string         `json:"notes"`
+	ClientID  *string        `json:"clientId" gorm:"uniqueIndex"`
+	Version   int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"deletedAt" gorm:"index"`
 }
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Типы тренировочных сессий
+const (
+	SessionTypeStrength = "strength"
+	SessionTypeCardio   = "cardio"
+)
+
+// CardioActivity - кардио-активность в рамках тренировочной сессии
+type CardioActivity struct {
+	ID                uint             `json:"id" gorm:"primaryKey"`
+	TrainingSessionID uint             `json:"trainingSessionId" gorm:"not null;index"`
+	Activity          string           `json:"activity"`     // run, row, bike, swim, hiit, other
+	Duration          int              `json:"duration"`     // длительность в секундах
+	Distance          float64          `json:"distance"`     // дистанция в метрах
+	Pace              float64          `json:"pace"`         // темп, секунд на километр
+	AvgHeartRate      int              `json:"avgHeartRate"` // средний пульс
+	MaxHeartRate      int              `json:"maxHeartRate"` // максимальный пульс
+	Calories          int              `json:"calories"`
+	Intervals         []CardioInterval `json:"intervals" gorm:"serializer:json"` // интервалы для HIIT
+	Notes             string           `json:"notes"`
+	// Данные импортированного файла активности (GPX/TCX/FIT)
+	ElevationGain    float64           `json:"elevationGain"` // набор высоты в метрах
+	HeartRateSamples []HeartRateSample `json:"heartRateSamples,omitempty" gorm:"serializer:json"`
+	SourceFile       string            `json:"sourceFile,omitempty"`
+	SourceHash       string            `json:"-" gorm:"index"` // sha256 файла для защиты от повторной загрузки
+	ImportProfileID  *uint             `json:"-"`              // профиль, загрузивший файл; с SourceHash - уникальный индекс
+	Version          int               `json:"version" gorm:"not null;default:1"`
+	CreatedAt        time.Time         `json:"createdAt"`
+	UpdatedAt        time.Time         `json:"updatedAt"`
+	DeletedAt        gorm.DeletedAt    `json:"deletedAt" gorm:"index"`
+}
+
+// CardioInterval - рабочий отрезок интервальной тренировки
+type CardioInterval struct {
+	Duration  int     `json:"duration"`           // работа в секундах
+	Rest      int     `json:"rest"`               // отдых после отрезка в секундах
+	Distance  float64 `json:"distance,omitempty"` // дистанция в метрах
+	HeartRate int     `json:"heartRate,omitempty"`
+}
+
+// HeartRateSample - замер пульса, Offset - секунды от начала активности
+type HeartRateSample struct {
+	Offset int `json:"offset"`
+	BPM    int `json:"bpm"`
+}
@@ -1,33 +1,70 @@
 package models
 
+import "time"
+
 // DTOs used by HTTP layer
 
 type OneRMRequest struct {
-	Weight     float64 `json:"weight" binding:"required,gt=0"`
-	Reps       int     `json:"reps" binding:"required,gt=0,lte=20"`
+	Weight     float64 `json:"weight" binding:"required,gt=0,lte=1000"`
+	Reps       int     `json:"reps" binding:"required,gt=0,lte=30"`
 	Percentage float64 `json:"percentage" binding:"required,gte=50,lte=100"`
-	Formula    string  `json:"formula"` // brzycki, epley, lander
+	Formula    string  `json:"formula" binding:"omitempty,oneof=brzycki epley lander mayhew oconner wathan lombardi average"`
+	RPE        float64 `json:"rpe" binding:"omitempty,gte=1,lte=10"`
+	RIR        *int    `json:"rir" binding:"omitempty,gte=0,lte=10"` // при указании важнее RPE
+	// Схема подходов: straight (по умолчанию), 5x5, 531_week1-3, pyramid, top_backoff
+	Scheme     string        `json:"scheme" binding:"omitempty,oneof=straight 5x5 531_week1 531_week2 531_week3 pyramid top_backoff"`
+	SetCount   int           `json:"setCount" binding:"omitempty,min=1,max=20"`  // рабочих подходов (для top_backoff - подходов со сбросом)
+	WarmupSets *int          `json:"warmupSets" binding:"omitempty,min=0,max=6"` // по умолчанию 3, для straight - 0
+	Increment  float64       `json:"increment" binding:"omitempty,gt=0,lte=20"`  // шаг округления веса, кг
+	ProfileID  uint          `json:"profileId"`                                  // округлять по набору дисков профиля
+	GymID      uint          `json:"gymId"`                                      // зал профиля, по умолчанию - выбранный
+	Apply      *SchemeTarget `json:"apply"`                                      // записать схему в программу или сессию
+}
+
+// SchemeTarget - куда записать схему из калькулятора: в упражнение программы (programId, dayOfWeek)
+// или в упражнение сессии (sessionId)
+type SchemeTarget struct {
+	ProfileID uint   `json:"profileId" binding:"required"`
+	Exercise  string `json:"exercise" binding:"required"`
+	ProgramID uint   `json:"programId" binding:"required_without=SessionID,excluded_with=SessionID"`
+	DayOfWeek int    `json:"dayOfWeek" binding:"required_with=ProgramID,omitempty,min=1,max=7"`
+	SessionID uint   `json:"sessionId"`
 }
 
 type SetValues struct {
-	Reps   int     `json:"reps"`
-	Weight float64 `json:"kg"`
+	Reps       int     `json:"reps"`
+	Weight     float64 `json:"kg"`
+	Type       string  `json:"type,omitempty"`       // warmup/working/amrap
+	Percentage float64 `json:"percentage,omitempty"` // процент 1ПМ
 }
 
 type OneRMResponse struct {
-	OneRM        float64     `json:"oneRM"`
-	TargetWeight float64     `json:"targetWeight"`
-	Percentage   float64     `json:"percentage"`
-	Formula      string      `json:"formula"`
-	Sets         []SetValues `json:"sets"`
+	OneRM         float64           `json:"oneRM"`
+	TargetWeight  float64           `json:"targetWeight"`
+	Percentage    float64           `json:"percentage"`
+	Formula       string            `json:"formula"`
+	EffectiveReps float64           `json:"effectiveReps"` // повторения с учетом запаса по RPE/RIR
+	Estimates     []FormulaEstimate `json:"estimates"`     // результат каждой формулы для сравнения
+	Scheme        string            `json:"scheme"`
+	Sets          []SetValues       `json:"sets"`
+	// Созданное упражнение, если схема записана через apply
+	ProgramExercise *ProgramExercise         `json:"programExercise,omitempty"`
+	SessionExercise *TrainingSessionExercise `json:"sessionExercise,omitempty"`
+}
+
+type FormulaEstimate struct {
+	Formula string  `json:"formula"`
+	OneRM   float64 `json:"oneRM"`
 }
 
 type AnalyticsResponse struct {
 	Profile            ProfileStats      `json:"profile"`
 	Progress           ProgressStats     `json:"progress"`
 	MuscleGroupBalance []MuscleGroupStat `json:"muscleGroupBalance"`
-	Recommendations    []string          `json:"recommendations"`
+	Recommendations    []Recommendation  `json:"recommendations"`
 	ExerciseStats      []ExerciseStat    `json:"exerciseStats"`
+	Conditioning       ConditioningStats `json:"conditioning"`
+	TrainingLoad       *TrainingLoadDay  `json:"trainingLoad"` // нагрузка и утомление на сегодня
 }
 
 type ProfileStats struct {
@@ -53,10 +90,32 @@ type MuscleGroupStat struct {
 }
 
 type ExerciseStat struct {
-	Exercise    string  `json:"exercise"`
-	MaxWeight   float64 `json:"maxWeight"`
-	TotalVolume float64 `json:"totalVolume"`
-	Progress    float64 `json:"progress"`
+	Exercise       string  `json:"exercise"`
+	MaxWeight      float64 `json:"maxWeight"`
+	TotalVolume    float64 `json:"totalVolume"`
+	Progress       float64 `json:"progress"`
+	EstimatedOneRM float64 `json:"estimatedOneRM"`
+	BestDuration   int     `json:"bestDuration,omitempty"` // лучшее время подхода в секундах
+	BestDistance   float64 `json:"bestDistance,omitempty"` // лучшая дистанция подхода в метрах
+}
+
+// ConditioningStats - сводка по кардио и кондиционным тренировкам
+type ConditioningStats struct {
+	TotalSessions    int            `json:"totalSessions"`
+	TotalDuration    int            `json:"totalDuration"` // секунды
+	TotalDistance    float64        `json:"totalDistance"` // метры
+	TotalCalories    int            `json:"totalCalories"`
+	AverageHeartRate float64        `json:"averageHeartRate"`
+	Activities       []ActivityStat `json:"activities"`
+}
+
+type ActivityStat struct {
+	Activity string  `json:"activity"`
+	Count    int     `json:"count"`
+	Duration int     `json:"duration"`
+	Distance float64 `json:"distance"`
+	Calories int     `json:"calories"`
+	BestPace float64 `json:"bestPace"` // секунд на км
 }
 
 type ChartDataPoint struct {
@@ -82,3 +141,253 @@ type TrainingHistoryResponse struct {
 	PageSize   int                            `json:"pageSize"`
 	HasMore    bool                           `json:"hasMore"`
 }
+
+type SyncResult struct {
+	ChangeID string      `json:"changeId,omitempty"`
+	Entity   string      `json:"entity"`
+	ID       uint        `json:"id,omitempty"`
+	ClientID string      `json:"clientId,omitempty"`
+	Status   string      `json:"status"` // applied/duplicate/conflict/rejected
+	Version  int         `json:"version,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Current  interface{} `json:"current,omitempty"` // серверная версия записи при конфликте
+}
+
+type SyncResponse struct {
+	Results []SyncResult `json:"results"`
+	Cursor  uint         `json:"cursor"` // последний курсор ленты изменений
+}
+
+type SyncFeedItem struct {
+	Cursor   uint        `json:"cursor"`
+	Entity   string      `json:"entity"`
+	ID       uint        `json:"id"`
+	ClientID *string     `json:"clientId"`
+	Op       string      `json:"op"`
+	Version  int         `json:"version"`
+	Data     interface{} `json:"data,omitempty"` // текущее состояние записи, пусто для delete
+}
+
+type SyncFeedResponse struct {
+	Changes []SyncFeedItem `json:"changes"`
+	Cursor  uint           `json:"cursor"`
+	HasMore bool           `json:"hasMore"`
+}
+
+// Problem - ошибка API в формате RFC 7807 (application/problem+json)
+type Problem struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Code     string       `json:"code"`  // машиночитаемый код ошибки
+	Error    string       `json:"error"` // то же, что detail, для клиентов старого формата
+	Errors   []FieldError `json:"errors,omitempty"`
+	Current  interface{}  `json:"current,omitempty"` // актуальная запись при 412
+}
+
+type FieldError struct {
+	Field   string `json:"field"` // путь поля в JSON, например sets[0].rpe
+	Code    string `json:"code"`  // правило проверки: required, min, oneof, datetime...
+	Message string `json:"message"`
+}
+
+// OrphanReport - записи, ссылающиеся на удаленного родителя
+type OrphanReport struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	Parent string `json:"parent"`
+	Count  int    `json:"count"`
+	IDs    []uint `json:"ids"`
+}
+
+type OrphansResponse struct {
+	Orphans []OrphanReport `json:"orphans"`
+	Total   int            `json:"total"`
+	Deleted bool           `json:"deleted"` // true, если записи удалены
+}
+
+// TrashItem - запись в корзине профиля
+type TrashItem struct {
+	Type      string    `json:"type"` // session, program, body_weight, goal, personal_record, session_exercise...
+	ID        uint      `json:"id"`
+	Title     string    `json:"title"`
+	DeletedAt time.Time `json:"deletedAt"`
+	PurgeAt   time.Time `json:"purgeAt"` // после этого момента запись удаляется насовсем
+}
+
+type TrashResponse struct {
+	Items     []TrashItem `json:"items"`
+	Retention int         `json:"retentionDays"`
+}
+
+type AuditLogResponse struct {
+	Entries []AuditLog `json:"entries"`
+	HasMore bool       `json:"hasMore"`
+	Before  uint       `json:"before,omitempty"` // курсор следующей страницы: id последней записи
+}
+
+// OneRMPoint - лучшая оценка 1ПМ в упражнении за сессию
+type OneRMPoint struct {
+	Date           string  `json:"date"`
+	SessionID      uint    `json:"sessionId"`
+	EstimatedOneRM float64 `json:"estimatedOneRM"`
+	Weight         float64 `json:"weight"` // нагрузка подхода, по которому получена оценка
+	Reps           int     `json:"reps"`
+	RPE            float64 `json:"rpe,omitempty"`
+}
+
+type OneRMHistory struct {
+	Exercise    string       `json:"exercise"`
+	Current     float64      `json:"current"`     // лучший результат за последние 6 недель тренировок
+	CurrentDate string       `json:"currentDate"` // дата сессии, давшей текущий 1ПМ
+	Best        float64      `json:"best"`
+	Points      []OneRMPoint `json:"points"`
+}
+
+type OneRMHistoryResponse struct {
+	Formula     string         `json:"formula"`
+	MaxReps     int            `json:"maxReps"`
+	RPEAdjusted bool           `json:"rpeAdjusted"`
+	Exercises   []OneRMHistory `json:"exercises"`
+}
+
+// PlateLoad - раскладка дисков для веса на штанге
+type PlateLoad struct {
+	Unit           string       `json:"unit"`
+	TargetWeight   float64      `json:"targetWeight"`
+	BarWeight      float64      `json:"barWeight"`
+	CollarWeight   float64      `json:"collarWeight"` // оба замка
+	AchievedWeight float64      `json:"achievedWeight"`
+	Difference     float64      `json:"difference"` // achievedWeight - targetWeight
+	Exact          bool         `json:"exact"`
+	PerSide        []PlateCount `json:"perSide"` // от тяжелых к легким
+}
+
+// StrengthLift - лучший результат в соревновательном движении и уровень по нормативам
+type StrengthLift struct {
+	Lift            string  `json:"lift"` // squat/bench/deadlift
+	Exercise        string  `json:"exercise"`
+	OneRM           float64 `json:"oneRM"`
+	Estimated       bool    `json:"estimated"` // 1ПМ рассчитан по подходу на несколько повторений
+	Date            string  `json:"date"`
+	BodyWeight      float64 `json:"bodyWeight"` // ближайшее к дате взвешивание
+	Ratio           float64 `json:"ratio"`      // 1ПМ / вес тела
+	Level           string  `json:"level"`      // untrained/beginner/novice/intermediate/advanced/elite
+	NextLevel       string  `json:"nextLevel,omitempty"`
+	NextLevelWeight float64 `json:"nextLevelWeight,omitempty"`
+	Wilks           float64 `json:"wilks"`
+	DOTS            float64 `json:"dots"`
+	IPFPoints       float64 `json:"ipfPoints,omitempty"` // IPF GL, только для жима
+}
+
+// StrengthTotal - сумма троеборья и очки за нее
+type StrengthTotal struct {
+	Total      float64 `json:"total"`
+	Date       string  `json:"date"`       // дата последнего из трех результатов
+	BodyWeight float64 `json:"bodyWeight"` // ближайшее к этой дате взвешивание
+	Wilks      float64 `json:"wilks"`
+	DOTS       float64 `json:"dots"`
+	IPFPoints  float64 `json:"ipfPoints"`
+}
+
+type StrengthResponse struct {
+	Gender string         `json:"gender"`
+	Source string         `json:"source"` // pr/e1rm
+	Lifts  []StrengthLift `json:"lifts"`
+	Total  *StrengthTotal `json:"total"` // nil, пока нет результата во всех трех движениях
+}
+
+// TrainingLoadDay - нагрузка за день и показатели утомления на этот день
+type TrainingLoadDay struct {
+	Date        string   `json:"date"`
+	Sessions    int      `json:"sessions"`
+	VolumeLoad  float64  `json:"volumeLoad"`  // сумма вес × повторения рабочих подходов
+	SessionLoad float64  `json:"sessionLoad"` // session RPE × минуты
+	Load        float64  `json:"load"`        // нагрузка по выбранной метрике
+	Acute       float64  `json:"acute"`       // средняя дневная нагрузка за 7 дней
+	Chronic     float64  `json:"chronic"`     // средняя дневная нагрузка за 28 дней
+	ACWR        *float64 `json:"acwr"`        // acute / chronic; nil, пока истории меньше 28 дней
+	Monotony    *float64 `json:"monotony"`    // монотонность по Фостеру: среднее / стандартное отклонение за 7 дней
+	Strain      float64  `json:"strain"`      // напряжение: нагрузка за 7 дней × монотонность
+}
+
+type TrainingLoadResponse struct {
+	Metric   string            `json:"metric"` // srpe/volume
+	Days     []TrainingLoadDay `json:"days"`
+	Current  *TrainingLoadDay  `json:"current"`  // последний день ряда, nil без тренировок
+	Warnings []Recommendation  `json:"warnings"` // сработавшие правила категории load
+}
+
+// ReadinessCorrelation - связь показателя готовности с результатом тренировки
+type ReadinessCorrelation struct {
+	Metric      string   `json:"metric"`      // e1rmChange/volume
+	Coefficient *float64 `json:"coefficient"` // коэффициент корреляции Пирсона; nil, если данных мало
+	Samples     int      `json:"samples"`
+	Strength    string   `json:"strength"` // none/weak/moderate/strong
+}
+
+// ReadinessFactor - показатель готовности и его связь с результатами
+type ReadinessFactor struct {
+	Factor       string                 `json:"factor"` // sleepHours/sleepQuality/stress/hrv/restingHeartRate/energy/mood/soreness
+	Average      float64                `json:"average"`
+	Correlations []ReadinessCorrelation `json:"correlations"`
+}
+
+type ReadinessAnalysisResponse struct {
+	Sessions int               `json:"sessions"` // тренировок с результатом для сравнения
+	Factors  []ReadinessFactor `json:"factors"`  // по убыванию силы связи
+	Insights []Recommendation  `json:"insights"` // правила категории readiness
+}
+
+// ExerciseTrend - динамика расчетного 1ПМ и объема упражнения за последние тренировки
+type ExerciseTrend struct {
+	Exercise        string   `json:"exercise"`
+	Status          string   `json:"status"` // progressing/stalled/declining/insufficient_data
+	Sessions        int      `json:"sessions"`
+	LastDate        string   `json:"lastDate"`
+	BestOneRM       float64  `json:"bestOneRM"`              // лучший 1ПМ до последних N тренировок
+	RecentOneRM     float64  `json:"recentOneRM"`            // лучший 1ПМ за последние N тренировок
+	OneRMChange     float64  `json:"oneRMChange"`            // RecentOneRM к BestOneRM, %
+	VolumeChange    float64  `json:"volumeChange"`           // средний объем последних N тренировок к предыдущим N, %
+	SessionsSincePR int      `json:"sessionsSincePR"`        // тренировок подряд без нового лучшего 1ПМ
+	AverageReps     float64  `json:"averageReps"`            // среднее число повторений в последних N тренировках
+	Alternatives    []string `json:"alternatives,omitempty"` // чем заменить застрявшее упражнение
+}
+
+// FatigueStatus - признаки накопленной усталости
+type FatigueStatus struct {
+	Level              string   `json:"level"`   // low/moderate/high
+	Signals            []string `json:"signals"` // declining_lifts/load_spike/monotony/high_rpe
+	StalledExercises   int      `json:"stalledExercises"`
+	DecliningExercises int      `json:"decliningExercises"`
+	ACWR               *float64 `json:"acwr"`
+	Monotony           *float64 `json:"monotony"`
+	RecentRPE          float64  `json:"recentRPE"` // средний RPE подходов за последние 14 дней, 0 - нет данных
+}
+
+// Recommendation - сработавшее правило рекомендаций и данные, на которых оно основано
+type Recommendation struct {
+	ID       string                 `json:"id"`                 // правило, например load.acwr_spike
+	Severity string                 `json:"severity"`           // info/warning/critical
+	Category string                 `json:"category"`           // load/progression/body/balance/frequency/variety/goal/readiness/general
+	Exercise string                 `json:"exercise,omitempty"` // пусто - рекомендация для всего профиля
+	Message  string                 `json:"message"`
+	Evidence map[string]interface{} `json:"evidence"`
+}
+
+// RecommendationRule - правило рекомендаций и его состояние в профиле
+type RecommendationRule struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+	Category string `json:"category"`
+	Enabled  bool   `json:"enabled"`
+}
+
+type ProgressionResponse struct {
+	StallSessions   int              `json:"stallSessions"` // N: тренировок без прироста 1ПМ, после которых упражнение считается застрявшим
+	Exercises       []ExerciseTrend  `json:"exercises"`
+	Fatigue         FatigueStatus    `json:"fatigue"`
+	Recommendations []Recommendation `json:"recommendations"`
+}
@@ -1,5 +1,13 @@
 package models
 
+// Метрики, которые может отслеживать упражнение
+const (
+	MetricWeight   = "weight"
+	MetricReps     = "reps"
+	MetricDuration = "duration"
+	MetricDistance = "distance"
+)
+
 type Exercise struct {
 	ID          uint   `json:"id" gorm:"primaryKey"`
 	Name        string `json:"name" gorm:"uniqueIndex;not null"`
@@ -7,4 +15,32 @@ type Exercise struct {
 	Category    string `json:"category"`
 	MuscleGroup string `json:"muscleGroup"`
 	IsCustom    bool   `json:"isCustom" gorm:"default:false"`
+	// Упражнения с собственным весом (подтягивания, брусья, отжимания)
+	IsBodyweight    bool    `json:"isBodyweight" gorm:"default:false"`
+	BodyweightRatio float64 `json:"bodyweightRatio" gorm:"default:1"` // доля веса тела, которую поднимает атлет
+	// Метрики подхода: weight, reps, duration, distance. Пусто - вес и повторения
+	Metrics []string `json:"metrics" gorm:"serializer:json" binding:"omitempty,dive,oneof=weight reps duration distance"`
+	// Необходимое оборудование: barbell, dumbbell, cable, machine... Пусто - ничего не нужно
+	Equipment []string `json:"equipment" gorm:"serializer:json" binding:"omitempty,dive,oneof=barbell dumbbell cable machine smith pullup_bar dip_bars cardio"`
+}
+
+// TrackedMetrics возвращает метрики упражнения с учетом значений по умолчанию
+func (e Exercise) TrackedMetrics() []string {
+	if len(e.Metrics) > 0 {
+		return e.Metrics
+	}
+	if e.IsBodyweight {
+		return []string{MetricReps}
+	}
+	return []string{MetricWeight, MetricReps}
+}
+
+// Tracks сообщает, отслеживает ли упражнение указанную метрику
+func (e Exercise) Tracks(metric string) bool {
+	for _, m := range e.TrackedMetrics() {
+		if m == metric {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Оборудование, которое требуется упражнениям. Штанга и гантели есть в зале,
+// если в нем указаны грифы и гантели, остальное перечисляется в Machines.
+const (
+	EquipmentBarbell   = "barbell"
+	EquipmentDumbbell  = "dumbbell"
+	EquipmentCable     = "cable"      // блоки и кроссовер
+	EquipmentMachine   = "machine"    // рычажные и блочные тренажеры
+	EquipmentSmith     = "smith"      // тренажер Смита
+	EquipmentPullupBar = "pullup_bar" // турник
+	EquipmentDipBars   = "dip_bars"   // брусья
+	EquipmentCardio    = "cardio"     // беговая дорожка, гребной тренажер, велотренажер
+)
+
+// Gym - зал профиля и оборудование в нем
+type Gym struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	ProfileID  uint   `json:"profileId" gorm:"not null;index"`
+	Name       string `json:"name" gorm:"not null"`
+	IsSelected bool   `json:"isSelected" gorm:"default:false"` // зал, в котором атлет тренируется сейчас
+	// Штанга: грифы, замки и диски в единицах Unit
+	Unit         string       `json:"unit" gorm:"default:kg"`
+	Bars         []GymBar     `json:"bars" gorm:"serializer:json"`
+	CollarWeight float64      `json:"collarWeight"`
+	Plates       []PlateStock `json:"plates" gorm:"serializer:json"`
+	// Гантели от DumbbellMin до DumbbellMax с шагом DumbbellIncrement, 0 - гантелей нет
+	DumbbellMin       float64        `json:"dumbbellMin"`
+	DumbbellMax       float64        `json:"dumbbellMax"`
+	DumbbellIncrement float64        `json:"dumbbellIncrement"`
+	Machines          []string       `json:"machines" gorm:"serializer:json"` // cable, machine, smith, pullup_bar, dip_bars, cardio и свои названия
+	Notes             string         `json:"notes"`
+	Version           int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt         time.Time      `json:"createdAt"`
+	UpdatedAt         time.Time      `json:"updatedAt"`
+	DeletedAt         gorm.DeletedAt `json:"deletedAt" gorm:"index"`
+}
+
+// GymBar - гриф в зале
+type GymBar struct {
+	Name   string  `json:"name" binding:"required"`
+	Weight float64 `json:"weight" binding:"required,gt=0,lte=100"`
+}
+
+// Has сообщает, есть ли в зале оборудование
+func (g Gym) Has(equipment string) bool {
+	switch equipment {
+	case EquipmentBarbell:
+		return len(g.Bars) > 0
+	case EquipmentDumbbell:
+		return g.DumbbellMax > 0
+	}
+	for _, m := range g.Machines {
+		if m == equipment {
+			return true
+		}
+	}
+	return false
+}
+
+// CanPerform сообщает, хватает ли оборудования зала для упражнения
+func (g Gym) CanPerform(exercise Exercise) bool {
+	for _, equipment := range exercise.Equipment {
+		if !g.Has(equipment) {
+			return false
+		}
+	}
+	return true
+}
+
+// PlateInventory - набор для калькулятора дисков: первый гриф зала, его замки и диски
+func (g Gym) PlateInventory() PlateInventory {
+	inventory := PlateInventory{Unit: g.Unit, CollarWeight: g.CollarWeight, Plates: g.Plates}
+	if len(g.Bars) > 0 {
+		inventory.BarWeight = g.Bars[0].Weight
+	}
+	return inventory
+}
@@ -0,0 +1,27 @@
+package models
+
+// Единицы веса дисков
+const (
+	UnitKg = "kg"
+	UnitLb = "lb"
+)
+
+// PlateInventory - гриф, замки и диски, которыми располагает атлет
+type PlateInventory struct {
+	Unit         string       `json:"unit" binding:"omitempty,oneof=kg lb"`          // kg/lb, пусто - kg
+	BarWeight    float64      `json:"barWeight" binding:"omitempty,gt=0,lte=100"`    // вес грифа
+	CollarWeight float64      `json:"collarWeight" binding:"omitempty,gte=0,lte=10"` // вес одного замка
+	Plates       []PlateStock `json:"plates" binding:"omitempty,max=30,dive"`        // пусто - стандартный набор
+}
+
+// PlateStock - диски одного веса
+type PlateStock struct {
+	Weight float64 `json:"weight" binding:"required,gt=0,lte=50"` // допускаются дробные диски (0.5, 0.25)
+	Pairs  int     `json:"pairs" binding:"required,min=1,max=20"` // пар дисков, на каждую сторону - по одному из пары
+}
+
+// PlateCount - сколько дисков одного веса вешать на сторону
+type PlateCount struct {
+	Weight float64 `json:"weight"`
+	Count  int     `json:"count"`
+}
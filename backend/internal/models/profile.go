@@ -1,19 +1,29 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type Profile struct {
 	ID   uint   `json:"id" gorm:"primaryKey"`
-	Name string `json:"name" gorm:"not null"`
+	Name string `json:"name" gorm:"not null" binding:"required"`
 	// Личные параметры
-	Age    *int     `json:"age"`    // Возраст
-	Gender string   `json:"gender"` // male/female/other
-	Weight *float64 `json:"weight"` // Вес в кг
-	Height *int     `json:"height"` // Рост в см
-	Goal   string   `json:"goal"`   // strength/mass/endurance/weight_loss
+	Age    *int     `json:"age" binding:"omitempty,min=1,max=120"`                              // Возраст
+	Gender string   `json:"gender" binding:"omitempty,oneof=male female other"`                 // male/female/other
+	Weight *float64 `json:"weight" binding:"omitempty,gt=0,lte=500"`                            // Вес в кг
+	Height *int     `json:"height" binding:"omitempty,min=50,max=272"`                          // Рост в см
+	Goal   string   `json:"goal" binding:"omitempty,oneof=strength mass endurance weight_loss"` // strength/mass/endurance/weight_loss
 	// Дополнительные параметры
-	Experience string    `json:"experience"`             // beginner/intermediate/advanced
-	Notes      string    `json:"notes" gorm:"type:text"` // Заметки
-	CreatedAt  time.Time `json:"createdAt"`
-	UpdatedAt  time.Time `json:"updatedAt"`
+	Experience string `json:"experience" binding:"omitempty,oneof=beginner intermediate advanced"` // beginner/intermediate/advanced
+	Notes      string `json:"notes" gorm:"type:text"`                                              // Заметки
+	// Набор дисков для калькулятора загрузки штанги, nil - стандартный набор в кг
+	Plates *PlateInventory `json:"plates" gorm:"serializer:json"`
+	// Правила рекомендаций, отключенные в профиле
+	DisabledRecommendations []string       `json:"disabledRecommendations" gorm:"serializer:json"`
+	Version                 int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt               time.Time      `json:"createdAt"`
+	UpdatedAt               time.Time      `json:"updatedAt"`
+	DeletedAt               gorm.DeletedAt `json:"deletedAt" gorm:"index"`
 }
@@ -1,41 +1,53 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type TrainingProgram struct {
-	ID          uint      `json:"id" gorm:"primaryKey"`
-	ProfileID   uint      `json:"profileId" gorm:"not null;index"`
-	Name        string    `json:"name" gorm:"not null"`
-	Description string    `json:"description"`
-	StartDate   time.Time `json:"startDate"`
-	EndDate     time.Time `json:"endDate"`
-	IsActive    bool      `json:"isActive" gorm:"default:false"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          uint           `json:"id" gorm:"primaryKey"`
+	ProfileID   uint           `json:"profileId" gorm:"not null;index"`
+	Name        string         `json:"name" gorm:"not null"`
+	Description string         `json:"description"`
+	StartDate   time.Time      `json:"startDate"`
+	EndDate     time.Time      `json:"endDate"`
+	IsActive    bool           `json:"isActive" gorm:"default:false"`
+	Version     int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt `json:"deletedAt" gorm:"index"`
 }
 
 type ProgramExercise struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	ProgramID uint      `json:"programId" gorm:"not null;index"`
-	Exercise  string    `json:"exercise" gorm:"not null"`
-	DayOfWeek int       `json:"dayOfWeek" gorm:"not null"`              // 1-7 (понедельник-воскресенье)
-	Order     int       `json:"order" gorm:"column:\"order\";not null"` // порядок в дне
-	Sets      int       `json:"sets" gorm:"not null"`
-	Reps      int       `json:"reps" gorm:"not null"`
-	Weight    float64   `json:"weight" gorm:"not null"`
-	Notes     string    `json:"notes"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	ProgramID uint           `json:"programId" gorm:"not null;index"`
+	Exercise  string         `json:"exercise" gorm:"not null"`
+	DayOfWeek int            `json:"dayOfWeek" gorm:"not null"`              // 1-7 (понедельник-воскресенье)
+	Order     int            `json:"order" gorm:"column:\"order\";not null"` // порядок в дне
+	GroupID   string         `json:"groupId"`                                // общий идентификатор суперсета/круга
+	GroupType string         `json:"groupType"`                              // superset/circuit
+	Sets      int            `json:"sets" gorm:"not null"`
+	Reps      int            `json:"reps" gorm:"not null"`
+	Weight    float64        `json:"weight" gorm:"not null"`
+	Notes     string         `json:"notes"`
+	Version   int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"deletedAt" gorm:"index"`
 }
 
 type ProgramSession struct {
-	ID        uint      `json:"id" gorm:"primaryKey"`
-	ProgramID uint      `json:"programId" gorm:"not null;index"`
-	Date      time.Time `json:"date" gorm:"not null"`
-	Completed bool      `json:"completed" gorm:"default:false"`
-	Notes     string    `json:"notes"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	ProgramID uint           `json:"programId" gorm:"not null;index"`
+	Date      time.Time      `json:"date" gorm:"not null"`
+	Completed bool           `json:"completed" gorm:"default:false"`
+	Notes     string         `json:"notes"`
+	Version   int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"deletedAt" gorm:"index"`
 }
 
 type ProgramSessionWithExercises struct {
@@ -46,4 +58,14 @@ type ProgramSessionWithExercises struct {
 type PlanDay struct {
 	Date      string            `json:"date"`
 	Exercises []ProgramExercise `json:"exercises"`
+	Groups    []ExerciseGroup   `json:"groups"`
+	// Упражнения дня, для которых в зале нет оборудования
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+// ExerciseGroup - суперсет, круг или одиночное упражнение (пустой GroupID) в плане дня
+type ExerciseGroup struct {
+	GroupID   string            `json:"groupId"`
+	GroupType string            `json:"groupType"`
+	Exercises []ProgramExercise `json:"exercises"`
 }
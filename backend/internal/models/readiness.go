@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReadinessCheckIn - ежедневная оценка готовности к тренировке.
+// Нулевые значения означают, что показатель не указан.
+type ReadinessCheckIn struct {
+	ID               uint           `json:"id" gorm:"primaryKey"`
+	ProfileID        uint           `json:"profileId" gorm:"not null;index"`
+	Date             time.Time      `json:"date" gorm:"index"`
+	SleepHours       float64        `json:"sleepHours"`       // сон, часов
+	SleepQuality     int            `json:"sleepQuality"`     // качество сна 1-10
+	Stress           int            `json:"stress"`           // стресс 1-10
+	HRV              float64        `json:"hrv"`              // вариабельность пульса (RMSSD), мс
+	RestingHeartRate int            `json:"restingHeartRate"` // пульс в покое, уд/мин
+	Notes            string         `json:"notes"`
+	Version          int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt        time.Time      `json:"createdAt"`
+	UpdatedAt        time.Time      `json:"updatedAt"`
+	DeletedAt        gorm.DeletedAt `json:"deletedAt" gorm:"index"`
+}
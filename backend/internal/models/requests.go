@@ -1,18 +1,31 @@
 package models
 
+import "encoding/json"
+
 // Request DTOs
 
 type BodyWeightRequest struct {
 	Weight float64 `json:"weight" binding:"required,gt=0"`
 	Notes  string  `json:"notes"`
-	Date   string  `json:"date"` // ISO date string
+	Date   string  `json:"date" binding:"omitempty,datetime=2006-01-02"` // ISO date string
+}
+
+// ReadinessCheckInRequest - запрос на создание/обновление оценки готовности
+type ReadinessCheckInRequest struct {
+	Date             string  `json:"date" binding:"omitempty,datetime=2006-01-02"` // ISO date string
+	SleepHours       float64 `json:"sleepHours" binding:"omitempty,gt=0,lte=24"`
+	SleepQuality     int     `json:"sleepQuality" binding:"omitempty,min=1,max=10"`       // 1-10
+	Stress           int     `json:"stress" binding:"omitempty,min=1,max=10"`             // 1-10
+	HRV              float64 `json:"hrv" binding:"omitempty,gt=0,lte=300"`                // мс
+	RestingHeartRate int     `json:"restingHeartRate" binding:"omitempty,min=20,max=150"` // уд/мин
+	Notes            string  `json:"notes" binding:"max=1000"`
 }
 
 type PersonalRecordRequest struct {
 	Exercise string  `json:"exercise" binding:"required"`
 	Weight   float64 `json:"weight" binding:"required,gt=0"`
 	Reps     int     `json:"reps" binding:"required,gt=0"`
-	Date     string  `json:"date"` // ISO date string
+	Date     string  `json:"date" binding:"omitempty,datetime=2006-01-02"` // ISO date string
 }
 
 type GoalRequest struct {
@@ -22,7 +35,7 @@ type GoalRequest struct {
 	Exercise    string  `json:"exercise"` // для целей по упражнениям
 	TargetValue float64 `json:"targetValue" binding:"required,gt=0"`
 	Unit        string  `json:"unit"`
-	TargetDate  string  `json:"targetDate"` // ISO date string
+	TargetDate  string  `json:"targetDate" binding:"omitempty,datetime=2006-01-02"` // ISO date string
 }
 
 type GoalProgressRequest struct {
@@ -30,19 +43,21 @@ type GoalProgressRequest struct {
 }
 
 type TrainingSessionRequest struct {
-	Date     string `json:"date"`     // ISO date string
-	Duration int    `json:"duration"` // в минутах
-	Notes    string `json:"notes"`
-	Energy   int    `json:"energy"`   // 1-10
-	Mood     int    `json:"mood"`     // 1-10
-	Soreness int    `json:"soreness"` // 1-10
+	Type     string  `json:"type" binding:"omitempty,oneof=strength cardio"`
+	Date     string  `json:"date" binding:"omitempty,datetime=2006-01-02"` // ISO date string
+	Duration int     `json:"duration" binding:"min=0"`                     // в минутах
+	Notes    string  `json:"notes"`
+	Energy   int     `json:"energy" binding:"omitempty,min=1,max=10"`   // 1-10
+	Mood     int     `json:"mood" binding:"omitempty,min=1,max=10"`     // 1-10
+	Soreness int     `json:"soreness" binding:"omitempty,min=1,max=10"` // 1-10
+	RPE      float64 `json:"rpe" binding:"omitempty,min=1,max=10"`      // session RPE 1-10
 }
 
 type TrainingProgramRequest struct {
 	Name        string `json:"name" binding:"required"`
 	Description string `json:"description"`
-	StartDate   string `json:"startDate"` // ISO date string
-	EndDate     string `json:"endDate"`   // ISO date string
+	StartDate   string `json:"startDate" binding:"omitempty,datetime=2006-01-02"` // ISO date string
+	EndDate     string `json:"endDate" binding:"omitempty,datetime=2006-01-02"`   // ISO date string
 	IsActive    bool   `json:"isActive"`
 }
 
@@ -52,12 +67,114 @@ type ProgramExerciseRequest struct {
 	Order     int     `json:"order" binding:"required,min=1"`
 	Sets      int     `json:"sets" binding:"required,min=1"`
 	Reps      int     `json:"reps" binding:"required,min=1"`
-	Weight    float64 `json:"weight" binding:"min=0"`
+	Weight    float64 `json:"weight" binding:"min=0,lte=1000"`
 	Notes     string  `json:"notes"`
+	GroupID   string  `json:"groupId"`
+	GroupType string  `json:"groupType" binding:"omitempty,oneof=superset circuit"`
+	// Округлить вес до ближайшего, который собирается набором дисков профиля
+	RoundToPlates bool `json:"roundToPlates"`
 }
 
 type ProgramSessionRequest struct {
-	Date      string `json:"date"` // ISO date string
+	Date      string `json:"date" binding:"omitempty,datetime=2006-01-02"` // ISO date string
 	Completed bool   `json:"completed"`
 	Notes     string `json:"notes"`
 }
+
+type CardioActivityRequest struct {
+	Activity     string           `json:"activity" binding:"required,oneof=run row bike swim hiit other"`
+	Duration     int              `json:"duration" binding:"required,gt=0"` // в секундах
+	Distance     float64          `json:"distance" binding:"gte=0"`         // в метрах
+	Pace         float64          `json:"pace" binding:"gte=0"`             // секунд на км, рассчитывается если не указан
+	AvgHeartRate int              `json:"avgHeartRate" binding:"gte=0,lte=250"`
+	MaxHeartRate int              `json:"maxHeartRate" binding:"gte=0,lte=250"`
+	Calories     int              `json:"calories" binding:"gte=0"`
+	Intervals    []CardioInterval `json:"intervals"`
+	Notes        string           `json:"notes"`
+}
+
+type SessionExerciseRequest struct {
+	Exercise  string `json:"exercise" binding:"required"`
+	Order     int    `json:"order" binding:"min=0"` // 0 - добавить в конец
+	GroupID   string `json:"groupId"`
+	GroupType string `json:"groupType" binding:"omitempty,oneof=superset circuit"`
+	Sets      []Set  `json:"sets"`
+	Notes     string `json:"notes"`
+}
+
+// ReorderRequest - новый порядок и группировка упражнений
+type ReorderRequest struct {
+	Items []ReorderItem `json:"items" binding:"required,min=1,dive"`
+}
+
+type ReorderItem struct {
+	ID        uint   `json:"id" binding:"required"`
+	Order     int    `json:"order" binding:"required,min=1"`
+	GroupID   string `json:"groupId"`
+	GroupType string `json:"groupType" binding:"omitempty,oneof=superset circuit"`
+	Version   int    `json:"version" binding:"min=0"` // версия, которую видел клиент; 0 - без проверки
+}
+
+type StartWorkoutRequest struct {
+	Type  string `json:"type" binding:"omitempty,oneof=strength cardio"`
+	Notes string `json:"notes"`
+}
+
+// WorkoutSetRequest - выполненный подход в живой тренировке
+type WorkoutSetRequest struct {
+	Exercise  string `json:"exercise" binding:"required"`
+	GroupID   string `json:"groupId"`
+	GroupType string `json:"groupType" binding:"omitempty,oneof=superset circuit"`
+	Set       Set    `json:"set"`
+}
+
+type FinishWorkoutRequest struct {
+	Notes    string  `json:"notes"`
+	Energy   int     `json:"energy" binding:"omitempty,min=1,max=10"`   // 1-10
+	Mood     int     `json:"mood" binding:"omitempty,min=1,max=10"`     // 1-10
+	Soreness int     `json:"soreness" binding:"omitempty,min=1,max=10"` // 1-10
+	RPE      float64 `json:"rpe" binding:"omitempty,min=1,max=10"`      // session RPE 1-10
+}
+
+type SyncRequest struct {
+	Changes []SyncChangeRequest `json:"changes" binding:"required,max=500,dive"`
+}
+
+// SyncChangeRequest - одно изменение из офлайн-очереди клиента.
+// Запись ищется по id или clientId; для создания нужен clientId.
+type SyncChangeRequest struct {
+	ChangeID    string          `json:"changeId" binding:"omitempty,uuid"`
+	Entity      string          `json:"entity" binding:"required,oneof=training_session session_exercise body_weight"`
+	Op          string          `json:"op" binding:"required,oneof=upsert delete"`
+	ID          uint            `json:"id"`
+	ClientID    string          `json:"clientId" binding:"omitempty,uuid"`
+	BaseVersion int             `json:"baseVersion" binding:"min=0"` // 0 - новая запись
+	Data        json.RawMessage `json:"data"`
+}
+
+// SyncSessionExerciseData - данные упражнения сессии; сессия указывается по id или clientId
+type SyncSessionExerciseData struct {
+	SessionID       uint   `json:"sessionId"`
+	SessionClientID string `json:"sessionClientId" binding:"omitempty,uuid"`
+	SessionExerciseRequest
+}
+
+// GymRequest - запрос на создание/обновление зала
+type GymRequest struct {
+	Name              string       `json:"name" binding:"required,max=100"`
+	IsSelected        bool         `json:"isSelected"`
+	Unit              string       `json:"unit" binding:"omitempty,oneof=kg lb"`
+	Bars              []GymBar     `json:"bars" binding:"omitempty,max=10,dive"`
+	CollarWeight      float64      `json:"collarWeight" binding:"omitempty,gte=0,lte=10"`
+	Plates            []PlateStock `json:"plates" binding:"omitempty,max=30,dive"`
+	DumbbellMin       float64      `json:"dumbbellMin" binding:"omitempty,gt=0,lte=100"`
+	DumbbellMax       float64      `json:"dumbbellMax" binding:"omitempty,gt=0,lte=200,gtefield=DumbbellMin"`
+	DumbbellIncrement float64      `json:"dumbbellIncrement" binding:"required_with=DumbbellMax,omitempty,gt=0,lte=10"`
+	Machines          []string     `json:"machines" binding:"omitempty,max=50,dive,required,max=50"`
+	Notes             string       `json:"notes"`
+}
+
+// RecommendationRuleRequest - включение/отключение правила рекомендаций в профиле
+type RecommendationRuleRequest struct {
+	Enabled *bool `json:"enabled" binding:"required"`
+}
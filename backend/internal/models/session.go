@@ -1,31 +1,72 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Статусы тренировочной сессии
+const (
+	SessionStatusInProgress = "in_progress"
+	SessionStatusPaused     = "paused"
+	SessionStatusCompleted  = "completed"
+)
 
 type TrainingSession struct {
 	ID        uint      `json:"id" gorm:"primaryKey"`
 	ProfileID uint      `json:"profileId" gorm:"not null;index"`
+	Type      string    `json:"type" gorm:"default:strength"` // strength/cardio
 	Date      time.Time `json:"date"`
 	Duration  int       `json:"duration"` // длительность в минутах
 	Notes     string    `json:"notes"`    // заметки о тренировке
 	Energy    int       `json:"energy"`   // энергия 1-10
 	Mood      int       `json:"mood"`     // настроение 1-10
 	Soreness  int       `json:"soreness"` // болезненность 1-10
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	RPE       float64   `json:"rpe"`      // субъективная тяжесть всей тренировки (session RPE) 1-10, 0 - не указана
+	// Живая тренировка
+	Status      string         `json:"status" gorm:"default:completed;index"` // in_progress/paused/completed
+	StartedAt   *time.Time     `json:"startedAt"`
+	FinishedAt  *time.Time     `json:"finishedAt"`
+	Pauses      []WorkoutPause `json:"pauses,omitempty" gorm:"serializer:json"`
+	RestSeconds int            `json:"restSeconds"` // суммарный отдых между подходами
+	// Синхронизация
+	ClientID  *string        `json:"clientId" gorm:"uniqueIndex"` // UUID, созданный клиентом офлайн
+	Version   int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"deletedAt" gorm:"index"` // заполнено, пока запись в корзине
+}
+
+// WorkoutPause - пауза в живой тренировке, End пустой пока пауза не закончилась
+type WorkoutPause struct {
+	Start time.Time  `json:"start"`
+	End   *time.Time `json:"end,omitempty"`
+}
+
+// IsActive сообщает, что тренировка еще не завершена
+func (s TrainingSession) IsActive() bool {
+	return s.Status == SessionStatusInProgress || s.Status == SessionStatusPaused
 }
 
 type TrainingSessionExercise struct {
-	ID                uint      `json:"id" gorm:"primaryKey"`
-	TrainingSessionID uint      `json:"trainingSessionId" gorm:"not null;index"`
-	Exercise          string    `json:"exercise"`
-	Sets              []Set     `json:"sets" gorm:"serializer:json"`
-	Notes             string    `json:"notes"`
-	CreatedAt         time.Time `json:"createdAt"`
-	UpdatedAt         time.Time `json:"updatedAt"`
+	ID                uint           `json:"id" gorm:"primaryKey"`
+	TrainingSessionID uint           `json:"trainingSessionId" gorm:"not null;index"`
+	Exercise          string         `json:"exercise"`
+	Order             int            `json:"order" gorm:"column:\"order\";not null;default:0"` // порядок в сессии
+	GroupID           string         `json:"groupId"`                                          // общий идентификатор суперсета/круга
+	GroupType         string         `json:"groupType"`                                        // superset/circuit
+	Sets              []Set          `json:"sets" gorm:"serializer:json"`
+	Notes             string         `json:"notes"`
+	ClientID          *string        `json:"clientId" gorm:"uniqueIndex"`
+	Version           int            `json:"version" gorm:"not null;default:1"`
+	CreatedAt         time.Time      `json:"createdAt"`
+	UpdatedAt         time.Time      `json:"updatedAt"`
+	DeletedAt         gorm.DeletedAt `json:"deletedAt" gorm:"index"`
 }
 
 type TrainingSessionWithExercises struct {
 	TrainingSession
-	Exercises []TrainingSessionExercise `json:"exercises"`
+	Exercises  []TrainingSessionExercise `json:"exercises"`
+	Activities []CardioActivity          `json:"activities"`
 }
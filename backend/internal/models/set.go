@@ -1,8 +1,36 @@
 package models
 
+import "time"
+
+// Типы подходов
+const (
+	SetTypeWarmup  = "warmup"
+	SetTypeWorking = "working"
+	SetTypeDrop    = "drop"
+	SetTypeAMRAP   = "amrap"
+	SetTypeCluster = "cluster"
+	SetTypeFailure = "failure"
+)
+
 // Set - подход в упражнении
 type Set struct {
-	Weight float64 `json:"weight"`
-	Reps   int     `json:"reps"`
-	RPE    int     `json:"rpe"` // Rate of Perceived Exertion 1-10
+	Weight     float64 `json:"weight"` // для упражнений с собственным весом - дополнительный вес
+	Reps       int     `json:"reps"`
+	RPE        float64 `json:"rpe"`                  // Rate of Perceived Exertion 1-10 с шагом 0.5
+	RIR        *int    `json:"rir,omitempty"`        // Reps in Reserve - повторения в запасе
+	Type       string  `json:"type,omitempty"`       // warmup/working/drop/amrap/cluster/failure, пусто - рабочий
+	Tempo      string  `json:"tempo,omitempty"`      // темп, например 3-1-1-0
+	Rest       int     `json:"rest,omitempty"`       // отдых после подхода в секундах
+	Notes      string  `json:"notes,omitempty"`      // заметка к подходу
+	Assistance float64 `json:"assistance,omitempty"` // помощь (резина, гравитрон) в кг
+	Duration   int     `json:"duration,omitempty"`   // время под нагрузкой в секундах
+	Distance   float64 `json:"distance,omitempty"`   // дистанция в метрах
+	HeartRate  int     `json:"heartRate,omitempty"`  // пульс, уд/мин
+	// Время выполнения подхода в живой тренировке
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+// IsWarmup сообщает, что подход разминочный и не учитывается в объеме и рекордах
+func (s Set) IsWarmup() bool {
+	return s.Type == SetTypeWarmup
 }
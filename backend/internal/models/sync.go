@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Сущности, которые клиент может синхронизировать офлайн
+const (
+	SyncEntitySession         = "training_session"
+	SyncEntitySessionExercise = "session_exercise"
+	SyncEntityBodyWeight      = "body_weight"
+)
+
+// Операции синхронизации
+const (
+	SyncOpUpsert = "upsert"
+	SyncOpDelete = "delete"
+)
+
+// Результаты применения изменения
+const (
+	SyncStatusApplied   = "applied"
+	SyncStatusDuplicate = "duplicate" // изменение с этим changeId уже применено
+	SyncStatusConflict  = "conflict"  // запись изменилась на сервере после baseVersion
+	SyncStatusRejected  = "rejected"  // изменение не прошло валидацию
+)
+
+// SyncChange - запись журнала изменений, ID служит курсором ленты изменений
+type SyncChange struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	ProfileID uint      `json:"profileId" gorm:"not null;index;uniqueIndex:idx_sync_changes_profile_change"`
+	Entity    string    `json:"entity" gorm:"not null"`
+	EntityID  uint      `json:"entityId" gorm:"not null"`
+	ClientID  *string   `json:"clientId"`
+	ChangeID  *string   `json:"changeId" gorm:"uniqueIndex:idx_sync_changes_profile_change"` // идентификатор изменения от клиента, уникален в пределах профиля
+	Op        string    `json:"op" gorm:"not null"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -54,4 +54,5 @@ type Training struct {
 	Week4D5Kg   int    `json:"week4d5Kg"`
 	Week4D6Reps int    `json:"week4d6Reps"`
 	Week4D6Kg   int    `json:"week4d6Kg"`
+	Version     int    `json:"version" gorm:"not null;default:1"`
 }
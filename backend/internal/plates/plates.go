@@ -0,0 +1,261 @@
+// Package plates раскладывает вес штанги по дискам из набора атлета и находит
+// ближайший вес, который этим набором можно собрать.
+package plates
+
+import (
+	"math"
+	"sort"
+
+	"training-tracker/backend/internal/models"
+)
+
+// Режимы подбора веса
+const (
+	ModeNearest = "nearest" // ближайший, при равенстве - легче
+	ModeDown    = "down"    // не тяжелее заданного
+	ModeUp      = "up"      // не легче заданного
+)
+
+// KgPerLb - килограммов в фунте
+const KgPerLb = 0.45359237
+
+// scale - расчет ведется в сотых долях единицы веса, чтобы дробные диски складывались точно
+const scale = 100
+
+// DefaultInventory - стандартный набор зала: олимпийский гриф и по несколько пар каждого диска
+func DefaultInventory(unit string) models.PlateInventory {
+	if unit == models.UnitLb {
+		return models.PlateInventory{
+			Unit:      models.UnitLb,
+			BarWeight: 45,
+			Plates: []models.PlateStock{
+				{Weight: 45, Pairs: 8}, {Weight: 35, Pairs: 2}, {Weight: 25, Pairs: 2},
+				{Weight: 10, Pairs: 2}, {Weight: 5, Pairs: 2}, {Weight: 2.5, Pairs: 2},
+			},
+		}
+	}
+	return models.PlateInventory{
+		Unit:      models.UnitKg,
+		BarWeight: 20,
+		Plates: []models.PlateStock{
+			{Weight: 25, Pairs: 8}, {Weight: 20, Pairs: 2}, {Weight: 15, Pairs: 2}, {Weight: 10, Pairs: 2},
+			{Weight: 5, Pairs: 2}, {Weight: 2.5, Pairs: 2}, {Weight: 1.25, Pairs: 2},
+		},
+	}
+}
+
+// Normalize дополняет набор значениями по умолчанию: единицы, гриф и диски
+func Normalize(inventory *models.PlateInventory) models.PlateInventory {
+	if inventory == nil {
+		return DefaultInventory(models.UnitKg)
+	}
+	result := *inventory
+	if result.Unit == "" {
+		result.Unit = models.UnitKg
+	}
+	defaults := DefaultInventory(result.Unit)
+	if result.BarWeight == 0 {
+		result.BarWeight = defaults.BarWeight
+	}
+	if len(result.Plates) == 0 {
+		result.Plates = defaults.Plates
+	}
+	return result
+}
+
+// Load подбирает диски для веса target (в единицах набора) в режиме mode
+func Load(inventory models.PlateInventory, target float64, mode string) models.PlateLoad {
+	return NewLoader(inventory).Load(target, mode)
+}
+
+// Round - ближайший вес в килограммах, который собирается набором
+func Round(inventory models.PlateInventory, weightKg float64) float64 {
+	return NewLoader(inventory).Round(weightKg)
+}
+
+// Loader подбирает диски из одного набора. Таблица собираемых весов стороны строится
+// при первом подборе и достраивается только для более тяжелых весов, поэтому для
+// всех подходов одного запроса стоит использовать один Loader.
+type Loader struct {
+	inventory models.PlateInventory
+	plates    []models.PlateStock // от тяжелых к легким
+	step      int                 // НОД весов дисков в сотых: собираемые веса кратны ему
+	heaviest  int                 // самый тяжелый диск, в шагах
+	total     int                 // все диски на одну сторону, в шагах
+
+	// count[s] - наименьшее число дисков для веса стороны s шагов,
+	// taken[i][s] - сколько дисков plates[i] взято в этой раскладке
+	count []int
+	taken [][]int
+}
+
+const unreachable = math.MaxInt32
+
+// maxSteps - предел таблицы весов стороны в шагах. Даже при шаге 0.01 это больше
+// 650 единиц веса на сторону, поэтому предел задевает только неправдоподобные
+// веса, а память на таблицу не зависит от дробных дисков в наборе.
+const maxSteps = 1 << 16
+
+// NewLoader готовит подбор для набора; диски без веса или без пар не учитываются
+func NewLoader(inventory models.PlateInventory) *Loader {
+	l := &Loader{inventory: inventory}
+	for _, p := range inventory.Plates {
+		if toUnits(p.Weight) > 0 && p.Pairs > 0 {
+			l.plates = append(l.plates, p)
+		}
+	}
+	sort.Slice(l.plates, func(i, j int) bool { return l.plates[i].Weight > l.plates[j].Weight })
+	for _, p := range l.plates {
+		l.step = gcd(l.step, toUnits(p.Weight))
+	}
+	for _, p := range l.plates {
+		weight := toUnits(p.Weight) / l.step
+		l.total += weight * p.Pairs
+		if weight > l.heaviest {
+			l.heaviest = weight
+		}
+	}
+	l.count = []int{0}
+	return l
+}
+
+// Load подбирает диски для веса target (в единицах набора) в режиме mode
+func (l *Loader) Load(target float64, mode string) models.PlateLoad {
+	collars := 2 * l.inventory.CollarWeight
+	fixed := l.inventory.BarWeight + collars
+
+	side := l.pickSide(toUnits((target-fixed)/2), mode)
+
+	result := models.PlateLoad{
+		Unit:           l.inventory.Unit,
+		TargetWeight:   target,
+		BarWeight:      l.inventory.BarWeight,
+		CollarWeight:   collars,
+		AchievedWeight: round(fixed + 2*side.total),
+		PerSide:        side.plates,
+	}
+	result.Difference = round(result.AchievedWeight - target)
+	result.Exact = result.Difference == 0
+	return result
+}
+
+// Round - ближайший вес в килограммах, который собирается набором
+func (l *Loader) Round(weightKg float64) float64 {
+	if l.inventory.Unit == models.UnitLb {
+		return round(l.Load(weightKg/KgPerLb, ModeNearest).AchievedWeight * KgPerLb)
+	}
+	return l.Load(weightKg, ModeNearest).AchievedWeight
+}
+
+type sideLoad struct {
+	total  float64
+	plates []models.PlateCount
+}
+
+// pickSide находит собираемый вес стороны, ближайший к target (в сотых долях),
+// и раскладку с наименьшим числом дисков
+func (l *Loader) pickSide(target int, mode string) sideLoad {
+	result := sideLoad{plates: []models.PlateCount{}}
+	if target <= 0 || l.step == 0 {
+		return result
+	}
+	// Ближайший собираемый вес тяжелее target не дальше самого тяжелого диска:
+	// иначе без этого диска раскладка тоже была бы тяжелее target
+	l.grow(target/l.step + l.heaviest + 1)
+
+	best := l.choose(target, mode)
+	result.total = float64(best*l.step) / scale
+	for i := len(l.plates) - 1; i >= 0; i-- {
+		if k := l.taken[i][best]; k > 0 {
+			result.plates = append(result.plates, models.PlateCount{Weight: l.plates[i].Weight, Count: k})
+			best -= k * toUnits(l.plates[i].Weight) / l.step
+		}
+	}
+	// Раскладка восстанавливается с последнего слоя (легкие диски), а вешают от тяжелых
+	sort.Slice(result.plates, func(i, j int) bool { return result.plates[i].Weight > result.plates[j].Weight })
+	return result
+}
+
+// grow достраивает таблицу до веса стороны capacity шагов (не больше всех дисков
+// и не больше maxSteps).
+// Диски каждого веса - ограниченный рюкзак: по слою на вес диска, в слое
+// запоминается, сколько дисков взято. Таблица растет минимум вдвое, чтобы
+// подбор все более тяжелых весов не пересчитывал ее каждый раз.
+func (l *Loader) grow(capacity int) {
+	current := len(l.count) - 1
+	limit := min(l.total, maxSteps)
+	capacity = min(capacity, limit)
+	if capacity <= current {
+		return
+	}
+	if capacity < 2*current {
+		capacity = min(2*current, limit)
+	}
+
+	count := make([]int, capacity+1)
+	for s := 1; s <= capacity; s++ {
+		count[s] = unreachable
+	}
+	l.taken = make([][]int, len(l.plates))
+	for i, p := range l.plates {
+		weight := toUnits(p.Weight) / l.step
+		next := make([]int, capacity+1)
+		l.taken[i] = make([]int, capacity+1)
+		for s := 0; s <= capacity; s++ {
+			next[s] = count[s]
+			for k := 1; k <= p.Pairs && k*weight <= s; k++ {
+				if prev := count[s-k*weight]; prev != unreachable && prev+k < next[s] {
+					next[s] = prev + k
+					l.taken[i][s] = k
+				}
+			}
+		}
+		count = next
+	}
+	l.count = count
+}
+
+// choose выбирает собираемый вес стороны (в шагах) по режиму
+func (l *Loader) choose(target int, mode string) int {
+	capacity := len(l.count) - 1
+	below, above := 0, -1
+	for s := min(target/l.step, capacity); s > 0; s-- {
+		if l.count[s] != unreachable {
+			below = s
+			break
+		}
+	}
+	for s := target/l.step + 1; s <= capacity; s++ {
+		if l.count[s] != unreachable {
+			above = s
+			break
+		}
+	}
+	switch {
+	case above < 0 || below*l.step == target:
+		return below
+	case mode == ModeDown:
+		return below
+	case mode == ModeUp:
+		return above
+	case above*l.step-target < target-below*l.step:
+		return above
+	default:
+		return below
+	}
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+func toUnits(weight float64) int {
+	return int(math.Round(weight * scale))
+}
+
+func round(v float64) float64 {
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,254 @@
+package plates
+
+import (
+	"reflect"
+	"testing"
+
+	"training-tracker/backend/internal/models"
+)
+
+// testInventory - набор без равноценных раскладок, чтобы ожидаемые диски были однозначны
+func testInventory() models.PlateInventory {
+	return models.PlateInventory{
+		Unit:      models.UnitKg,
+		BarWeight: 20,
+		Plates: []models.PlateStock{
+			{Weight: 1.25, Pairs: 1}, {Weight: 20, Pairs: 2}, {Weight: 5, Pairs: 1},
+			{Weight: 10, Pairs: 2}, {Weight: 2.5, Pairs: 1},
+		},
+	}
+}
+
+func TestLoad(t *testing.T) {
+	withCollars := testInventory()
+	withCollars.CollarWeight = 2.5
+	onlyTwenties := models.PlateInventory{Unit: models.UnitKg, BarWeight: 20, Plates: []models.PlateStock{{Weight: 20, Pairs: 2}}}
+
+	tests := []struct {
+		name      string
+		inventory models.PlateInventory
+		target    float64
+		mode      string
+		achieved  float64
+		perSide   []models.PlateCount
+	}{
+		{"exact", testInventory(), 100, ModeNearest, 100, []models.PlateCount{{Weight: 20, Count: 2}}},
+		{"fewest plates", testInventory(), 80, ModeNearest, 80, []models.PlateCount{{Weight: 20, Count: 1}, {Weight: 10, Count: 1}}},
+		{"nearest rounds down", testInventory(), 101, ModeNearest, 100, []models.PlateCount{{Weight: 20, Count: 2}}},
+		{"nearest rounds up", testInventory(), 102, ModeNearest, 102.5, []models.PlateCount{{Weight: 20, Count: 2}, {Weight: 1.25, Count: 1}}},
+		{"nearest tie goes lighter", onlyTwenties, 80, ModeNearest, 60, []models.PlateCount{{Weight: 20, Count: 1}}},
+		{"down", testInventory(), 102, ModeDown, 100, []models.PlateCount{{Weight: 20, Count: 2}}},
+		{"up", testInventory(), 101, ModeUp, 102.5, []models.PlateCount{{Weight: 20, Count: 2}, {Weight: 1.25, Count: 1}}},
+		{"up keeps an exact weight", testInventory(), 100, ModeUp, 100, []models.PlateCount{{Weight: 20, Count: 2}}},
+		{"down keeps an exact weight", testInventory(), 100, ModeDown, 100, []models.PlateCount{{Weight: 20, Count: 2}}},
+		{"lighter than the bar", testInventory(), 15, ModeUp, 20, []models.PlateCount{}},
+		{"heavier than all plates", testInventory(), 200, ModeUp, 157.5, []models.PlateCount{
+			{Weight: 20, Count: 2}, {Weight: 10, Count: 2}, {Weight: 5, Count: 1}, {Weight: 2.5, Count: 1}, {Weight: 1.25, Count: 1},
+		}},
+		{"collars", withCollars, 65, ModeNearest, 65, []models.PlateCount{{Weight: 20, Count: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Load(tt.inventory, tt.target, tt.mode)
+			if got.AchievedWeight != tt.achieved {
+				t.Errorf("AchievedWeight = %v, want %v", got.AchievedWeight, tt.achieved)
+			}
+			if !reflect.DeepEqual(got.PerSide, tt.perSide) {
+				t.Errorf("PerSide = %v, want %v", got.PerSide, tt.perSide)
+			}
+			if want := round(tt.achieved - tt.target); got.Difference != want || got.Exact != (want == 0) {
+				t.Errorf("Difference = %v, Exact = %v, want %v", got.Difference, got.Exact, want)
+			}
+		})
+	}
+}
+
+func TestLoadWithoutPlates(t *testing.T) {
+	inventory := models.PlateInventory{Unit: models.UnitKg, BarWeight: 20, Plates: []models.PlateStock{{Weight: 0, Pairs: 2}, {Weight: 10, Pairs: 0}}}
+	got := Load(inventory, 60, ModeUp)
+	if got.AchievedWeight != 20 || len(got.PerSide) != 0 {
+		t.Errorf("Load = %+v, want the bar only", got)
+	}
+}
+
+func TestLoadFractionalPlates(t *testing.T) {
+	inventory := models.PlateInventory{
+		Unit:      models.UnitKg,
+		BarWeight: 20,
+		Plates:    []models.PlateStock{{Weight: 10, Pairs: 2}, {Weight: 0.5, Pairs: 1}, {Weight: 0.25, Pairs: 1}},
+	}
+	got := Load(inventory, 41.5, ModeNearest)
+	want := []models.PlateCount{{Weight: 10, Count: 1}, {Weight: 0.5, Count: 1}, {Weight: 0.25, Count: 1}}
+	if got.AchievedWeight != 41.5 || !reflect.DeepEqual(got.PerSide, want) {
+		t.Errorf("Load = %v %v, want 41.5 %v", got.AchievedWeight, got.PerSide, want)
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		name      string
+		inventory models.PlateInventory
+		weightKg  float64
+		want      float64
+	}{
+		{"kg", DefaultInventory(models.UnitKg), 101, 100},
+		{"kg with change plates", DefaultInventory(models.UnitKg), 101.5, 102.5},
+		// 100 кг ≈ 220.46 lb, ближайший собираемый вес - 220 lb
+		{"lb", DefaultInventory(models.UnitLb), 100, 99.79},
+		{"lb bar only", DefaultInventory(models.UnitLb), 10, 20.41},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Round(tt.inventory, tt.weightKg); got != tt.want {
+				t.Errorf("Round(%v) = %v, want %v", tt.weightKg, got, tt.want)
+			}
+		})
+	}
+}
+
+// Loader достраивает таблицу по мере роста веса: результат должен совпадать
+// с отдельным подбором для каждого веса в любом порядке
+func TestLoaderReuse(t *testing.T) {
+	inventory := DefaultInventory(models.UnitKg)
+	weights := []float64{42.5, 200, 61.25, 330, 20, 147.5, 500, 97.5}
+	for _, order := range [][]float64{weights, {500, 20, 330, 42.5}} {
+		loader := NewLoader(inventory)
+		for _, w := range order {
+			for _, mode := range []string{ModeNearest, ModeDown, ModeUp} {
+				got, want := loader.Load(w, mode), Load(inventory, w, mode)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Load(%v, %s) = %+v, want %+v", w, mode, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize(nil); !reflect.DeepEqual(got, DefaultInventory(models.UnitKg)) {
+		t.Errorf("Normalize(nil) = %+v", got)
+	}
+	got := Normalize(&models.PlateInventory{Unit: models.UnitLb})
+	if got.BarWeight != 45 || !reflect.DeepEqual(got.Plates, DefaultInventory(models.UnitLb).Plates) {
+		t.Errorf("Normalize(lb) = %+v", got)
+	}
+	custom := models.PlateInventory{BarWeight: 15, Plates: []models.PlateStock{{Weight: 5, Pairs: 1}}}
+	got = Normalize(&custom)
+	if got.Unit != models.UnitKg || got.BarWeight != 15 || len(got.Plates) != 1 {
+		t.Errorf("Normalize(custom) = %+v", got)
+	}
+}
+
+// Подбор сверяется с перебором всех раскладок небольшого набора: вес по режиму
+// и наименьшее число дисков для него
+func TestLoadMatchesBruteForce(t *testing.T) {
+	inventory := testInventory()
+	loader := NewLoader(inventory)
+
+	type option struct{ side, plates int }
+	var options []option
+	var walk func(i, side, plates int)
+	walk = func(i, side, plates int) {
+		if i == len(inventory.Plates) {
+			options = append(options, option{side, plates})
+			return
+		}
+		p := inventory.Plates[i]
+		for k := 0; k <= p.Pairs; k++ {
+			walk(i+1, side+k*toUnits(p.Weight), plates+k)
+		}
+	}
+	walk(0, 0, 0)
+
+	for target := 15.0; target <= 170; target += 0.25 {
+		side := toUnits((target - inventory.BarWeight) / 2)
+		for _, mode := range []string{ModeNearest, ModeDown, ModeUp} {
+			best := option{side: -1}
+			for _, o := range options {
+				if better(o.side, best.side, side, mode) || (o.side == best.side && o.plates < best.plates) {
+					best = o
+				}
+			}
+			got := loader.Load(target, mode)
+			var plates int
+			for _, p := range got.PerSide {
+				plates += p.Count
+			}
+			if want := round(inventory.BarWeight + 2*float64(best.side)/scale); got.AchievedWeight != want || plates != best.plates {
+				t.Errorf("Load(%v, %s) = %v with %d plates, want %v with %d", target, mode, got.AchievedWeight, plates, want, best.plates)
+			}
+		}
+	}
+}
+
+// better - вес стороны a подходит к target в режиме mode лучше, чем b
+func better(a, b, target int, mode string) bool {
+	if b < 0 {
+		return true
+	}
+	if target <= 0 {
+		return a < b
+	}
+	fits := func(s int) bool {
+		switch mode {
+		case ModeDown:
+			return s <= target
+		case ModeUp:
+			return s >= target
+		}
+		return true
+	}
+	if fits(a) != fits(b) {
+		return fits(a)
+	}
+	da, db := abs(a-target), abs(b-target)
+	if da != db {
+		return da < db
+	}
+	return a < b
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// Самый большой набор, который пропускает проверка: подбор не должен строить
+// таблицу на все диски сразу
+func TestLoadLargeInventory(t *testing.T) {
+	inventory := models.PlateInventory{Unit: models.UnitKg, BarWeight: 20}
+	for i := 0; i < 30; i++ {
+		inventory.Plates = append(inventory.Plates, models.PlateStock{Weight: 50 - float64(i)*0.01, Pairs: 20})
+	}
+	loader := NewLoader(inventory)
+	for _, w := range []float64{60, 180.5, 250, 499.99} {
+		got := loader.Load(w, ModeNearest)
+		if got.AchievedWeight < w-50 || got.AchievedWeight > w+50 {
+			t.Errorf("Load(%v) = %v", w, got.AchievedWeight)
+		}
+	}
+	if capacity := len(loader.count) - 1; capacity > loader.total/10 {
+		t.Errorf("table capacity %d of %d", capacity, loader.total)
+	}
+}
+
+// Диск 0.01 делает шаг таблицы минимальным: таблица не растет дальше maxSteps,
+// а вес тяжелее предела собирается настолько близко, насколько позволяет таблица
+func TestLoadTableLimit(t *testing.T) {
+	inventory := models.PlateInventory{Unit: models.UnitKg, BarWeight: 20, Plates: []models.PlateStock{{Weight: 0.01, Pairs: 1}}}
+	for i := 0; i < 29; i++ {
+		inventory.Plates = append(inventory.Plates, models.PlateStock{Weight: 50 - float64(i)*0.5, Pairs: 20})
+	}
+	loader := NewLoader(inventory)
+	if got := loader.Load(1000, ModeNearest); got.AchievedWeight != 1000 {
+		t.Errorf("Load(1000) = %v", got.AchievedWeight)
+	}
+	if got := loader.Load(5000, ModeUp); got.AchievedWeight > 20+2*float64(maxSteps)/scale {
+		t.Errorf("Load(5000) = %v", got.AchievedWeight)
+	}
+	if capacity := len(loader.count) - 1; capacity > maxSteps {
+		t.Errorf("table capacity %d, limit %d", capacity, maxSteps)
+	}
+}
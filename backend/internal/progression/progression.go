@@ -0,0 +1,232 @@
+// Package progression находит застой и спад расчетного 1ПМ в упражнениях и признаки
+// накопленной усталости. Рекомендации по ним (разгрузка, смена диапазона повторений,
+// замена упражнения) составляют правила пакета recommendations.
+package progression
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"training-tracker/backend/internal/models"
+)
+
+// Динамика упражнения
+const (
+	StatusProgressing      = "progressing"
+	StatusStalled          = "stalled"   // нет нового лучшего 1ПМ N тренировок подряд
+	StatusDeclining        = "declining" // застой, и 1ПМ упал ниже прежнего лучшего
+	StatusInsufficientData = "insufficient_data"
+)
+
+// Уровни усталости
+const (
+	FatigueLow      = "low"
+	FatigueModerate = "moderate"
+	FatigueHigh     = "high"
+)
+
+// Признаки усталости
+const (
+	SignalDecliningLifts = "declining_lifts"
+	SignalLoadSpike      = "load_spike"
+	SignalMonotony       = "monotony"
+	SignalHighRPE        = "high_rpe"
+)
+
+const (
+	DefaultStallSessions = 4
+	MinStallSessions     = 2
+	MaxStallSessions     = 12
+	// declineThreshold - падение 1ПМ, %, после которого застой считается спадом
+	declineThreshold = 3.0
+	// activeWindow - упражнения без тренировок дольше этого срока не анализируются
+	activeWindow = 28 * 24 * time.Hour
+	// rpeWindow - за какой срок считается средний RPE
+	rpeWindow = 14 * 24 * time.Hour
+	// Пороги признаков усталости
+	fatigueACWR           = 1.3
+	fatigueMonotony       = 2.0
+	fatigueRPE            = 9.0
+	fatigueDecliningLifts = 2
+	// maxAlternatives - сколько замен предлагать для застрявшего упражнения
+	maxAlternatives = 3
+)
+
+// Session - тренировка упражнения
+type Session struct {
+	Date   time.Time
+	OneRM  float64 // лучший расчетный 1ПМ за тренировку
+	Volume float64 // вес × повторения рабочих подходов
+	Reps   float64 // среднее число повторений в рабочих подходах
+	RPE    float64 // средний RPE подходов, 0 - не указан
+}
+
+// Exercise - ряд тренировок упражнения по возрастанию даты
+type Exercise struct {
+	Name     string
+	Sessions []Session
+	// Alternatives - упражнения на ту же группу мышц, которыми его можно заменить
+	Alternatives []string
+}
+
+// Input - данные профиля для анализа
+type Input struct {
+	Exercises     []Exercise
+	ACWR          *float64 // текущая нагрузка, см. training-load
+	Monotony      *float64
+	Now           time.Time
+	StallSessions int // N, 0 - DefaultStallSessions
+}
+
+// Analyze оценивает динамику каждого упражнения, которое выполнялось за последние
+// четыре недели, и уровень усталости
+func Analyze(in Input) models.ProgressionResponse {
+	n := in.StallSessions
+	if n == 0 {
+		n = DefaultStallSessions
+	}
+	response := models.ProgressionResponse{
+		StallSessions:   n,
+		Exercises:       []models.ExerciseTrend{},
+		Recommendations: []models.Recommendation{},
+	}
+
+	var rpeSum float64
+	var rpeCount int
+	for _, ex := range in.Exercises {
+		if len(ex.Sessions) == 0 || in.Now.Sub(ex.Sessions[len(ex.Sessions)-1].Date) > activeWindow {
+			continue
+		}
+		trend := Trend(ex, n)
+		if trend.Status == StatusStalled || trend.Status == StatusDeclining {
+			trend.Alternatives = ex.Alternatives
+			if len(trend.Alternatives) > maxAlternatives {
+				trend.Alternatives = trend.Alternatives[:maxAlternatives]
+			}
+		}
+		response.Exercises = append(response.Exercises, trend)
+		for _, s := range ex.Sessions {
+			if s.RPE > 0 && in.Now.Sub(s.Date) <= rpeWindow {
+				rpeSum += s.RPE
+				rpeCount++
+			}
+		}
+	}
+	sort.Slice(response.Exercises, func(i, j int) bool {
+		a, b := response.Exercises[i], response.Exercises[j]
+		if statusOrder[a.Status] != statusOrder[b.Status] {
+			return statusOrder[a.Status] < statusOrder[b.Status]
+		}
+		return a.Exercise < b.Exercise
+	})
+
+	fatigue := models.FatigueStatus{Signals: []string{}, ACWR: in.ACWR, Monotony: in.Monotony}
+	if rpeCount > 0 {
+		fatigue.RecentRPE = round(rpeSum / float64(rpeCount))
+	}
+	for _, t := range response.Exercises {
+		switch t.Status {
+		case StatusDeclining:
+			fatigue.DecliningExercises++
+			fatigue.StalledExercises++
+		case StatusStalled:
+			fatigue.StalledExercises++
+		}
+	}
+	if fatigue.DecliningExercises >= fatigueDecliningLifts {
+		fatigue.Signals = append(fatigue.Signals, SignalDecliningLifts)
+	}
+	if in.ACWR != nil && *in.ACWR > fatigueACWR {
+		fatigue.Signals = append(fatigue.Signals, SignalLoadSpike)
+	}
+	if in.Monotony != nil && *in.Monotony > fatigueMonotony {
+		fatigue.Signals = append(fatigue.Signals, SignalMonotony)
+	}
+	if fatigue.RecentRPE >= fatigueRPE {
+		fatigue.Signals = append(fatigue.Signals, SignalHighRPE)
+	}
+	switch len(fatigue.Signals) {
+	case 0:
+		fatigue.Level = FatigueLow
+	case 1:
+		fatigue.Level = FatigueModerate
+	default:
+		fatigue.Level = FatigueHigh
+	}
+	response.Fatigue = fatigue
+	return response
+}
+
+var statusOrder = map[string]int{
+	StatusDeclining:        0,
+	StatusStalled:          1,
+	StatusProgressing:      2,
+	StatusInsufficientData: 3,
+}
+
+// Trend - динамика упражнения: последние n тренировок сравниваются с предыдущими
+func Trend(ex Exercise, n int) models.ExerciseTrend {
+	sessions := ex.Sessions
+	trend := models.ExerciseTrend{
+		Exercise: ex.Name,
+		Status:   StatusInsufficientData,
+		Sessions: len(sessions),
+	}
+	if len(sessions) == 0 {
+		return trend
+	}
+	trend.LastDate = sessions[len(sessions)-1].Date.Format("2006-01-02")
+
+	var best float64
+	for _, s := range sessions {
+		if s.OneRM > best {
+			best = s.OneRM
+			trend.SessionsSincePR = 0
+		} else {
+			trend.SessionsSincePR++
+		}
+	}
+	if len(sessions) <= n {
+		return trend
+	}
+
+	recent, prior := sessions[len(sessions)-n:], sessions[:len(sessions)-n]
+	for _, s := range prior {
+		trend.BestOneRM = math.Max(trend.BestOneRM, s.OneRM)
+	}
+	var recentVolume, reps float64
+	for _, s := range recent {
+		trend.RecentOneRM = math.Max(trend.RecentOneRM, s.OneRM)
+		recentVolume += s.Volume
+		reps += s.Reps
+	}
+	trend.AverageReps = round(reps / float64(n))
+	if trend.BestOneRM > 0 {
+		trend.OneRMChange = round((trend.RecentOneRM/trend.BestOneRM - 1) * 100)
+	}
+	if len(prior) > n {
+		prior = prior[len(prior)-n:]
+	}
+	var priorVolume float64
+	for _, s := range prior {
+		priorVolume += s.Volume
+	}
+	if priorVolume > 0 {
+		trend.VolumeChange = round((recentVolume/float64(n)/(priorVolume/float64(len(prior))) - 1) * 100)
+	}
+
+	switch {
+	case trend.SessionsSincePR < n:
+		trend.Status = StatusProgressing
+	case trend.OneRMChange <= -declineThreshold:
+		trend.Status = StatusDeclining
+	default:
+		trend.Status = StatusStalled
+	}
+	return trend
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
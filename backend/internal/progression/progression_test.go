@@ -0,0 +1,123 @@
+package progression
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+var now = time.Date(2026, 3, 30, 0, 0, 0, 0, time.UTC)
+
+// series - тренировки с заданными 1ПМ раз в три дня, последняя - за день до now
+func series(oneRMs ...float64) []Session {
+	sessions := make([]Session, len(oneRMs))
+	for i, oneRM := range oneRMs {
+		sessions[i] = Session{
+			Date:   now.AddDate(0, 0, -1-3*(len(oneRMs)-1-i)),
+			OneRM:  oneRM,
+			Volume: oneRM * 10,
+			Reps:   5,
+		}
+	}
+	return sessions
+}
+
+func TestTrend(t *testing.T) {
+	tests := []struct {
+		name            string
+		oneRMs          []float64
+		status          string
+		sessionsSincePR int
+		oneRMChange     float64
+	}{
+		{"no sessions", nil, StatusInsufficientData, 0, 0},
+		{"not enough sessions", []float64{100, 90, 90, 90}, StatusInsufficientData, 3, 0},
+		{"new best in the last n", []float64{100, 90, 90, 90, 101}, StatusProgressing, 0, 1},
+		{"one session short of a stall", []float64{90, 100, 95, 95, 95}, StatusProgressing, 3, 11.11},
+		{"stalled at n sessions", []float64{100, 100, 100, 100, 100}, StatusStalled, 4, 0},
+		{"stalled just above the decline threshold", []float64{100, 97, 97, 96, 97.01}, StatusStalled, 4, -2.99},
+		{"declining at the threshold", []float64{100, 97, 96, 95, 94}, StatusDeclining, 4, -3},
+		{"declining", []float64{120, 100, 110, 105, 100, 90}, StatusDeclining, 5, -8.33},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Trend(Exercise{Name: "squat", Sessions: series(tt.oneRMs...)}, 4)
+			if got.Status != tt.status || got.SessionsSincePR != tt.sessionsSincePR || got.OneRMChange != tt.oneRMChange {
+				t.Errorf("Trend = %s, %d since PR, %v%%, want %s, %d, %v%%",
+					got.Status, got.SessionsSincePR, got.OneRMChange, tt.status, tt.sessionsSincePR, tt.oneRMChange)
+			}
+		})
+	}
+}
+
+func TestTrendVolume(t *testing.T) {
+	sessions := series(100, 100, 100, 100, 100, 100)
+	for i := range sessions {
+		sessions[i].Volume = float64(1000 + 100*i)
+		sessions[i].Reps = float64(4 + i)
+	}
+	got := Trend(Exercise{Sessions: sessions}, 3)
+	// последние 3 тренировки: объем 1300-1500, повторения 7-9; до них 1000-1200
+	if got.VolumeChange != 27.27 || got.AverageReps != 8 {
+		t.Errorf("VolumeChange = %v, AverageReps = %v", got.VolumeChange, got.AverageReps)
+	}
+}
+
+func TestAnalyzeFatigue(t *testing.T) {
+	declining := series(100, 97, 96, 95, 94)
+	stale := series(100, 90, 90, 90, 90)
+	for i := range stale {
+		stale[i].Date = stale[i].Date.AddDate(0, 0, -30)
+	}
+	acwr, monotony := 1.31, 2.0
+
+	tests := []struct {
+		name     string
+		in       Input
+		level    string
+		signals  []string
+		statuses []string
+	}{
+		{"low", Input{Exercises: []Exercise{{Name: "a", Sessions: series(90, 95, 100)}}}, FatigueLow, []string{}, []string{StatusInsufficientData}},
+		{"one declining lift and a load spike", Input{
+			Exercises: []Exercise{{Name: "a", Sessions: declining}},
+			ACWR:      &acwr,
+			Monotony:  &monotony,
+		}, FatigueModerate, []string{SignalLoadSpike}, []string{StatusDeclining}},
+		{"two declining lifts and a load spike", Input{
+			Exercises: []Exercise{{Name: "b", Sessions: declining}, {Name: "a", Sessions: declining}},
+			ACWR:      &acwr,
+		}, FatigueHigh, []string{SignalDecliningLifts, SignalLoadSpike}, []string{StatusDeclining, StatusDeclining}},
+		{"exercises outside the window are skipped", Input{
+			Exercises: []Exercise{{Name: "a", Sessions: stale}, {Name: "b", Sessions: declining}},
+		}, FatigueLow, []string{}, []string{StatusDeclining}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.in.Now = now
+			got := Analyze(tt.in)
+			statuses := []string{}
+			for _, e := range got.Exercises {
+				statuses = append(statuses, e.Status)
+			}
+			if got.Fatigue.Level != tt.level || !reflect.DeepEqual(got.Fatigue.Signals, tt.signals) || !reflect.DeepEqual(statuses, tt.statuses) {
+				t.Errorf("Analyze = %s %v %v, want %s %v %v", got.Fatigue.Level, got.Fatigue.Signals, statuses, tt.level, tt.signals, tt.statuses)
+			}
+		})
+	}
+}
+
+func TestAnalyzeHighRPE(t *testing.T) {
+	sessions := series(100, 101, 102)
+	sessions[0].RPE = 10
+	sessions[1].RPE = 9
+	sessions[2].RPE = 8
+	old := series(100)
+	old[0].Date = now.AddDate(0, 0, -20)
+	old[0].RPE = 6
+
+	got := Analyze(Input{Now: now, Exercises: []Exercise{{Name: "a", Sessions: sessions}, {Name: "b", Sessions: append(old, series(100)...)}}})
+	if got.Fatigue.RecentRPE != 9 || !reflect.DeepEqual(got.Fatigue.Signals, []string{SignalHighRPE}) {
+		t.Errorf("Fatigue = %+v", got.Fatigue)
+	}
+}
@@ -0,0 +1,91 @@
+// Package realtime рассылает изменения тренировочных сессий подписанным клиентам.
+package realtime
+
+import (
+	"sync"
+	"time"
+)
+
+// subscriberBuffer - сколько событий может ждать отправки одному клиенту
+const subscriberBuffer = 32
+
+// Типы событий сессии
+const (
+	EventSnapshot        = "snapshot"
+	EventSessionUpdated  = "session.updated"
+	EventSessionDeleted  = "session.deleted"
+	EventExerciseAdded   = "exercise.added"
+	EventExerciseUpdated = "exercise.updated"
+	EventExerciseDeleted = "exercise.deleted"
+	EventSetAdded        = "set.added"
+	EventExercisesSorted = "exercises.reordered"
+)
+
+// Event - изменение в сессии
+type Event struct {
+	Type      string      `json:"type"`
+	SessionID uint        `json:"sessionId"`
+	Data      interface{} `json:"data"`
+	At        time.Time   `json:"at"`
+}
+
+// Hub хранит подписчиков по сессиям в памяти процесса.
+// При нескольких экземплярах бэкенда клиент получает только события своего экземпляра.
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[uint]map[chan Event]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[uint]map[chan Event]struct{})}
+}
+
+// Subscribe возвращает канал событий сессии и функцию отписки.
+// Канал закрывается при отписке или если клиент не успевает читать события.
+func (h *Hub) Subscribe(sessionID uint) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	h.mu.Lock()
+	if h.subscribers[sessionID] == nil {
+		h.subscribers[sessionID] = make(map[chan Event]struct{})
+	}
+	h.subscribers[sessionID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() { h.remove(sessionID, ch) })
+	}
+}
+
+// Publish отправляет событие всем подписчикам сессии без блокировки
+func (h *Hub) Publish(sessionID uint, eventType string, data interface{}) {
+	event := Event{Type: eventType, SessionID: sessionID, Data: data, At: time.Now()}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subscribers[sessionID] {
+		select {
+		case ch <- event:
+		default:
+			// Медленный клиент: отключаем, при переподключении он получит свежий снимок
+			delete(h.subscribers[sessionID], ch)
+			close(ch)
+		}
+	}
+	if len(h.subscribers[sessionID]) == 0 {
+		delete(h.subscribers, sessionID)
+	}
+}
+
+func (h *Hub) remove(sessionID uint, ch chan Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subscribers[sessionID][ch]; ok {
+		delete(h.subscribers[sessionID], ch)
+		close(ch)
+	}
+	if len(h.subscribers[sessionID]) == 0 {
+		delete(h.subscribers, sessionID)
+	}
+}
@@ -0,0 +1,220 @@
+// Package recommendations - движок рекомендаций: набор правил с идентификатором,
+// важностью, категорией и шаблонами сообщений на нескольких языках. Правило
+// смотрит на аналитику профиля и возвращает находки с данными, из которых
+// собирается сообщение. Правила можно добавлять через Register и отключать
+// для отдельного профиля.
+package recommendations
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"training-tracker/backend/internal/models"
+)
+
+// Важность рекомендации
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Категории правил
+const (
+	CategoryLoad        = "load"
+	CategoryProgression = "progression"
+	CategoryBody        = "body"
+	CategoryBalance     = "balance"
+	CategoryFrequency   = "frequency"
+	CategoryVariety     = "variety"
+	CategoryGoal        = "goal"
+	CategoryReadiness   = "readiness"
+	CategoryGeneral     = "general"
+)
+
+// Языки сообщений
+const (
+	LocaleRU      = "ru"
+	LocaleEN      = "en"
+	DefaultLocale = LocaleRU
+)
+
+var severityOrder = map[string]int{SeverityCritical: 0, SeverityWarning: 1, SeverityInfo: 2}
+
+// Input - аналитика профиля, по которой срабатывают правила. Правила, которым
+// не хватает данных (например, Progression nil), не срабатывают.
+type Input struct {
+	Profile       models.Profile
+	Trainings     int // записей журнала старого формата
+	MuscleBalance []models.MuscleGroupStat
+	ExerciseStats []models.ExerciseStat
+	Load          *models.TrainingLoadDay
+	Progression   *models.ProgressionResponse
+	Readiness     *models.ReadinessAnalysisResponse
+}
+
+// Finding - срабатывание правила
+type Finding struct {
+	Exercise string                 // пусто - находка для всего профиля
+	Evidence map[string]interface{} // данные для шаблона сообщения и ответа
+}
+
+// Rule - правило рекомендаций
+type Rule struct {
+	ID       string
+	Severity string
+	Category string
+	// Messages - шаблоны text/template по языкам, данные шаблона - Evidence находки.
+	// Шаблон для DefaultLocale обязателен.
+	Messages map[string]string
+	// Fallback - правило срабатывает, только если не сработало ни одно другое
+	Fallback bool
+	Evaluate func(Input) []Finding
+
+	templates map[string]*template.Template
+}
+
+// Options - какие правила проверять и на каком языке писать сообщения
+type Options struct {
+	Locale     string
+	Disabled   []string // ID правил, отключенных в профиле
+	Categories []string // пусто - все категории
+}
+
+var registry []Rule
+
+// Register добавляет правило. Повторный ID и ошибка в шаблоне - ошибка программиста,
+// поэтому Register паникует.
+func Register(rule Rule) {
+	if _, ok := Find(rule.ID); ok {
+		panic(fmt.Sprintf("recommendations: duplicate rule %q", rule.ID))
+	}
+	if _, ok := rule.Messages[DefaultLocale]; !ok {
+		panic(fmt.Sprintf("recommendations: rule %q has no %s message", rule.ID, DefaultLocale))
+	}
+	rule.templates = make(map[string]*template.Template, len(rule.Messages))
+	for locale, message := range rule.Messages {
+		rule.templates[locale] = template.Must(template.New(rule.ID).Funcs(templateFuncs(locale)).Parse(message))
+	}
+	registry = append(registry, rule)
+}
+
+// Rules - зарегистрированные правила в порядке регистрации
+func Rules() []Rule {
+	return append([]Rule(nil), registry...)
+}
+
+// Find ищет правило по ID
+func Find(id string) (Rule, bool) {
+	for _, rule := range registry {
+		if rule.ID == id {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// SupportedLocale сообщает, есть ли у правил сообщения на этом языке
+func SupportedLocale(locale string) bool {
+	return locale == LocaleRU || locale == LocaleEN
+}
+
+// Evaluate проверяет включенные правила и возвращает рекомендации: сначала
+// critical, затем warning и info, внутри важности - в порядке регистрации
+func Evaluate(in Input, opts Options) []models.Recommendation {
+	disabled := make(map[string]bool, len(opts.Disabled))
+	for _, id := range opts.Disabled {
+		disabled[id] = true
+	}
+	categories := make(map[string]bool, len(opts.Categories))
+	for _, category := range opts.Categories {
+		categories[category] = true
+	}
+	locale := opts.Locale
+	if !SupportedLocale(locale) {
+		locale = DefaultLocale
+	}
+
+	result := []models.Recommendation{}
+	var fallbacks []Rule
+	for _, rule := range registry {
+		if disabled[rule.ID] || (len(categories) > 0 && !categories[rule.Category]) {
+			continue
+		}
+		if rule.Fallback {
+			fallbacks = append(fallbacks, rule)
+			continue
+		}
+		for _, finding := range rule.Evaluate(in) {
+			result = append(result, rule.recommendation(finding, locale))
+		}
+	}
+	if len(result) == 0 {
+		for _, rule := range fallbacks {
+			for _, finding := range rule.Evaluate(in) {
+				result = append(result, rule.recommendation(finding, locale))
+			}
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return severityOrder[result[i].Severity] < severityOrder[result[j].Severity]
+	})
+	return result
+}
+
+func (r Rule) recommendation(finding Finding, locale string) models.Recommendation {
+	evidence := finding.Evidence
+	if evidence == nil {
+		evidence = map[string]interface{}{}
+	}
+	return models.Recommendation{
+		ID:       r.ID,
+		Severity: r.Severity,
+		Category: r.Category,
+		Exercise: finding.Exercise,
+		Message:  r.message(evidence, locale),
+		Evidence: evidence,
+	}
+}
+
+// message - сообщение на нужном языке; без перевода - на языке по умолчанию
+func (r Rule) message(evidence map[string]interface{}, locale string) string {
+	tmpl, ok := r.templates[locale]
+	if !ok {
+		tmpl = r.templates[DefaultLocale]
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, evidence); err != nil {
+		return r.ID
+	}
+	return buf.String()
+}
+
+// templateFuncs - функции шаблонов: phrase переводит ключ (признак усталости, цель),
+// phrases переводит список ключей и склеивает через запятую, join склеивает строки
+func templateFuncs(locale string) template.FuncMap {
+	phrase := func(key string) string {
+		if text, ok := phrases[locale][key]; ok {
+			return text
+		}
+		if text, ok := phrases[DefaultLocale][key]; ok {
+			return text
+		}
+		return key
+	}
+	return template.FuncMap{
+		"phrase": phrase,
+		"phrases": func(keys []string) string {
+			texts := make([]string, len(keys))
+			for i, key := range keys {
+				texts[i] = phrase(key)
+			}
+			return strings.Join(texts, ", ")
+		},
+		"join": func(values []string) string { return strings.Join(values, ", ") },
+	}
+}
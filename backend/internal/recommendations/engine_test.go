@@ -0,0 +1,128 @@
+package recommendations
+
+import (
+	"reflect"
+	"testing"
+
+	"training-tracker/backend/internal/models"
+)
+
+func float(v float64) *float64 { return &v }
+
+// quietInput - аналитика, на которую не срабатывает ни одно правило, кроме запасного
+func quietInput() Input {
+	return Input{
+		Trainings:     minTrainings,
+		ExerciseStats: make([]models.ExerciseStat, minExercises),
+	}
+}
+
+func ruleIDs(recs []models.Recommendation) []string {
+	ids := []string{}
+	for _, r := range recs {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestEvaluate(t *testing.T) {
+	weight, height := 50.0, 180
+	underweight := models.Profile{Weight: &weight, Height: &height}
+
+	spike := quietInput()
+	spike.Load = &models.TrainingLoadDay{ACWR: float(1.8)}
+	mixed := Input{Profile: underweight, Load: &models.TrainingLoadDay{ACWR: float(1.8)}}
+
+	tests := []struct {
+		name string
+		in   Input
+		opts Options
+		want []string
+	}{
+		{"only the fallback", quietInput(), Options{}, []string{"general.all_good"}},
+		{"fallback skipped once a rule fires", spike, Options{}, []string{"load.acwr_spike"}},
+		{"fallback outside the categories", quietInput(), Options{Categories: []string{CategoryLoad}}, []string{}},
+		{"disabled rule falls back", spike, Options{Disabled: []string{"load.acwr_spike"}}, []string{"general.all_good"}},
+		{"disabled fallback", quietInput(), Options{Disabled: []string{"general.all_good"}}, []string{}},
+		{"severity order", mixed, Options{}, []string{"load.acwr_spike", "body.bmi_low", "frequency.low", "variety.low"}},
+		{"categories", mixed, Options{Categories: []string{CategoryBody, CategoryVariety}}, []string{"body.bmi_low", "variety.low"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruleIDs(Evaluate(tt.in, tt.opts)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateLocale(t *testing.T) {
+	in := quietInput()
+	in.Load = &models.TrainingLoadDay{ACWR: float(1.75)}
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{LocaleRU, "⚠️ Резкий рост нагрузки: за последнюю неделю она в 1.8 раза выше средней за 4 недели. Снизьте объем в ближайшие дни, чтобы не получить травму."},
+		{LocaleEN, "⚠️ Training load spike: last week's load is 1.8x your 4-week average. Cut volume over the next few days to avoid injury."},
+		{"", "⚠️ Резкий рост нагрузки: за последнюю неделю она в 1.8 раза выше средней за 4 недели. Снизьте объем в ближайшие дни, чтобы не получить травму."},
+		{"de", "⚠️ Резкий рост нагрузки: за последнюю неделю она в 1.8 раза выше средней за 4 недели. Снизьте объем в ближайшие дни, чтобы не получить травму."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			recs := Evaluate(in, Options{Locale: tt.locale})
+			if len(recs) != 1 || recs[0].Message != tt.want {
+				t.Errorf("Evaluate(%q) = %+v, want %q", tt.locale, recs, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateReadiness(t *testing.T) {
+	in := Input{Readiness: &models.ReadinessAnalysisResponse{Factors: []models.ReadinessFactor{
+		{Factor: "sleepHours", Correlations: []models.ReadinessCorrelation{
+			{Metric: "e1rmChange", Coefficient: float(0.62), Samples: 12, Strength: "strong"},
+			{Metric: "volume", Coefficient: float(0.15), Samples: 12, Strength: "weak"},
+		}},
+		{Factor: "stress", Correlations: []models.ReadinessCorrelation{
+			{Metric: "e1rmChange", Samples: 3, Strength: "none"},
+			{Metric: "volume", Coefficient: float(-0.41), Samples: 9, Strength: "moderate"},
+		}},
+	}}}
+
+	tests := []struct {
+		locale string
+		want   []string
+	}{
+		{LocaleRU, []string{
+			"📈 Чем выше продолжительность сна, тем больше прирост расчетного 1ПМ (r = 0.62, тренировок: 12)",
+			"📈 Чем выше стресс, тем меньше объем тренировки (r = -0.41, тренировок: 9)",
+		}},
+		{LocaleEN, []string{
+			"📈 The higher your sleep duration, the bigger your estimated 1RM gain (r = 0.62, sessions: 12)",
+			"📈 The higher your stress, the lower your session volume (r = -0.41, sessions: 9)",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			recs := Evaluate(in, Options{Locale: tt.locale, Categories: []string{CategoryReadiness}})
+			var got []string
+			for _, r := range recs {
+				got = append(got, r.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("messages = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Register did not panic on a duplicate rule")
+		}
+	}()
+	Register(Rule{ID: "general.all_good", Messages: map[string]string{LocaleRU: "x"}})
+}
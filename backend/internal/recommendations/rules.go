@@ -0,0 +1,400 @@
+package recommendations
+
+import (
+	"math"
+
+	"training-tracker/backend/internal/models"
+	"training-tracker/backend/internal/progression"
+)
+
+// Пороги встроенных правил
+const (
+	// acwrSpike - ACWR, выше которого рост нагрузки считается резким
+	acwrSpike = 1.5
+	// monotonyHigh - монотонность, выше которой неделя считается однообразной
+	monotonyHigh = 2.0
+	bmiLow       = 18.5
+	bmiHigh      = 25.0
+	// laggingMuscleShare - группа мышц отстает, если ее объем меньше этой доли от самой нагруженной
+	laggingMuscleShare = 0.3
+	minTrainings       = 8
+	minExercises       = 5
+	// swapFactor - застой дольше swapFactor × N тренировок - повод заменить упражнение
+	swapFactor = 2
+)
+
+// notableCorrelations - сила связи готовности с результатом, о которой стоит сказать
+var notableCorrelations = map[string]bool{"moderate": true, "strong": true}
+
+// phrases - переводы ключей из данных находок для функций шаблонов phrase и phrases
+var phrases = map[string]map[string]string{
+	LocaleRU: {
+		progression.SignalDecliningLifts: "падают результаты в нескольких упражнениях",
+		progression.SignalLoadSpike:      "резко выросла нагрузка",
+		progression.SignalMonotony:       "однообразная нагрузка",
+		progression.SignalHighRPE:        "тренировки на пределе (RPE 9+)",
+		"goal.strength":                  "💪 Для развития силы фокусируйтесь на весах 85-95% от 1ПМ с 1-5 повторениями.",
+		"goal.mass":                      "🏋️ Для роста массы оптимальны веса 70-85% от 1ПМ с 6-12 повторениями.",
+		"goal.endurance":                 "🏃 Для развития выносливости используйте веса 50-70% от 1ПМ с 15-20+ повторениями.",
+		"goal.weight_loss":               "🔥 Для похудения сочетайте силовые тренировки с кардио и контролируйте калорийность.",
+		"readiness.sleepHours":           "продолжительность сна",
+		"readiness.sleepQuality":         "качество сна",
+		"readiness.stress":               "стресс",
+		"readiness.hrv":                  "вариабельность пульса",
+		"readiness.restingHeartRate":     "пульс в покое",
+		"readiness.energy":               "энергия",
+		"readiness.mood":                 "настроение",
+		"readiness.soreness":             "болезненность мышц",
+		"readiness.e1rmChange.more":      "больше прирост расчетного 1ПМ",
+		"readiness.e1rmChange.less":      "меньше прирост расчетного 1ПМ",
+		"readiness.volume.more":          "больше объем тренировки",
+		"readiness.volume.less":          "меньше объем тренировки",
+	},
+	LocaleEN: {
+		progression.SignalDecliningLifts: "several lifts are going down",
+		progression.SignalLoadSpike:      "training load spiked",
+		progression.SignalMonotony:       "monotonous load",
+		progression.SignalHighRPE:        "sessions at the limit (RPE 9+)",
+		"goal.strength":                  "💪 For strength, focus on 85-95% of your 1RM for 1-5 reps.",
+		"goal.mass":                      "🏋️ For muscle gain, 70-85% of your 1RM for 6-12 reps works best.",
+		"goal.endurance":                 "🏃 For endurance, use 50-70% of your 1RM for 15-20+ reps.",
+		"goal.weight_loss":               "🔥 For weight loss, combine strength training with cardio and watch your calories.",
+		"readiness.sleepHours":           "sleep duration",
+		"readiness.sleepQuality":         "sleep quality",
+		"readiness.stress":               "stress",
+		"readiness.hrv":                  "heart rate variability",
+		"readiness.restingHeartRate":     "resting heart rate",
+		"readiness.energy":               "energy",
+		"readiness.mood":                 "mood",
+		"readiness.soreness":             "muscle soreness",
+		"readiness.e1rmChange.more":      "bigger your estimated 1RM gain",
+		"readiness.e1rmChange.less":      "smaller your estimated 1RM gain",
+		"readiness.volume.more":          "higher your session volume",
+		"readiness.volume.less":          "lower your session volume",
+	},
+}
+
+func init() {
+	Register(Rule{
+		ID:       "load.acwr_spike",
+		Severity: SeverityCritical,
+		Category: CategoryLoad,
+		Messages: map[string]string{
+			LocaleRU: `⚠️ Резкий рост нагрузки: за последнюю неделю она в {{printf "%.1f" .acwr}} раза выше средней за 4 недели. Снизьте объем в ближайшие дни, чтобы не получить травму.`,
+			LocaleEN: `⚠️ Training load spike: last week's load is {{printf "%.1f" .acwr}}x your 4-week average. Cut volume over the next few days to avoid injury.`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if in.Load == nil || in.Load.ACWR == nil || *in.Load.ACWR <= acwrSpike {
+				return nil
+			}
+			return []Finding{{Evidence: map[string]interface{}{
+				"acwr": *in.Load.ACWR, "acute": in.Load.Acute, "chronic": in.Load.Chronic, "threshold": acwrSpike,
+			}}}
+		},
+	})
+
+	Register(Rule{
+		ID:       "load.monotony",
+		Severity: SeverityWarning,
+		Category: CategoryLoad,
+		Messages: map[string]string{
+			LocaleRU: `🔁 Нагрузка слишком однообразна (монотонность {{printf "%.1f" .monotony}}). Чередуйте тяжелые и легкие дни и оставляйте дни отдыха.`,
+			LocaleEN: `🔁 Your training load is too monotonous (monotony {{printf "%.1f" .monotony}}). Alternate hard and easy days and keep rest days.`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if in.Load == nil || in.Load.Monotony == nil || *in.Load.Monotony <= monotonyHigh {
+				return nil
+			}
+			return []Finding{{Evidence: map[string]interface{}{
+				"monotony": *in.Load.Monotony, "strain": in.Load.Strain, "threshold": monotonyHigh,
+			}}}
+		},
+	})
+
+	Register(Rule{
+		ID:       "progression.deload_week",
+		Severity: SeverityWarning,
+		Category: CategoryProgression,
+		Messages: map[string]string{
+			LocaleRU: `🛌 Пора сделать разгрузочную неделю: снизьте объем на 40-50% и рабочие веса на 10-15%, сохранив технику и частоту тренировок. Признаки усталости: {{phrases .signals}}.`,
+			LocaleEN: `🛌 Time for a deload week: cut volume by 40-50% and working weights by 10-15%, keeping technique and training frequency. Signs of fatigue: {{phrases .signals}}.`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if in.Progression == nil || in.Progression.Fatigue.Level != progression.FatigueHigh {
+				return nil
+			}
+			fatigue := in.Progression.Fatigue
+			return []Finding{{Evidence: map[string]interface{}{
+				"signals":            fatigue.Signals,
+				"acwr":               fatigue.ACWR,
+				"monotony":           fatigue.Monotony,
+				"recentRPE":          fatigue.RecentRPE,
+				"decliningExercises": fatigue.DecliningExercises,
+			}}}
+		},
+	})
+
+	// Спад в отдельном упражнении лечится облегченной неделей в нем, если вся программа
+	// не уходит в разгрузку
+	Register(Rule{
+		ID:       "progression.exercise_deload",
+		Severity: SeverityWarning,
+		Category: CategoryProgression,
+		Messages: map[string]string{
+			LocaleRU: `📉 Расчетный 1ПМ в упражнении «{{.exercise}}» снизился на {{printf "%.1f" .decline}}%. Проведите одну облегченную неделю в нем (вес -10%, на 1-2 подхода меньше), затем вернитесь к прогрессии.`,
+			LocaleEN: `📉 Your estimated 1RM in {{.exercise}} dropped by {{printf "%.1f" .decline}}%. Take one lighter week on it (10% less weight, 1-2 fewer sets), then resume progression.`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if in.Progression == nil || in.Progression.Fatigue.Level == progression.FatigueHigh {
+				return nil
+			}
+			var findings []Finding
+			for _, t := range in.Progression.Exercises {
+				if t.Status == progression.StatusDeclining {
+					evidence := trendEvidence(t)
+					evidence["decline"] = -t.OneRMChange
+					findings = append(findings, Finding{Exercise: t.Exercise, Evidence: evidence})
+				}
+			}
+			return findings
+		},
+	})
+
+	// Долгий застой - повод заменить упражнение, короткий - сменить диапазон повторений
+	Register(Rule{
+		ID:       "progression.exercise_swap",
+		Severity: SeverityInfo,
+		Category: CategoryProgression,
+		Messages: map[string]string{
+			LocaleRU: `🔄 Упражнение «{{.exercise}}» застряло (тренировок без нового лучшего результата: {{.sessionsSincePR}}). Замените его на 4-6 недель похожим: {{join .alternatives}}.`,
+			LocaleEN: `🔄 {{.exercise}} has stalled ({{.sessionsSincePR}} sessions without a new best). Swap it for a similar exercise for 4-6 weeks: {{join .alternatives}}.`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if in.Progression == nil {
+				return nil
+			}
+			var findings []Finding
+			for _, t := range in.Progression.Exercises {
+				if t.Status == progression.StatusStalled && needsSwap(t, in.Progression.StallSessions) {
+					evidence := trendEvidence(t)
+					evidence["alternatives"] = t.Alternatives
+					findings = append(findings, Finding{Exercise: t.Exercise, Evidence: evidence})
+				}
+			}
+			return findings
+		},
+	})
+
+	Register(Rule{
+		ID:       "progression.rep_range",
+		Severity: SeverityInfo,
+		Category: CategoryProgression,
+		Messages: map[string]string{
+			LocaleRU: `🎯 Упражнение «{{.exercise}}» застряло (тренировок без нового лучшего результата: {{.sessionsSincePR}}). Смените диапазон повторений на 3-4 недели: {{.suggestedReps}} вместо ~{{printf "%.0f" .averageReps}}.`,
+			LocaleEN: `🎯 {{.exercise}} has stalled ({{.sessionsSincePR}} sessions without a new best). Change the rep range for 3-4 weeks: {{.suggestedReps}} instead of ~{{printf "%.0f" .averageReps}}.`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if in.Progression == nil {
+				return nil
+			}
+			var findings []Finding
+			for _, t := range in.Progression.Exercises {
+				if t.Status == progression.StatusStalled && !needsSwap(t, in.Progression.StallSessions) {
+					evidence := trendEvidence(t)
+					evidence["suggestedReps"] = suggestedReps(t.AverageReps)
+					findings = append(findings, Finding{Exercise: t.Exercise, Evidence: evidence})
+				}
+			}
+			return findings
+		},
+	})
+
+	Register(Rule{
+		ID:       "body.bmi_low",
+		Severity: SeverityWarning,
+		Category: CategoryBody,
+		Messages: map[string]string{
+			LocaleRU: `⚠️ Ваш BMI ниже нормы. Рекомендуется увеличить калорийность питания и сосредоточиться на наборе мышечной массы.`,
+			LocaleEN: `⚠️ Your BMI is below the normal range. Consider eating more and focusing on building muscle.`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if bmi, ok := profileBMI(in.Profile); ok && bmi < bmiLow {
+				return []Finding{{Evidence: map[string]interface{}{"bmi": bmi, "threshold": bmiLow}}}
+			}
+			return nil
+		},
+	})
+
+	Register(Rule{
+		ID:       "body.bmi_high",
+		Severity: SeverityWarning,
+		Category: CategoryBody,
+		Messages: map[string]string{
+			LocaleRU: `⚠️ Ваш BMI выше нормы. Рекомендуется добавить кардио и контролировать калорийность питания.`,
+			LocaleEN: `⚠️ Your BMI is above the normal range. Consider adding cardio and watching your calories.`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if bmi, ok := profileBMI(in.Profile); ok && bmi > bmiHigh {
+				return []Finding{{Evidence: map[string]interface{}{"bmi": bmi, "threshold": bmiHigh}}}
+			}
+			return nil
+		},
+	})
+
+	Register(Rule{
+		ID:       "balance.lagging_muscle_group",
+		Severity: SeverityInfo,
+		Category: CategoryBalance,
+		Messages: map[string]string{
+			LocaleRU: `💪 Уделите больше внимания группе мышц: {{.muscleGroup}} (всего {{printf "%.1f" .percentage}}% от общего объема)`,
+			LocaleEN: `💪 Give more attention to this muscle group: {{.muscleGroup}} (only {{printf "%.1f" .percentage}}% of total volume)`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if len(in.MuscleBalance) == 0 {
+				return nil
+			}
+			// MuscleBalance отсортирован по убыванию объема
+			maxVolume := in.MuscleBalance[0].Volume
+			var findings []Finding
+			for _, mg := range in.MuscleBalance {
+				if mg.Volume < maxVolume*laggingMuscleShare {
+					findings = append(findings, Finding{Evidence: map[string]interface{}{
+						"muscleGroup": mg.MuscleGroup, "percentage": mg.Percentage, "volume": mg.Volume, "maxVolume": maxVolume,
+					}})
+				}
+			}
+			return findings
+		},
+	})
+
+	Register(Rule{
+		ID:       "frequency.low",
+		Severity: SeverityInfo,
+		Category: CategoryFrequency,
+		Messages: map[string]string{
+			LocaleRU: `📅 Рекомендуется увеличить частоту тренировок до 3-4 раз в неделю для лучших результатов.`,
+			LocaleEN: `📅 Train 3-4 times a week for better results.`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if in.Trainings < minTrainings {
+				return []Finding{{Evidence: map[string]interface{}{"trainings": in.Trainings, "threshold": minTrainings}}}
+			}
+			return nil
+		},
+	})
+
+	Register(Rule{
+		ID:       "variety.low",
+		Severity: SeverityInfo,
+		Category: CategoryVariety,
+		Messages: map[string]string{
+			LocaleRU: `🎯 Добавьте больше разнообразия в программу. Рекомендуется выполнять 8-12 различных упражнений.`,
+			LocaleEN: `🎯 Add more variety to your program. Aim for 8-12 different exercises.`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if len(in.ExerciseStats) < minExercises {
+				return []Finding{{Evidence: map[string]interface{}{"exercises": len(in.ExerciseStats), "threshold": minExercises}}}
+			}
+			return nil
+		},
+	})
+
+	Register(Rule{
+		ID:       "goal.training_zone",
+		Severity: SeverityInfo,
+		Category: CategoryGoal,
+		Messages: map[string]string{
+			LocaleRU: `{{phrase (print "goal." .goal)}}`,
+			LocaleEN: `{{phrase (print "goal." .goal)}}`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if _, ok := phrases[DefaultLocale]["goal."+in.Profile.Goal]; !ok {
+				return nil
+			}
+			return []Finding{{Evidence: map[string]interface{}{"goal": in.Profile.Goal}}}
+		},
+	})
+
+	// Заметная связь показателя готовности с результатом тренировок
+	Register(Rule{
+		ID:       "readiness.correlation",
+		Severity: SeverityInfo,
+		Category: CategoryReadiness,
+		Messages: map[string]string{
+			LocaleRU: `📈 Чем выше {{phrase (print "readiness." .factor)}}, тем {{phrase (print "readiness." .metric "." .direction)}} (r = {{printf "%.2f" .coefficient}}, тренировок: {{.samples}})`,
+			LocaleEN: `📈 The higher your {{phrase (print "readiness." .factor)}}, the {{phrase (print "readiness." .metric "." .direction)}} (r = {{printf "%.2f" .coefficient}}, sessions: {{.samples}})`,
+		},
+		Evaluate: func(in Input) []Finding {
+			if in.Readiness == nil {
+				return nil
+			}
+			var findings []Finding
+			for _, f := range in.Readiness.Factors {
+				for _, corr := range f.Correlations {
+					if corr.Coefficient == nil || !notableCorrelations[corr.Strength] {
+						continue
+					}
+					direction := "more"
+					if *corr.Coefficient < 0 {
+						direction = "less"
+					}
+					findings = append(findings, Finding{Evidence: map[string]interface{}{
+						"factor": f.Factor, "metric": corr.Metric, "direction": direction,
+						"coefficient": *corr.Coefficient, "strength": corr.Strength, "samples": corr.Samples,
+					}})
+				}
+			}
+			return findings
+		},
+	})
+
+	Register(Rule{
+		ID:       "general.all_good",
+		Severity: SeverityInfo,
+		Category: CategoryGeneral,
+		Fallback: true,
+		Messages: map[string]string{
+			LocaleRU: `✅ Отличная работа! Продолжайте в том же духе.`,
+			LocaleEN: `✅ Great work! Keep it up.`,
+		},
+		Evaluate: func(Input) []Finding { return []Finding{{}} },
+	})
+}
+
+func profileBMI(profile models.Profile) (float64, bool) {
+	if profile.Weight == nil || profile.Height == nil || *profile.Height <= 0 {
+		return 0, false
+	}
+	heightM := float64(*profile.Height) / 100.0
+	return math.Round(*profile.Weight/(heightM*heightM)*10) / 10, true
+}
+
+// trendEvidence - данные динамики упражнения для находок правил progression
+func trendEvidence(t models.ExerciseTrend) map[string]interface{} {
+	return map[string]interface{}{
+		"exercise":        t.Exercise,
+		"sessionsSincePR": t.SessionsSincePR,
+		"bestOneRM":       t.BestOneRM,
+		"recentOneRM":     t.RecentOneRM,
+		"oneRMChange":     t.OneRMChange,
+		"volumeChange":    t.VolumeChange,
+		"averageReps":     t.AverageReps,
+	}
+}
+
+func needsSwap(t models.ExerciseTrend, stallSessions int) bool {
+	return t.SessionsSincePR >= swapFactor*stallSessions && len(t.Alternatives) > 0
+}
+
+// suggestedReps - новый диапазон повторений: после тяжелой работы - средний,
+// после среднего - силовой, после многоповторной - средний
+func suggestedReps(averageReps float64) string {
+	switch {
+	case averageReps <= 5:
+		return "6-8"
+	case averageReps <= 10:
+		return "3-5"
+	default:
+		return "6-8"
+	}
+}
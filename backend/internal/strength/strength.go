@@ -0,0 +1,168 @@
+// Package strength считает силовые очки (Wilks, DOTS, IPF GoodLift) и уровень
+// атлета по нормативам относительно веса тела.
+package strength
+
+import "math"
+
+// Соревновательные движения
+const (
+	LiftSquat    = "squat"
+	LiftBench    = "bench"
+	LiftDeadlift = "deadlift"
+)
+
+// Lifts - движения троеборья в порядке выступления
+var Lifts = []string{LiftSquat, LiftBench, LiftDeadlift}
+
+// Пол для формул; для остальных значений профиля очки не считаются
+const (
+	Male   = "male"
+	Female = "female"
+)
+
+// bounds - вес тела, за пределами которого формулы не определены
+type bounds struct{ min, max float64 }
+
+func (b bounds) clamp(v float64) float64 {
+	return math.Min(math.Max(v, b.min), b.max)
+}
+
+// Коэффициенты Wilks (оригинальная формула), от свободного члена к x^5
+var wilksCoefficients = map[string][]float64{
+	Male:   {-216.0475144, 16.2606339, -0.002388645, -0.00113732, 7.01863e-06, -1.291e-08},
+	Female: {594.31747775582, -27.23842536447, 0.82112226871, -0.00930733913, 4.731582e-05, -9.054e-08},
+}
+
+var wilksBounds = map[string]bounds{
+	Male:   {40, 201.9},
+	Female: {26.51, 154.53},
+}
+
+// Коэффициенты DOTS, от свободного члена к x^4
+var dotsCoefficients = map[string][]float64{
+	Male:   {-307.75076, 24.0900756, -0.1918759221, 0.0007391293, -0.000001093},
+	Female: {-57.96288, 13.6175032, -0.1126655495, 0.0005158568, -0.0000010706},
+}
+
+var dotsBounds = map[string]bounds{
+	Male:   {40, 210},
+	Female: {40, 150},
+}
+
+// Коэффициенты IPF GoodLift (2020) для классического троеборья и классического жима: A, B, C
+var (
+	ipfTotalCoefficients = map[string][3]float64{
+		Male:   {1199.72839, 1025.18162, 0.00921},
+		Female: {610.32796, 1045.59282, 0.03048},
+	}
+	ipfBenchCoefficients = map[string][3]float64{
+		Male:   {320.98041, 281.40258, 0.01008},
+		Female: {142.40398, 442.52671, 0.04724},
+	}
+)
+
+// Supported сообщает, считаются ли очки для пола
+func Supported(gender string) bool {
+	return gender == Male || gender == Female
+}
+
+// Wilks - очки Wilks для поднятого веса (сумма или одно движение) в кг
+func Wilks(lifted, bodyWeight float64, gender string) float64 {
+	coefficients, ok := wilksCoefficients[gender]
+	if !ok || lifted <= 0 || bodyWeight <= 0 {
+		return 0
+	}
+	return round(lifted * 500 / polynomial(coefficients, wilksBounds[gender].clamp(bodyWeight)))
+}
+
+// DOTS - очки DOTS для поднятого веса в кг
+func DOTS(lifted, bodyWeight float64, gender string) float64 {
+	coefficients, ok := dotsCoefficients[gender]
+	if !ok || lifted <= 0 || bodyWeight <= 0 {
+		return 0
+	}
+	return round(lifted * 500 / polynomial(coefficients, dotsBounds[gender].clamp(bodyWeight)))
+}
+
+// IPFGoodLift - очки IPF GL за сумму троеборья
+func IPFGoodLift(total, bodyWeight float64, gender string) float64 {
+	return ipfPoints(ipfTotalCoefficients, total, bodyWeight, gender)
+}
+
+// IPFGoodLiftBench - очки IPF GL за жим лежа
+func IPFGoodLiftBench(bench, bodyWeight float64, gender string) float64 {
+	return ipfPoints(ipfBenchCoefficients, bench, bodyWeight, gender)
+}
+
+func ipfPoints(table map[string][3]float64, lifted, bodyWeight float64, gender string) float64 {
+	k, ok := table[gender]
+	if !ok || lifted <= 0 || bodyWeight < 35 {
+		return 0
+	}
+	return round(lifted * 100 / (k[0] - k[1]*math.Exp(-k[2]*bodyWeight)))
+}
+
+func polynomial(coefficients []float64, x float64) float64 {
+	var sum float64
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		sum = sum*x + coefficients[i]
+	}
+	return sum
+}
+
+// Уровни нормативов
+const (
+	LevelUntrained    = "untrained"
+	LevelBeginner     = "beginner"
+	LevelNovice       = "novice"
+	LevelIntermediate = "intermediate"
+	LevelAdvanced     = "advanced"
+	LevelElite        = "elite"
+)
+
+// levels - уровни по возрастанию, нормативы задаются для всех, кроме untrained
+var levels = []string{LevelBeginner, LevelNovice, LevelIntermediate, LevelAdvanced, LevelElite}
+
+// standards - 1ПМ в долях веса тела, с которых начинается каждый уровень
+var standards = map[string]map[string][]float64{
+	Male: {
+		LiftSquat:    {0.75, 1.25, 1.5, 2.25, 2.75},
+		LiftBench:    {0.5, 0.75, 1.25, 1.75, 2.0},
+		LiftDeadlift: {1.0, 1.5, 2.0, 2.5, 3.0},
+	},
+	Female: {
+		LiftSquat:    {0.5, 0.75, 1.25, 1.5, 2.0},
+		LiftBench:    {0.25, 0.5, 0.75, 1.0, 1.5},
+		LiftDeadlift: {0.5, 1.0, 1.25, 1.75, 2.5},
+	},
+}
+
+// Standing - уровень атлета в движении
+type Standing struct {
+	Ratio           float64 // 1ПМ / вес тела
+	Level           string
+	NextLevel       string  // пусто для elite
+	NextLevelWeight float64 // 1ПМ, нужный для следующего уровня при текущем весе тела
+}
+
+// Standard определяет уровень по 1ПМ и весу тела
+func Standard(lift string, oneRM, bodyWeight float64, gender string) (Standing, bool) {
+	thresholds, ok := standards[gender][lift]
+	if !ok || bodyWeight <= 0 {
+		return Standing{}, false
+	}
+	standing := Standing{Ratio: round(oneRM / bodyWeight), Level: LevelUntrained}
+	for i, threshold := range thresholds {
+		if oneRM/bodyWeight < threshold {
+			standing.NextLevel = levels[i]
+			standing.NextLevelWeight = round(threshold * bodyWeight)
+			break
+		}
+		standing.Level = levels[i]
+	}
+	return standing, true
+}
+
+func round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
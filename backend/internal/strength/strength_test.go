@@ -0,0 +1,80 @@
+package strength
+
+import "testing"
+
+// Ожидаемые очки посчитаны по опубликованным коэффициентам Wilks, DOTS и IPF GL (2020)
+// для суммы 500 кг (жим - 150 кг) и округлены до сотых
+func TestScores(t *testing.T) {
+	tests := []struct {
+		name       string
+		score      func(lifted, bodyWeight float64, gender string) float64
+		lifted     float64
+		bodyWeight float64
+		gender     string
+		want       float64
+	}{
+		{"wilks male 75", Wilks, 500, 75, Male, 356.28},
+		{"wilks male 100", Wilks, 500, 100, Male, 304.29},
+		{"wilks female 60", Wilks, 500, 60, Female, 557.44},
+		{"wilks female 90", Wilks, 500, 90, Female, 432.03},
+		{"dots male 75", DOTS, 500, 75, Male, 358.71},
+		{"dots male 100", DOTS, 500, 100, Male, 307.76},
+		{"dots female 60", DOTS, 500, 60, Female, 554.27},
+		{"dots female 90", DOTS, 500, 90, Female, 445.76},
+		{"ipf gl male 90", IPFGoodLift, 500, 90, Male, 66.47},
+		{"ipf gl male 120", IPFGoodLift, 500, 120, Male, 58.12},
+		{"ipf gl female 60", IPFGoodLift, 500, 60, Female, 113.02},
+		{"ipf gl bench male 75", IPFGoodLiftBench, 150, 75, Male, 79.43},
+		{"ipf gl bench male 100", IPFGoodLiftBench, 150, 100, Male, 68.72},
+
+		// Вес тела вне области формулы приводится к ее границе
+		{"wilks below the bounds", Wilks, 500, 30, Male, Wilks(500, 40, Male)},
+		{"wilks above the bounds", Wilks, 500, 250, Male, Wilks(500, 201.9, Male)},
+		{"dots above the bounds", DOTS, 500, 250, Female, DOTS(500, 150, Female)},
+
+		{"ipf gl below 35 kg", IPFGoodLift, 500, 30, Male, 0},
+		{"unsupported gender", Wilks, 500, 80, "other", 0},
+		{"nothing lifted", DOTS, 0, 80, Male, 0},
+		{"no body weight", Wilks, 500, 0, Female, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.score(tt.lifted, tt.bodyWeight, tt.gender); got != tt.want {
+				t.Errorf("score(%v, %v, %q) = %v, want %v", tt.lifted, tt.bodyWeight, tt.gender, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStandard(t *testing.T) {
+	tests := []struct {
+		name       string
+		lift       string
+		oneRM      float64
+		bodyWeight float64
+		gender     string
+		want       Standing
+	}{
+		{"untrained", LiftBench, 30, 80, Male, Standing{Ratio: 0.38, Level: LevelUntrained, NextLevel: LevelBeginner, NextLevelWeight: 40}},
+		{"exactly on a threshold", LiftSquat, 120, 80, Male, Standing{Ratio: 1.5, Level: LevelIntermediate, NextLevel: LevelAdvanced, NextLevelWeight: 180}},
+		{"between levels", LiftDeadlift, 100, 60, Female, Standing{Ratio: 1.67, Level: LevelIntermediate, NextLevel: LevelAdvanced, NextLevelWeight: 105}},
+		{"elite", LiftBench, 170, 80, Male, Standing{Ratio: 2.13, Level: LevelElite}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Standard(tt.lift, tt.oneRM, tt.bodyWeight, tt.gender)
+			if !ok || got != tt.want {
+				t.Errorf("Standard = %+v, %v, want %+v", got, ok, tt.want)
+			}
+		})
+	}
+
+	for _, args := range []struct {
+		lift, gender string
+		bodyWeight   float64
+	}{{"curl", Male, 80}, {LiftSquat, "other", 80}, {LiftSquat, Male, 0}} {
+		if _, ok := Standard(args.lift, 100, args.bodyWeight, args.gender); ok {
+			t.Errorf("Standard(%q, %q, %v) is defined", args.lift, args.gender, args.bodyWeight)
+		}
+	}
+}
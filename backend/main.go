@@ -3,10 +3,14 @@ package main
 import (
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
+	"training-tracker/backend/internal/audit"
 	"training-tracker/backend/internal/config"
 	approuter "training-tracker/backend/internal/http"
+	"training-tracker/backend/internal/http/handlers"
+	"training-tracker/backend/internal/integrity"
 	"training-tracker/backend/internal/models"
 
 	"gorm.io/driver/postgres"
@@ -109,7 +113,7 @@ type ProgramSessionRequest struct {
 
 func main() {
 	dsn := config.GetEnv("POSTGRES_DSN", "host=localhost user=traininguser password=trainingpass dbname=trainingdb port=5432 sslmode=disable TimeZone=UTC")
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatalf("failed to connect database: %v", err)
 	}
@@ -120,11 +124,41 @@ func main() {
 	}
 
 	// Step 1.5: Migrate new tables
-	if err := db.AutoMigrate(&models.BodyWeight{}, &models.PersonalRecord{}, &models.Goal{}, &models.TrainingSession{}, &models.TrainingSessionExercise{}, &models.TrainingProgram{}, &models.ProgramExercise{}, &models.ProgramSession{}); err != nil {
+	if err := db.AutoMigrate(&models.BodyWeight{}, &models.PersonalRecord{}, &models.Goal{}, &models.TrainingSession{}, &models.TrainingSessionExercise{}, &models.CardioActivity{}, &models.SyncChange{}, &models.AuditLog{}, &models.TrainingProgram{}, &models.ProgramExercise{}, &models.ProgramSession{}, &models.Gym{}, &models.ReadinessCheckIn{}); err != nil {
 		// Do not crash if column already exists; log and continue
 		log.Printf("warn: AutoMigrate returned error (continuing): %v", err)
 	}
 
+	// Only one unfinished live workout per profile; sessions in the trash do not count
+	if err := db.Exec("DROP INDEX IF EXISTS idx_training_sessions_active_profile").Error; err != nil {
+		log.Printf("warn: failed to drop old active workout index: %v", err)
+	}
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_training_sessions_active_workout ON training_sessions(profile_id) WHERE status IN ('in_progress', 'paused') AND deleted_at IS NULL").Error; err != nil {
+		log.Printf("warn: failed to create active workout index: %v", err)
+	}
+	// changeId is unique per profile, not across all profiles
+	if err := db.Exec("DROP INDEX IF EXISTS idx_sync_changes_change_id").Error; err != nil {
+		log.Printf("warn: failed to drop old sync change index: %v", err)
+	}
+	// A profile imports each activity file once; older imports get their profile from the session
+	if err := db.Exec("UPDATE cardio_activities SET import_profile_id = s.profile_id FROM training_sessions s WHERE s.id = cardio_activities.training_session_id AND cardio_activities.source_hash <> '' AND cardio_activities.import_profile_id IS NULL").Error; err != nil {
+		log.Printf("warn: failed to backfill activity import profiles: %v", err)
+	}
+	// Imports in the trash do not block importing the same file again
+	if err := db.Exec("DROP INDEX IF EXISTS idx_cardio_activities_import").Error; err != nil {
+		log.Printf("warn: failed to drop old activity import index: %v", err)
+	}
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_cardio_activities_active_import ON cardio_activities(import_profile_id, source_hash) WHERE import_profile_id IS NOT NULL AND deleted_at IS NULL").Error; err != nil {
+		log.Printf("warn: failed to create activity import index: %v", err)
+	}
+	// One selected gym per profile; gyms in the trash do not count
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_gyms_profile_selected ON gyms(profile_id) WHERE is_selected AND deleted_at IS NULL").Error; err != nil {
+		log.Printf("warn: failed to create selected gym index: %v", err)
+	}
+	if err := db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_readiness_check_ins_profile_date ON readiness_check_ins(profile_id, date) WHERE deleted_at IS NULL").Error; err != nil {
+		log.Printf("warn: failed to create readiness check-in index: %v", err)
+	}
+
 	// Cleanup: drop obsolete column exercise_order if present
 	if db.Migrator().HasColumn(&models.ProgramExercise{}, "exercise_order") {
 		if err := db.Migrator().DropColumn(&models.ProgramExercise{}, "exercise_order"); err != nil {
@@ -135,6 +169,9 @@ func main() {
 	// Step 2: Seed default profile and exercises
 	seedProfiles(db)
 	seedExercises(db)
+	markBodyweightExercises(db)
+	markExerciseMetrics(db)
+	markExerciseEquipment(db)
 
 	var defaultProfile models.Profile
 	if err := db.First(&defaultProfile).Error; err != nil {
@@ -182,6 +219,33 @@ func main() {
 		log.Fatalf("failed to migrate Training: %v", err)
 	}
 
+	// Step 6: Record every later change in the audit log
+	if err := audit.Register(db); err != nil {
+		log.Fatalf("failed to register audit callbacks: %v", err)
+	}
+
+	// Step 7: Foreign keys with ON DELETE CASCADE and periodic orphan report
+	integrity.EnsureForeignKeys(db)
+	orphanCheckInterval, err := time.ParseDuration(config.GetEnv("ORPHAN_CHECK_INTERVAL", "24h"))
+	if err != nil {
+		log.Fatalf("invalid ORPHAN_CHECK_INTERVAL: %v", err)
+	}
+	if orphanCheckInterval <= 0 {
+		log.Fatalf("invalid ORPHAN_CHECK_INTERVAL: must be positive, got %s", orphanCheckInterval)
+	}
+	go integrity.RunOrphanCheck(db, orphanCheckInterval)
+
+	// Step 8: Purge trash older than TRASH_RETENTION
+	trashRetention, err := time.ParseDuration(config.GetEnv("TRASH_RETENTION", "720h"))
+	if err != nil {
+		log.Fatalf("invalid TRASH_RETENTION: %v", err)
+	}
+	if trashRetention <= 0 {
+		log.Fatalf("invalid TRASH_RETENTION: must be positive, got %s", trashRetention)
+	}
+	handlers.TrashRetention = trashRetention
+	go handlers.RunTrashPurge(db, time.Hour)
+
 	router := approuter.SetupRouter(db)
 
 	port := config.GetEnv("PORT", "8080")
@@ -191,10 +255,11 @@ func main() {
 }
 
 func seedExercises(db *gorm.DB) {
-	var count int64
-	db.Model(&models.Exercise{}).Count(&count)
-	if count > 0 {
-		return // Already seeded
+	var existing []string
+	db.Model(&models.Exercise{}).Pluck("name", &existing)
+	known := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		known[name] = true
 	}
 
 	exercises := []models.Exercise{
@@ -311,13 +376,151 @@ func seedExercises(db *gorm.DB) {
 		{Name: "Вакуум", Description: "Втяните живот на выдохе для поперечной мышцы.", Category: "Изолирующее", MuscleGroup: "Пресс", IsCustom: false},
 		{Name: "Складка", Description: "Одновременно поднимайте ноги и корпус.", Category: "Изолирующее", MuscleGroup: "Пресс", IsCustom: false},
 		{Name: "Планка с поднятием руки", Description: "Планка с попеременным подъемом рук.", Category: "Изолирующее", MuscleGroup: "Пресс", IsCustom: false},
+
+		// ========== КАРДИО И ФУНКЦИОНАЛ (Conditioning) ==========
+		{Name: "Прогулка фермера", Description: "Пройдите дистанцию с тяжелыми гантелями или гирями в руках.", Category: "Функциональное", MuscleGroup: "Кардио", IsCustom: false},
+		{Name: "Бег на дорожке", Description: "Бег в равномерном темпе или интервалами.", Category: "Кардио", MuscleGroup: "Кардио", IsCustom: false},
+		{Name: "Гребной тренажер", Description: "Гребля с акцентом на толчок ногами и тягу спиной.", Category: "Кардио", MuscleGroup: "Кардио", IsCustom: false},
+		{Name: "Велотренажер", Description: "Педалирование с заданным сопротивлением.", Category: "Кардио", MuscleGroup: "Кардио", IsCustom: false},
+		{Name: "Скакалка", Description: "Прыжки через скакалку в ровном ритме.", Category: "Кардио", MuscleGroup: "Кардио", IsCustom: false},
 	}
 
+	created := 0
 	for _, ex := range exercises {
+		if known[ex.Name] {
+			continue // Already seeded
+		}
+		if ratio, ok := bodyweightExercises[ex.Name]; ok {
+			ex.IsBodyweight = true
+			ex.BodyweightRatio = ratio
+		}
+		ex.Metrics = exerciseMetrics[ex.Name]
+		ex.Equipment = exerciseEquipment(ex.Name)
 		db.Create(&ex)
+		created++
+	}
+
+	if created > 0 {
+		log.Printf("Exercises seeded successfully: %d", created)
+	}
+}
+
+// exerciseMetrics - упражнения каталога, которые измеряются не весом и повторениями
+var exerciseMetrics = map[string][]string{
+	"Планка классическая":     {models.MetricDuration},
+	"Боковая планка":          {models.MetricDuration},
+	"Планка с поднятием руки": {models.MetricDuration},
+	"Вакуум":           {models.MetricDuration},
+	"Прогулка фермера": {models.MetricWeight, models.MetricDistance},
+	"Бег на дорожке":   {models.MetricDistance, models.MetricDuration},
+	"Гребной тренажер": {models.MetricDistance, models.MetricDuration},
+	"Велотренажер":     {models.MetricDistance, models.MetricDuration},
+	"Скакалка":         {models.MetricDuration},
+}
+
+// markExerciseMetrics проставляет метрики упражнениям, засеянным до появления колонки metrics
+func markExerciseMetrics(db *gorm.DB) {
+	for name, metrics := range exerciseMetrics {
+		var exercise models.Exercise
+		if err := db.Where("name = ? AND is_custom = ?", name, false).First(&exercise).Error; err != nil {
+			continue
+		}
+		if len(exercise.Metrics) > 0 {
+			continue
+		}
+		if err := db.Model(&exercise).Select("Metrics").Updates(models.Exercise{Metrics: metrics}).Error; err != nil {
+			log.Printf("warn: failed to set metrics for exercise %q: %v", name, err)
+		}
+	}
+}
+
+// bodyweightExercises - упражнения каталога с собственным весом и доля веса тела, которую они нагружают
+var bodyweightExercises = map[string]float64{
+	"Отжимания на брусьях":                       1,
+	"Отжимания на брусьях на трицепс":            1,
+	"Отжимания от пола":                          0.64,
+	"Отжимания узким хватом":                     0.64,
+	"Отжимания с упором ногами на возвышенность": 0.7,
+	"Подтягивания широким хватом":                1,
+	"Подтягивания узким хватом":                  1,
+	"Подтягивания обратным хватом":               1,
+	"Приседания на одной ноге":                   0.8,
+}
+
+// markBodyweightExercises помечает упражнения с собственным весом в уже засеянном каталоге
+func markBodyweightExercises(db *gorm.DB) {
+	for name, ratio := range bodyweightExercises {
+		if err := db.Model(&models.Exercise{}).
+			Where("name = ? AND is_custom = ? AND is_bodyweight = ?", name, false, false).
+			Updates(map[string]interface{}{"is_bodyweight": true, "bodyweight_ratio": ratio}).Error; err != nil {
+			log.Printf("warn: failed to mark bodyweight exercise %q: %v", name, err)
+		}
 	}
+}
 
-	log.Println("Exercises seeded successfully")
+// equipmentKeywords - оборудование упражнений каталога по словам в названии.
+// Проверяются по порядку, срабатывает первое совпадение.
+var equipmentKeywords = []struct {
+	keyword   string
+	equipment string
+}{
+	{"смита", models.EquipmentSmith},
+	{"т-грифа", models.EquipmentBarbell},
+	{"штанг", models.EquipmentBarbell},
+	{"становая", models.EquipmentBarbell},
+	{"румынская", models.EquipmentBarbell},
+	{"фронтальные", models.EquipmentBarbell},
+	{"жим лежа узким хватом", models.EquipmentBarbell},
+	{"французский жим", models.EquipmentBarbell},
+	{"скамье скотта", models.EquipmentBarbell},
+	{"гантел", models.EquipmentDumbbell},
+	{"гоблет", models.EquipmentDumbbell},
+	{"арнольда", models.EquipmentDumbbell},
+	{"молотковые", models.EquipmentDumbbell},
+	{"концентрированные", models.EquipmentDumbbell},
+	{"разводка в наклоне", models.EquipmentDumbbell},
+	{"прогулка фермера", models.EquipmentDumbbell},
+	{"кроссовер", models.EquipmentCable},
+	{"блок", models.EquipmentCable},
+	{"гребной тренажер", models.EquipmentCardio},
+	{"велотренажер", models.EquipmentCardio},
+	{"дорожке", models.EquipmentCardio},
+	{"тренажер", models.EquipmentMachine},
+	{"хаммер", models.EquipmentMachine},
+	{"жим ногами", models.EquipmentMachine},
+	{"сгибания ног", models.EquipmentMachine},
+	{"брусьях", models.EquipmentDipBars},
+	{"подтягивания", models.EquipmentPullupBar},
+	{"в висе", models.EquipmentPullupBar},
+}
+
+// exerciseEquipment - оборудование упражнения каталога, nil - ничего не нужно
+func exerciseEquipment(name string) []string {
+	lower := strings.ToLower(name)
+	for _, k := range equipmentKeywords {
+		if strings.Contains(lower, k.keyword) {
+			return []string{k.equipment}
+		}
+	}
+	return nil
+}
+
+// markExerciseEquipment проставляет оборудование упражнениям, засеянным до появления колонки equipment
+func markExerciseEquipment(db *gorm.DB) {
+	var exercises []models.Exercise
+	if err := db.Where("is_custom = ?", false).Find(&exercises).Error; err != nil {
+		log.Printf("warn: failed to load exercises for equipment: %v", err)
+		return
+	}
+	for _, exercise := range exercises {
+		equipment := exerciseEquipment(exercise.Name)
+		if len(exercise.Equipment) > 0 || len(equipment) == 0 {
+			continue
+		}
+		if err := db.Model(&exercise).Select("Equipment").Updates(models.Exercise{Equipment: equipment}).Error; err != nil {
+			log.Printf("warn: failed to set equipment for exercise %q: %v", exercise.Name, err)
+		}
+	}
 }
 
 func seedProfiles(db *gorm.DB) {
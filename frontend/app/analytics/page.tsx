@@ -41,11 +41,20 @@ type ExerciseStat = {
   progress: number
 }
 
+type Recommendation = {
+  id: string
+  severity: 'info' | 'warning' | 'critical'
+  category: string
+  exercise?: string
+  message: string
+  evidence: Record<string, unknown>
+}
+
 type Analytics = {
   profile: ProfileStats
   progress: ProgressStats
   muscleGroupBalance: MuscleGroupStat[]
-  recommendations: string[]
+  recommendations: Recommendation[]
   exerciseStats: ExerciseStat[]
 }
 
@@ -400,11 +409,11 @@ export default function AnalyticsPage() {
           </h3>
           <div className="space-y-3">
             {analytics.recommendations.map((rec, idx) => (
-              <div key={idx} className="flex items-start gap-3 p-4 rounded-xl bg-gradient-to-r from-amber-50 to-orange-50 dark:from-amber-950 dark:to-orange-950 border border-amber-200 dark:border-amber-800">
+              <div key={`${rec.id}-${idx}`} className="flex items-start gap-3 p-4 rounded-xl bg-gradient-to-r from-amber-50 to-orange-50 dark:from-amber-950 dark:to-orange-950 border border-amber-200 dark:border-amber-800">
                 <div className="flex-shrink-0 w-6 h-6 rounded-full bg-amber-500 text-white flex items-center justify-center text-xs font-bold">
                   {idx + 1}
                 </div>
-                <p className="text-sm font-medium text-slate-700 dark:text-slate-300">{rec}</p>
+                <p className="text-sm font-medium text-slate-700 dark:text-slate-300">{rec.message}</p>
               </div>
             ))}
           </div>
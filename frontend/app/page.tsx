@@ -79,6 +79,23 @@ export default function HomePage() {
     }
   }, [currentProfile])
 
+  // Сохранение существующей строки с версией в If-Match. Версия обновляется прямо
+  // в объекте строки, чтобы ее увидело и уже запланированное автосохранение.
+  // 412 значит, что строку изменили в другой вкладке: показываем актуальную версию.
+  async function putTraining(row: Training) {
+    const headers = row.version ? { 'If-Match': `"${row.version}"` } : undefined
+    try {
+      const res = await api.put<Training>(`/api/trainings/${row.id}`, { ...row, weeks: visibleWeeks }, { headers })
+      row.version = res.data.version
+    } catch (e: any) {
+      const current = e?.response?.status === 412 ? e.response.data?.current : null
+      if (!current) throw e
+      Object.assign(row, current)
+      setRows(prev => [...prev])
+      setError('Строка изменена в другой вкладке, загружена актуальная версия')
+    }
+  }
+
   // Автосохранение с debounce
   const autoSave = useCallback((row: Training) => {
     if (saveTimeoutRef.current) {
@@ -89,8 +106,7 @@ export default function HomePage() {
       if (row.id) {
         setIsSaving(true)
         try {
-          const payload = { ...row, weeks: visibleWeeks }
-          await api.put(`/api/trainings/${row.id}`, payload)
+          await putTraining(row)
         } catch (e: any) {
           console.error('Auto-save failed:', e)
         } finally {
@@ -121,11 +137,10 @@ export default function HomePage() {
 
   async function saveRow(index: number) {
     const row = rows[index]
-    const payload = { ...row, weeks: visibleWeeks }
     if (row.id) {
-      await api.put(`/api/trainings/${row.id}`, payload)
+      await putTraining(row)
     } else {
-      const res = await api.post('/api/trainings', payload)
+      const res = await api.post('/api/trainings', { ...row, weeks: visibleWeeks })
       const next = [...rows]
       next[index] = res.data
       setRows(next)